@@ -0,0 +1,59 @@
+// Package fixedpoint holds the fixed-point formats shared by the circuits in
+// lib and the off-chain helpers in utils. It imports nothing from either, so
+// both can depend on it and neither keeps its own copy.
+package fixedpoint
+
+import (
+	"math/big"
+	"strconv"
+)
+
+const (
+	// Precision is the number of fractional bits of W, B, X and z (Q32).
+	Precision = 32
+	// ScalingFactor is 2^Precision, the Q32 representation of 1.
+	ScalingFactor = 1 << Precision
+
+	// InputPrecision is the fractional bits of the sigmoid LUT index (Q10).
+	InputPrecision = 10
+	// OutputPrecision is the fractional bits of the sigmoid LUT values (Q16).
+	OutputPrecision = 16
+	// MaxInput is the largest |z| the LUT covers; beyond it z saturates.
+	MaxInput = 8
+	// MaxMarks bounds the marks the accuracy circuits accept: every X is
+	// in [0, MaxMarks].
+	MaxMarks = 100
+)
+
+// Rounding is how a product of two Precision-bit values is rescaled back to
+// Precision bits, both in the circuits' FixedArith and off-chain.
+type Rounding int
+
+const (
+	// Truncate rounds toward negative infinity (floor division by 2^Precision).
+	Truncate Rounding = iota
+	// RoundNearest rounds to nearest, ties toward positive infinity.
+	RoundNearest
+)
+
+func (r Rounding) String() string {
+	switch r {
+	case Truncate:
+		return "truncate"
+	case RoundNearest:
+		return "nearest"
+	}
+	return "Rounding(" + strconv.Itoa(int(r)) + ")"
+}
+
+// RescaleInt returns v, a fixed-point value with fromPrec fractional bits,
+// with toPrec fractional bits instead, e.g. a Q32 z as a Q10 LUT index or a
+// Q64 product back in Q32. Raising the precision is exact; lowering it
+// rounds toward negative infinity, for negative v too, as the circuits'
+// floor division does. v is not modified.
+func RescaleInt(v *big.Int, fromPrec, toPrec uint) *big.Int {
+	if toPrec >= fromPrec {
+		return new(big.Int).Lsh(v, toPrec-fromPrec)
+	}
+	return new(big.Int).Rsh(v, fromPrec-toPrec) // arithmetic shift == floor
+}
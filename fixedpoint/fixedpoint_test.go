@@ -0,0 +1,64 @@
+package fixedpoint
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRescaleInt(t *testing.T) {
+	tests := []struct {
+		name             string
+		v                int64
+		fromPrec, toPrec uint
+		want             int64
+	}{
+		{"Q10 to Q32", 3 << 10, InputPrecision, Precision, 3 << 32},
+		{"negative Q10 to Q32", -5, InputPrecision, Precision, -5 << 22},
+		{"Q16 to Q16", -12345, OutputPrecision, OutputPrecision, -12345},
+		{"Q32 to Q10, exact", 7 << 22, Precision, InputPrecision, 7},
+		{"Q32 to Q10, floored", 7<<22 + 1<<21, Precision, InputPrecision, 7},
+		{"negative Q32 to Q10, exact", -7 << 22, Precision, InputPrecision, -7},
+		// lowering the precision floors, so a negative value rounds away
+		// from zero where integer division would truncate toward it
+		{"negative Q32 to Q10, floored", -7<<22 - 1, Precision, InputPrecision, -8},
+		{"-1 LSB to Q10", -1, Precision, InputPrecision, -1},
+		{"+1 LSB to Q10", 1, Precision, InputPrecision, 0},
+		{"Q64 to Q32", -3 << 40, 2 * Precision, Precision, -3 << 8},
+	}
+	for _, tt := range tests {
+		v := big.NewInt(tt.v)
+		got := RescaleInt(v, tt.fromPrec, tt.toPrec)
+		if got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("%s: RescaleInt(%d, %d, %d) = %s, want %d", tt.name, tt.v, tt.fromPrec, tt.toPrec, got, tt.want)
+		}
+		if v.Int64() != tt.v {
+			t.Errorf("%s: RescaleInt modified its argument to %s", tt.name, v)
+		}
+	}
+}
+
+func TestRescaleIntRoundTrip(t *testing.T) {
+	// raising the precision and lowering it back returns the same value
+	for _, v := range []int64{0, 1, -1, 1 << 40, -(1 << 40) - 3} {
+		up := RescaleInt(big.NewInt(v), InputPrecision, Precision)
+		if back := RescaleInt(up, Precision, InputPrecision); back.Int64() != v {
+			t.Errorf("%d: round trip through Q32 gave %s", v, back)
+		}
+	}
+}
+
+func TestRoundingString(t *testing.T) {
+	tests := []struct {
+		r    Rounding
+		want string
+	}{
+		{Truncate, "truncate"},
+		{RoundNearest, "nearest"},
+		{Rounding(7), "Rounding(7)"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.String(); got != tt.want {
+			t.Errorf("Rounding(%d).String() = %q, want %q", int(tt.r), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// Backend selects the proof system circuits are compiled and proved with.
+// The circuit definitions are the same for both.
+type Backend int
+
+const (
+	// BackendPlonk compiles to a SparseR1CS and proves with PLONK over a
+	// universal KZG SRS.
+	BackendPlonk Backend = iota
+	// BackendGroth16 compiles to a standard R1CS and proves with Groth16,
+	// whose proofs are smaller and cheaper to verify on-chain but need a
+	// setup per circuit.
+	BackendGroth16
+)
+
+func (b Backend) String() string {
+	switch b {
+	case BackendPlonk:
+		return "plonk"
+	case BackendGroth16:
+		return "groth16"
+	}
+	return fmt.Sprintf("Backend(%d)", int(b))
+}
+
+// ParseBackend parses "plonk" or "groth16".
+func ParseBackend(name string) (Backend, error) {
+	for _, b := range []Backend{BackendPlonk, BackendGroth16} {
+		if strings.EqualFold(name, b.String()) {
+			return b, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported backend %q (want plonk or groth16)", name)
+}
+
+// Proof is a proof from either backend. It has the same method set as
+// plonk.Proof, and groth16.Proof satisfies it too.
+type Proof interface {
+	io.WriterTo
+	io.ReaderFrom
+	gnarkio.WriterRawTo
+}
+
+// newProof returns an empty proof for backend on curve, to read into.
+func newProof(backend Backend, curve ecc.ID) Proof {
+	if backend == BackendGroth16 {
+		return groth16.NewProof(curve)
+	}
+	return plonk.NewProof(curve)
+}
+
+// ReadProof decodes a proof for backend on curve from data, as written by
+// the proof's WriteTo.
+func ReadProof(backend Backend, curve ecc.ID, data []byte) (Proof, error) {
+	proof := newProof(backend, curve)
+	if _, err := proof.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Compile compiles circuit over curve's scalar field into the constraint
+// system backend proves: a SparseR1CS for PLONK, an R1CS for Groth16.
+func Compile(backend Backend, curve ecc.ID, circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	builder := scs.NewBuilder
+	if backend == BackendGroth16 {
+		builder = r1cs.NewBuilder
+	}
+	ccs, err := frontend.Compile(curve.ScalarField(), builder, circuit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCircuitCompile, err)
+	}
+	return ccs, nil
+}
+
+// CompileR1CS compiles circuit to a standard R1CS on DefaultCurve, e.g. for
+// tooling that consumes R1CS; its WriteTo exports gnark's binary encoding.
+func CompileR1CS(circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	return Compile(BackendGroth16, DefaultCurve, circuit)
+}
+
+// CircuitKeys is a compiled circuit with its proving and verifying keys
+// under one backend.
+type CircuitKeys struct {
+	Backend Backend
+	CCS     constraint.ConstraintSystem
+
+	// ProveBudget, if positive, pads every Prove to take at least that long
+	// whether it succeeds or fails. Proving time depends on the witness (an
+	// unsatisfied one fails fast, for a start), so without padding an
+	// observer timing proofs learns something about the private inputs. A
+	// proof slower than the budget still leaks its overrun, so the budget
+	// should exceed the slowest proof expected.
+	ProveBudget time.Duration
+
+	// proofSeed, if non-nil, makes Prove deterministic. Only
+	// SetInsecureProofSeed sets it, in zklr_insecure_seed builds.
+	proofSeed []byte
+
+	plonkPK   plonk.ProvingKey
+	plonkVK   plonk.VerifyingKey
+	groth16PK groth16.ProvingKey
+	groth16VK groth16.VerifyingKey
+}
+
+// plonkKeys wraps PLONK keys; either may be nil if only proving or only
+// verifying.
+func plonkKeys(ccs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey) *CircuitKeys {
+	return &CircuitKeys{Backend: BackendPlonk, CCS: ccs, plonkPK: pk, plonkVK: vk}
+}
+
+// VKFingerprint is VKFingerprint of the keys' verifying key.
+func (k *CircuitKeys) VKFingerprint() string {
+	if k.Backend == BackendGroth16 {
+		return VKFingerprint(k.groth16VK)
+	}
+	return VKFingerprint(k.plonkVK)
+}
+
+// SetupBackend compiles circuit and runs backend's setup on curve. Like
+// Setup, this is a development setup: the PLONK SRS is unsafekzg's and the
+// Groth16 toxic waste is sampled locally.
+func SetupBackend(backend Backend, curve ecc.ID, circuit frontend.Circuit) (*CircuitKeys, error) {
+	return setupBackend(backend, curve, circuit, nil)
+}
+
+// setupBackend is SetupBackend with a PLONK setup against srs (see
+// SetupWithSRS) unless it is nil.
+func setupBackend(backend Backend, curve ecc.ID, circuit frontend.Circuit, srs kzg.SRS) (*CircuitKeys, error) {
+	if backend == BackendPlonk && srs != nil {
+		ccs, err := Compile(backend, curve, circuit)
+		if err != nil {
+			return nil, err
+		}
+		pk, vk, err := SetupWithSRS(ccs, srs)
+		if err != nil {
+			return nil, err
+		}
+		return plonkKeys(ccs, pk, vk), nil
+	}
+	if backend == BackendPlonk {
+		ccs, pk, vk, err := SetupCurve(curve, circuit)
+		if err != nil {
+			return nil, err
+		}
+		return plonkKeys(ccs, pk, vk), nil
+	}
+
+	ccs, err := Compile(backend, curve, circuit)
+	if err != nil {
+		return nil, err
+	}
+	done := drawingRandomness()
+	pk, vk, err := groth16.Setup(ccs)
+	done()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSetup, err)
+	}
+	return &CircuitKeys{Backend: backend, CCS: ccs, groth16PK: pk, groth16VK: vk}, nil
+}
+
+// Prove proves the full witness under the keys' backend, padded to
+// ProveBudget.
+func (k *CircuitKeys) Prove(full witness.Witness) (Proof, error) {
+	if k.ProveBudget > 0 {
+		deadline := time.Now().Add(k.ProveBudget)
+		defer func() { time.Sleep(time.Until(deadline)) }()
+	}
+	if k.proofSeed != nil {
+		restore, err := deterministicProve(k.proofSeed)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+	} else {
+		defer drawingRandomness()()
+	}
+	var proof Proof
+	var err error
+	if k.Backend == BackendGroth16 {
+		proof, err = groth16.Prove(k.CCS, k.groth16PK, full)
+	} else {
+		proof, err = plonk.Prove(k.CCS, k.plonkPK, full)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProve, err)
+	}
+	return proof, nil
+}
+
+// Verify checks a proof made by Prove against its public witness.
+func (k *CircuitKeys) Verify(proof Proof, public witness.Witness) error {
+	var err error
+	if k.Backend == BackendGroth16 {
+		p, ok := proof.(groth16.Proof)
+		if !ok {
+			return fmt.Errorf("%w: %T is not a groth16 proof", ErrVerify, proof)
+		}
+		err = groth16.Verify(p, k.groth16VK, public)
+	} else {
+		// a groth16.Proof has plonk.Proof's method set too, and plonk.Verify
+		// panics on it
+		p, ok := proof.(plonk.Proof)
+		if _, isGroth16 := proof.(groth16.Proof); !ok || isGroth16 {
+			return fmt.Errorf("%w: %T is not a plonk proof", ErrVerify, proof)
+		}
+		err = plonk.Verify(p, k.plonkVK, public)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVerify, err)
+	}
+	return nil
+}
+
+// writeTo writes the keys in the cache format: the constraint system, the
+// proving key and the verifying key, each in gnark's binary encoding.
+func (k *CircuitKeys) writeTo(w io.Writer) error {
+	if k.Backend == BackendGroth16 {
+		return writeCircuitData(w, k.CCS, k.groth16PK, k.groth16VK)
+	}
+	return writeCircuitData(w, k.CCS, k.plonkPK, k.plonkVK)
+}
+
+// readCircuitKeys reads keys for backend on curve written by writeTo.
+func readCircuitKeys(backend Backend, curve ecc.ID, r io.Reader) (*CircuitKeys, error) {
+	if backend == BackendPlonk {
+		ccs, pk, vk, err := readCircuitData(curve, r)
+		if err != nil {
+			return nil, err
+		}
+		return plonkKeys(ccs, pk, vk), nil
+	}
+
+	ccs := groth16.NewCS(curve)
+	if _, err := ccs.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("%w: ccs: %w", ErrCacheCorrupt, err)
+	}
+	pk := groth16.NewProvingKey(curve)
+	if _, err := pk.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("%w: pk: %w", ErrCacheCorrupt, err)
+	}
+	vk := groth16.NewVerifyingKey(curve)
+	if _, err := vk.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("%w: vk: %w", ErrCacheCorrupt, err)
+	}
+	return &CircuitKeys{Backend: backend, CCS: ccs, groth16PK: pk, groth16VK: vk}, nil
+}
@@ -0,0 +1,259 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestParseBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Backend
+		wantErr bool
+	}{
+		{"plonk", BackendPlonk, false},
+		{"groth16", BackendGroth16, false},
+		{"Groth16", BackendGroth16, false},
+		{"PLONK", BackendPlonk, false},
+		{"r1cs", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBackend(tt.name)
+		if tt.wantErr != (err != nil) || got != tt.want {
+			t.Errorf("ParseBackend(%q) = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+		if err == nil && got.String() != tt.want.String() {
+			t.Errorf("%v.String() = %q", got, got.String())
+		}
+	}
+}
+
+func TestCompileR1CS(t *testing.T) {
+	r1cs, err := CompileR1CS(&LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r1cs.(constraint.R1CS); !ok {
+		t.Fatalf("CompileR1CS gave a %T, want an R1CS", r1cs)
+	}
+	if _, ok := compiled(t, &LinearCircuit{}).(constraint.SparseR1CS); !ok {
+		t.Fatal("the PLONK compile is not a SparseR1CS")
+	}
+
+	// the exported encoding reads back as the same R1CS
+	var buf bytes.Buffer
+	if _, err := r1cs.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read := groth16.NewCS(DefaultCurve)
+	if _, err := read.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if read.GetNbConstraints() != r1cs.GetNbConstraints() || read.GetNbPublicVariables() != r1cs.GetNbPublicVariables() {
+		t.Errorf("read back %d constraints and %d public variables, want %d and %d",
+			read.GetNbConstraints(), read.GetNbPublicVariables(), r1cs.GetNbConstraints(), r1cs.GetNbPublicVariables())
+	}
+
+	// the same circuit definition accepts the same witnesses under either
+	// system
+	wScaled, bScaled, x := NewScaled(testModel.w), NewScaled(testModel.b), NewScaled(30)
+	z := linearZScaled(wScaled, bScaled, x)
+	tests := []struct {
+		name string
+		z    *big.Int
+		ok   bool
+	}{
+		{"z = W*X + B", z, true},
+		{"z off by one", new(big.Int).Add(z, big.NewInt(1)), false},
+	}
+	for _, tt := range tests {
+		for _, ccs := range []constraint.ConstraintSystem{r1cs, read, compiled(t, &LinearCircuit{})} {
+			err := solved(t, ccs, &LinearCircuit{W: wScaled, B: bScaled, X: x, Z: tt.z, ModelCommitment: testCommitment()})
+			if tt.ok != (err == nil) {
+				t.Errorf("%s on a %T: %v", tt.name, ccs, err)
+			}
+		}
+	}
+}
+
+func TestProofOfOtherBackendRejected(t *testing.T) {
+	full, err := LinearWitness(DefaultCurve.ScalarField(), testModel.w, testModel.b, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := full.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[Backend]*CircuitKeys{}
+	proofs := map[Backend]Proof{}
+	for _, backend := range []Backend{BackendPlonk, BackendGroth16} {
+		if keys[backend], err = SetupBackend(backend, DefaultCurve, &LinearCircuit{}); err != nil {
+			t.Fatal(err)
+		}
+		if proofs[backend], err = keys[backend].Prove(full); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for verifier, k := range keys {
+		for prover, proof := range proofs {
+			err := k.Verify(proof, public)
+			if prover == verifier && err != nil {
+				t.Errorf("%v proof: %v", prover, err)
+			}
+			if prover != verifier && !errors.Is(err, ErrVerify) {
+				t.Errorf("%v proof under %v keys: %v, want ErrVerify", prover, verifier, err)
+			}
+		}
+	}
+}
+
+func TestGroth16Circuits(t *testing.T) {
+	field := DefaultCurve.ScalarField()
+	linear := func(x float64) witness.Witness {
+		full, err := LinearWitness(field, testModel.w, testModel.b, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return full
+	}
+	aggregator := func(counts ...int) witness.Witness {
+		full, err := frontend.NewWitness(&AggregatorCircuit{Count1: counts[0], Count2: counts[1], Count3: counts[2], Count4: counts[3]}, field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return full
+	}
+	tests := []struct {
+		name    string
+		circuit func() frontend.Circuit
+		full    witness.Witness
+		other   witness.Witness // another statement, whose public witness the proof must not verify
+		invalid witness.Witness // fails the circuit, so cannot be proved
+	}{
+		{"linear", func() frontend.Circuit { return &LinearCircuit{} }, linear(30), linear(31), nil},
+		{"aggregator", func() frontend.Circuit { return &AggregatorCircuit{} }, aggregator(25, 24, 25, 23), aggregator(25, 25, 25, 22), aggregator(25, 24, 24, 23)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log strings.Builder
+			logf := func(format string, args ...any) { fmt.Fprintf(&log, format, args...) }
+			cacheFile := filepath.Join(t.TempDir(), "groth16.cache")
+			keys, err := loadOrSetupKeys(BackendGroth16, DefaultCurve, cacheFile, tt.circuit(), nil, true, logf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := keys.CCS.(constraint.R1CS); !ok || keys.Backend != BackendGroth16 {
+				t.Fatalf("%v keys over a %T, want Groth16 over an R1CS", keys.Backend, keys.CCS)
+			}
+			cached, err := loadOrSetupKeys(BackendGroth16, DefaultCurve, cacheFile, tt.circuit(), nil, true, logf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(log.String(), "Loaded") || cached.VKFingerprint() != keys.VKFingerprint() {
+				t.Fatalf("the Groth16 cache was not reloaded:\n%s", log.String())
+			}
+			// a Groth16 cache is not a PLONK one
+			log.Reset()
+			if _, err := loadOrSetupKeys(BackendPlonk, DefaultCurve, cacheFile, tt.circuit(), nil, false, logf); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(log.String(), "recompiling") {
+				t.Errorf("loaded a Groth16 cache for PLONK:\n%s", log.String())
+			}
+
+			proof, err := keys.Prove(tt.full)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tc := range []struct {
+				name  string
+				full  witness.Witness
+				valid bool
+			}{
+				{"own public witness", tt.full, true},
+				{"other public witness", tt.other, false},
+			} {
+				public, err := tc.full.Public()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := cached.Verify(proof, public); tc.valid != (err == nil) {
+					t.Errorf("%s: %v, want valid = %v", tc.name, err, tc.valid)
+				}
+			}
+			if tt.invalid != nil {
+				if _, err := keys.Prove(tt.invalid); !errors.Is(err, ErrProve) {
+					t.Errorf("proving an unsatisfied witness: %v, want ErrProve", err)
+				}
+			}
+		})
+	}
+}
+
+func TestProveBudget(t *testing.T) {
+	keys, err := SetupBackend(BackendGroth16, DefaultCurve, &LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	field := DefaultCurve.ScalarField()
+	near, err := LinearWitness(field, testModel.w, testModel.b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	far, err := LinearWitness(field, 123.456, -98.7, MaxMarks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, b, x := NewScaled(testModel.w), NewScaled(testModel.b), NewScaled(30)
+	// an unsatisfied witness fails before any of the proving work
+	wrongZ, err := linearWitness(field, w, b, x, new(big.Int).Add(linearZScaled(w, b, x), big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const budget = 300 * time.Millisecond
+	keys.ProveBudget = budget
+	tests := []struct {
+		name  string
+		full  witness.Witness
+		valid bool
+	}{
+		{"small inputs", near, true},
+		{"large inputs", far, true},
+		{"unsatisfied", wrongZ, false},
+	}
+	var fastest, slowest time.Duration
+	for _, tt := range tests {
+		start := time.Now()
+		_, err := keys.Prove(tt.full)
+		elapsed := time.Since(start)
+		if (err == nil) != tt.valid || (err != nil && !errors.Is(err, ErrProve)) {
+			t.Errorf("%s: err = %v, want valid = %v", tt.name, err, tt.valid)
+		}
+		if elapsed < budget {
+			t.Errorf("%s: took %v, under the %v budget", tt.name, elapsed, budget)
+		}
+		if fastest == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+		slowest = max(slowest, elapsed)
+	}
+	// proving the linear circuit takes milliseconds, so padded times agree
+	// to well within the budget
+	if slowest-fastest > budget/3 {
+		t.Errorf("padded proofs took %v to %v, want about the same", fastest, slowest)
+	}
+}
@@ -0,0 +1,253 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ============================================================================
+// CIRCUIT 3E: Balanced Accuracy Chunk Circuit
+// Counts correct predictions per class over 25 samples, so an aggregator can
+// prove balanced accuracy, the mean of the recall of both classes, at
+// a threshold. Overall accuracy rewards always predicting the majority
+// class of an imbalanced dataset; balanced accuracy does not.
+// ============================================================================
+
+type BalancedChunkCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     [ChunkSize]frontend.Variable `gnark:",public"`
+	Label [ChunkSize]frontend.Variable `gnark:",public"`
+
+	// TruePositives counts the samples labelled and predicted 1, out of
+	// Positives labelled 1; TrueNegatives and Negatives likewise for 0.
+	// Every sample counts: a margin check would leave the recalls without
+	// a matching denominator.
+	TruePositives frontend.Variable `gnark:",public"`
+	Positives     frontend.Variable `gnark:",public"`
+	TrueNegatives frontend.Variable `gnark:",public"`
+	Negatives     frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B); see LinearCircuit.
+	ModelCommitment frontend.Variable `gnark:",public"`
+}
+
+// revision 2 asserts each prediction is boolean.
+func (c *BalancedChunkCircuit) revision() int { return 2 }
+
+func (c *BalancedChunkCircuit) Define(api frontend.API) error {
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+	w := New(api, c.W)
+	b := New(api, c.B)
+
+	truePositives, positives, trueNegatives := frontend.Variable(0), frontend.Variable(0), frontend.Variable(0)
+	for i := range c.X {
+		// Positives sums the labels, so each must be 0 or 1
+		api.AssertIsBoolean(c.Label[i])
+		z := w.Mul(New(api, c.X[i])).Add(b)
+		prediction := api.Sub(1, isNegative(api, z.Val))
+		api.AssertIsBoolean(prediction) // defensive, as in SigmoidCircuit
+		correct := api.IsZero(api.Sub(prediction, c.Label[i]))
+		correctPositive := api.Mul(correct, c.Label[i])
+
+		positives = api.Add(positives, c.Label[i])
+		truePositives = api.Add(truePositives, correctPositive)
+		trueNegatives = api.Add(trueNegatives, api.Sub(correct, correctPositive))
+	}
+	api.AssertIsEqual(truePositives, c.TruePositives)
+	api.AssertIsEqual(positives, c.Positives)
+	api.AssertIsEqual(trueNegatives, c.TrueNegatives)
+	api.AssertIsEqual(api.Sub(ChunkSize, positives), c.Negatives)
+	return nil
+}
+
+// ============================================================================
+// CIRCUIT 3F: Balanced Aggregator Circuit
+// Takes the class counts of 4 balanced chunks and asserts
+// (TP/P + TN/N) / 2 >= MinPercent/100 by cross-multiplication:
+// 100 * (TP*N + TN*P) >= 2 * MinPercent * P * N
+// ============================================================================
+
+type BalancedAggregatorCircuit struct {
+	// Chunk i's counts, as its BalancedChunkCircuit proved them. Chunks
+	// not given are all zero.
+	TruePositives [numChunks]frontend.Variable `gnark:",public"`
+	Positives     [numChunks]frontend.Variable `gnark:",public"`
+	TrueNegatives [numChunks]frontend.Variable `gnark:",public"`
+	Negatives     [numChunks]frontend.Variable `gnark:",public"`
+
+	// MinPercent is the compiled-in minimum balanced accuracy, in percent;
+	// each value yields its own verifying key. Zero means DefaultMinCorrect,
+	// i.e. the same 97% as the accuracy policy.
+	MinPercent int `gnark:"-"`
+}
+
+// NewBalancedAggregatorCircuit returns a BalancedAggregatorCircuit asserting
+// a balanced accuracy of at least minPercent percent.
+func NewBalancedAggregatorCircuit(minPercent int) *BalancedAggregatorCircuit {
+	return &BalancedAggregatorCircuit{MinPercent: minPercent}
+}
+
+func (c *BalancedAggregatorCircuit) Define(api frontend.API) error {
+	// Each count is in [0, ChunkSize], as in AggregatorCircuit, so the
+	// totals stay below numChunks*ChunkSize and the products below cannot
+	// wrap around the field.
+	countBits := bits.Len(ChunkSize)
+	totals := make([]frontend.Variable, 4)
+	for j, counts := range [][numChunks]frontend.Variable{c.TruePositives, c.Positives, c.TrueNegatives, c.Negatives} {
+		totals[j] = frontend.Variable(0)
+		for _, count := range counts {
+			api.ToBinary(count, countBits)
+			api.AssertIsEqual(isLessBounded(api, count, big.NewInt(ChunkSize+1), countBits), 1)
+			totals[j] = api.Add(totals[j], count)
+		}
+	}
+	truePositives, positives, trueNegatives, negatives := totals[0], totals[1], totals[2], totals[3]
+
+	// a recall is undefined for a class with no samples
+	api.AssertIsDifferent(positives, 0)
+	api.AssertIsDifferent(negatives, 0)
+
+	// Both sides are below 2^productBits, so their difference is
+	// non-negative exactly when it fits in productBits bits; see
+	// MeanBoundCircuit.
+	minPercent := minCorrectOrDefault(c.MinPercent)
+	lhs := api.Mul(100, api.Add(api.Mul(truePositives, negatives), api.Mul(trueNegatives, positives)))
+	rhs := api.Mul(2*minPercent, api.Mul(positives, negatives))
+	productBits := 2*bits.Len(numChunks*ChunkSize) + bits.Len(200)
+	api.ToBinary(api.Sub(lhs, rhs), productBits)
+	return nil
+}
+
+// ClassCounts are the per-class prediction counts a BalancedChunkCircuit
+// proves, or their totals over several chunks.
+type ClassCounts struct {
+	TruePositives int
+	Positives     int
+	TrueNegatives int
+	Negatives     int
+}
+
+// BalancedAccuracy returns the mean of the recall of both classes, or 0 if
+// either class has no samples.
+func (c ClassCounts) BalancedAccuracy() float64 {
+	if c.Positives == 0 || c.Negatives == 0 {
+		return 0
+	}
+	return (float64(c.TruePositives)/float64(c.Positives) + float64(c.TrueNegatives)/float64(c.Negatives)) / 2
+}
+
+// Accuracy is the overall accuracy of the same predictions, for comparison.
+func (c ClassCounts) Accuracy() float64 {
+	n := c.Positives + c.Negatives
+	if n == 0 {
+		return 0
+	}
+	return float64(c.TruePositives+c.TrueNegatives) / float64(n)
+}
+
+func (c ClassCounts) add(o ClassCounts) ClassCounts {
+	return ClassCounts{c.TruePositives + o.TruePositives, c.Positives + o.Positives, c.TrueNegatives + o.TrueNegatives, c.Negatives + o.Negatives}
+}
+
+// BuildBalancedChunkWitness returns the full BalancedChunkCircuit witness for
+// a chunk of exactly ChunkSize samples under model (w, b), on field, with
+// the class counts it proves. Chunks are not padded: a padding sample would
+// count against its class's recall.
+func BuildBalancedChunkWitness(field *big.Int, w, b float64, samples []utils.Sample) (witness.Witness, ClassCounts, error) {
+	if len(samples) != ChunkSize {
+		return nil, ClassCounts{}, fmt.Errorf("%w: balanced chunk needs %d samples, got %d", ErrWitness, ChunkSize, len(samples))
+	}
+	var assignment BalancedChunkCircuit
+	var counts ClassCounts
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	assignment.W = wScaled
+	assignment.B = bScaled
+	for i, s := range samples {
+		if s.Label != 0 && s.Label != 1 {
+			return nil, ClassCounts{}, fmt.Errorf("%w: sample %d: label %d is not 0 or 1", ErrWitness, i+1, s.Label)
+		}
+		x := NewScaled(s.Marks)
+		assignment.X[i] = x
+		assignment.Label[i] = big.NewInt(int64(s.Label))
+
+		pred := 0
+		if linearZScaled(wScaled, bScaled, x).Sign() >= 0 {
+			pred = 1
+		}
+		if s.Label == 1 {
+			counts.Positives++
+			if pred == 1 {
+				counts.TruePositives++
+			}
+		} else {
+			counts.Negatives++
+			if pred == 0 {
+				counts.TrueNegatives++
+			}
+		}
+	}
+	assignment.TruePositives = counts.TruePositives
+	assignment.Positives = counts.Positives
+	assignment.TrueNegatives = counts.TrueNegatives
+	assignment.Negatives = counts.Negatives
+	assignment.ModelCommitment = modelCommitment(field, wScaled, bScaled)
+
+	full, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, ClassCounts{}, fmt.Errorf("%w: balanced chunk: %w", ErrWitness, err)
+	}
+	return full, counts, nil
+}
+
+// PublicClassCounts extracts the class counts from a BalancedChunkCircuit
+// public witness, laid out as [X..., Label..., TruePositives, Positives,
+// TrueNegatives, Negatives, ModelCommitment].
+func PublicClassCounts(public witness.Witness) (ClassCounts, error) {
+	var v [4]int
+	for j := range v {
+		e, err := publicElement(public, 2*ChunkSize+j)
+		if err != nil {
+			return ClassCounts{}, fmt.Errorf("%w: class count: %w", ErrWitness, err)
+		}
+		if !e.IsUint64() || e.Uint64() > ChunkSize {
+			return ClassCounts{}, fmt.Errorf("%w: class count %s out of range", ErrWitness, e.String())
+		}
+		v[j] = int(e.Uint64())
+	}
+	return ClassCounts{v[0], v[1], v[2], v[3]}, nil
+}
+
+// BuildBalancedAggregatorWitness assembles the BalancedAggregatorCircuit
+// assignment from the public witnesses of 1 to numChunks balanced chunk
+// proofs, in order, and returns the totals it proves over.
+func BuildBalancedAggregatorWitness(chunkPublics []witness.Witness) (BalancedAggregatorCircuit, ClassCounts, error) {
+	var assignment BalancedAggregatorCircuit
+	var total ClassCounts
+	if len(chunkPublics) < 1 || len(chunkPublics) > numChunks {
+		return assignment, total, fmt.Errorf("%w: balanced aggregator needs 1 to %d chunks, got %d", ErrWitness, numChunks, len(chunkPublics))
+	}
+	for i := 0; i < numChunks; i++ {
+		var counts ClassCounts
+		if i < len(chunkPublics) {
+			var err error
+			if counts, err = PublicClassCounts(chunkPublics[i]); err != nil {
+				return assignment, total, fmt.Errorf("chunk %d: %w", i+1, err)
+			}
+		}
+		assignment.TruePositives[i] = counts.TruePositives
+		assignment.Positives[i] = counts.Positives
+		assignment.TrueNegatives[i] = counts.TrueNegatives
+		assignment.Negatives[i] = counts.Negatives
+		total = total.add(counts)
+	}
+	return assignment, total, nil
+}
@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// imbalancedDataset returns 4 chunks of 25 samples with one positive (1,
+// Fail) each. testModel predicts 1 only at marks <= 20, so it gets every
+// negative and the first positive right: 97% overall accuracy, but a
+// positive recall of 1/4.
+func imbalancedDataset() [][]utils.Sample {
+	chunks := make([][]utils.Sample, numChunks)
+	for i := range chunks {
+		for j := 0; j < ChunkSize-1; j++ {
+			chunks[i] = append(chunks[i], utils.Sample{Marks: float64(30 + j), Label: 0})
+		}
+		positive := utils.Sample{Marks: 50, Label: 1}
+		if i == 0 {
+			positive.Marks = 10
+		}
+		chunks[i] = append(chunks[i], positive)
+	}
+	return chunks
+}
+
+func TestBalancedAccuracy(t *testing.T) {
+	chunkCCS := compiled(t, &BalancedChunkCircuit{})
+	var publics []witness.Witness
+	var total ClassCounts
+	for i, chunk := range imbalancedDataset() {
+		full, counts, err := BuildBalancedChunkWitness(chunkCCS.Field(), testModel.w, testModel.b, chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := chunkCCS.IsSolved(full); err != nil {
+			t.Fatalf("chunk %d: %v", i+1, err)
+		}
+		public, err := full.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := PublicClassCounts(public); err != nil || got != counts {
+			t.Fatalf("chunk %d: PublicClassCounts = %+v, %v, want %+v", i+1, got, err, counts)
+		}
+		publics = append(publics, public)
+		total = total.add(counts)
+	}
+	if want := (ClassCounts{TruePositives: 1, Positives: 4, TrueNegatives: 96, Negatives: 96}); total != want {
+		t.Fatalf("counts %+v, want %+v", total, want)
+	}
+	if total.Accuracy() != 0.97 || total.BalancedAccuracy() != 0.625 {
+		t.Fatalf("accuracy %v, balanced %v, want 0.97 and 0.625", total.Accuracy(), total.BalancedAccuracy())
+	}
+
+	balanced, aggregated, err := BuildBalancedAggregatorWitness(publics)
+	if err != nil || aggregated != total {
+		t.Fatalf("BuildBalancedAggregatorWitness: %+v, %v, want %+v", aggregated, err, total)
+	}
+	overall := AggregatorCircuit{Count1: 25, Count2: 24, Count3: 24, Count4: 24}
+
+	// overall accuracy meets the 97% policy while balanced accuracy
+	// fails it, and passes only a threshold at or below 62.5%
+	accuracyTests := []struct {
+		name       string
+		minPercent int
+		wantErr    bool
+	}{
+		{"overall at 97%", -1, false},
+		{"balanced at 97%", 97, true},
+		{"balanced at 63%", 63, true},
+		{"balanced at 62%", 62, false},
+	}
+	for _, tt := range accuracyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.minPercent < 0 {
+				err = solved(t, compiled(t, &AggregatorCircuit{}), &overall)
+			} else {
+				assignment := balanced
+				assignment.MinPercent = tt.minPercent
+				err = solved(t, compiled(t, NewBalancedAggregatorCircuit(tt.minPercent)), &assignment)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error %v, want error = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBalancedWitnessErrors(t *testing.T) {
+	chunk := imbalancedDataset()[0]
+	badLabel := append([]utils.Sample(nil), chunk...)
+	badLabel[3].Label = 2
+	tests := []struct {
+		name    string
+		samples []utils.Sample
+	}{
+		{"short chunk", chunk[:ChunkSize-1]},
+		{"label not 0 or 1", badLabel},
+	}
+	for _, tt := range tests {
+		if _, _, err := BuildBalancedChunkWitness(DefaultCurve.ScalarField(), testModel.w, testModel.b, tt.samples); !errors.Is(err, ErrWitness) {
+			t.Errorf("%s: error %v, want ErrWitness", tt.name, err)
+		}
+	}
+	if _, _, err := BuildBalancedAggregatorWitness(nil); !errors.Is(err, ErrWitness) {
+		t.Errorf("no chunks: error %v, want ErrWitness", err)
+	}
+
+	// a recall is undefined for a class with no samples
+	noPositives := BalancedAggregatorCircuit{
+		TruePositives: [numChunks]frontend.Variable{0, 0, 0, 0},
+		Positives:     [numChunks]frontend.Variable{0, 0, 0, 0},
+		TrueNegatives: [numChunks]frontend.Variable{25, 0, 0, 0},
+		Negatives:     [numChunks]frontend.Variable{25, 0, 0, 0},
+	}
+	if err := solved(t, compiled(t, &BalancedAggregatorCircuit{}), &noPositives); err == nil {
+		t.Error("aggregator accepted a dataset without positives")
+	}
+}
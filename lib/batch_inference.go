@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ============================================================================
+// CIRCUIT 2D: Batch Inference Circuit
+// InferenceCircuit over len(X) samples in one proof: every prediction must
+// match its label. The sigmoid table and model commitment are paid for once
+// per batch instead of once per sample.
+// ============================================================================
+
+type BatchInferenceCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     []frontend.Variable `gnark:",public"`
+	Label []frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B); see LinearCircuit.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// Threshold is the compiled-in Q16 decision threshold; see SigmoidCircuit.
+	Threshold int64 `gnark:"-"`
+	// LUT optionally supplies precomputed table values; see SigmoidCircuit.
+	LUT []int64 `gnark:"-"`
+
+	table *sigmoidTable
+}
+
+// NewBatchInferenceCircuit returns the circuit to compile for batches of n
+// samples. It panics if n < 1, as that is a programming error.
+func NewBatchInferenceCircuit(n int) *BatchInferenceCircuit {
+	if n < 1 {
+		panic(fmt.Sprintf("lib: batch inference needs at least one sample, got %d", n))
+	}
+	return &BatchInferenceCircuit{X: make([]frontend.Variable, n), Label: make([]frontend.Variable, n)}
+}
+
+func (circuit *BatchInferenceCircuit) Define(api frontend.API) error {
+	if len(circuit.X) != len(circuit.Label) {
+		return fmt.Errorf("batch has %d marks but %d labels", len(circuit.X), len(circuit.Label))
+	}
+	if circuit.table == nil {
+		table, err := newSigmoidTable(api, circuit.LUT)
+		if err != nil {
+			return err
+		}
+		circuit.table = table
+	}
+	if err := assertModelCommitment(api, circuit.W, circuit.B, circuit.ModelCommitment); err != nil {
+		return err
+	}
+
+	w := New(api, circuit.W)
+	b := New(api, circuit.B)
+	threshold := thresholdOrDefault(circuit.Threshold)
+	for i := range circuit.X {
+		z := w.Mul(New(api, circuit.X[i])).Add(b)
+		prediction, _ := sigmoidPredict(api, circuit.table, z.Val, threshold)
+		api.AssertIsEqual(prediction, circuit.Label[i])
+	}
+	return nil
+}
+
+// BatchCost is the size of a BatchInferenceCircuit next to the
+// single-sample InferenceCircuit.
+type BatchCost struct {
+	Size          int
+	NbConstraints int
+	// PerSample is NbConstraints / Size, and Ratio is PerSample over the
+	// InferenceCircuit's constraint count: below 1 the batch is cheaper per
+	// sample than one proof per sample.
+	PerSample float64
+	Ratio     float64
+}
+
+// BatchInferenceCosts compiles the InferenceCircuit and a
+// BatchInferenceCircuit for each of sizes on DefaultCurve and reports their
+// constraints per sample.
+func BatchInferenceCosts(sizes []int) ([]BatchCost, error) {
+	single, err := Compile(BackendPlonk, DefaultCurve, &InferenceCircuit{})
+	if err != nil {
+		return nil, fmt.Errorf("inference circuit: %w", err)
+	}
+	singleCount := float64(single.GetNbConstraints())
+
+	costs := make([]BatchCost, 0, len(sizes))
+	for _, n := range sizes {
+		if n < 1 {
+			return costs, fmt.Errorf("batch inference needs at least one sample, got %d", n)
+		}
+		ccs, err := Compile(BackendPlonk, DefaultCurve, NewBatchInferenceCircuit(n))
+		if err != nil {
+			return costs, fmt.Errorf("batch of %d: %w", n, err)
+		}
+		perSample := float64(ccs.GetNbConstraints()) / float64(n)
+		costs = append(costs, BatchCost{
+			Size:          n,
+			NbConstraints: ccs.GetNbConstraints(),
+			PerSample:     perSample,
+			Ratio:         perSample / singleCount,
+		})
+	}
+	return costs, nil
+}
+
+// BatchInferenceWitness returns the full BatchInferenceCircuit witness over
+// field for samples, whose count must match the compiled batch size.
+func BatchInferenceWitness(field *big.Int, w, b float64, samples []utils.Sample) (witness.Witness, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w: batch inference needs at least one sample", ErrWitness)
+	}
+	assignment := NewBatchInferenceCircuit(len(samples))
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	assignment.W = wScaled
+	assignment.B = bScaled
+	for i, s := range samples {
+		assignment.X[i] = NewScaled(s.Marks)
+		assignment.Label[i] = big.NewInt(int64(s.Label))
+	}
+	assignment.ModelCommitment = modelCommitment(field, wScaled, bScaled)
+
+	full, err := frontend.NewWitness(assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: batch inference: %w", ErrWitness, err)
+	}
+	return full, nil
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestBatchInferenceCircuit(t *testing.T) {
+	samples := testChunk()[:4]
+	ccs := compiled(t, NewBatchInferenceCircuit(len(samples)))
+
+	wrongLabel := append([]utils.Sample(nil), samples...)
+	wrongLabel[2].Label = 1 - wrongLabel[2].Label
+
+	tests := []struct {
+		name    string
+		samples []utils.Sample
+		ok      bool
+	}{
+		{"labels match", samples, true},
+		{"one wrong label", wrongLabel, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			full, err := BatchInferenceWitness(ccs.Field(), testModel.w, testModel.b, tt.samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ccs.IsSolved(full)
+			if tt.ok && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Fatal("accepted")
+			}
+		})
+	}
+
+	if _, err := BatchInferenceWitness(ccs.Field(), testModel.w, testModel.b, nil); err == nil {
+		t.Error("empty batch built a witness")
+	}
+}
+
+func TestBatchInferenceCosts(t *testing.T) {
+	costs, err := BatchInferenceCosts([]int{1, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	single := compiled(t, &InferenceCircuit{}).GetNbConstraints()
+	if costs[0].NbConstraints != single || costs[0].Ratio != 1 {
+		t.Errorf("batch of 1 = %d constraints (ratio %v), want the inference circuit's %d", costs[0].NbConstraints, costs[0].Ratio, single)
+	}
+	if c := costs[1]; c.PerSample != float64(c.NbConstraints)/4 || c.Ratio >= 0.5 {
+		t.Errorf("batch of 4 = %d constraints, %v per sample, ratio %v; want under half the inference circuit's %d", c.NbConstraints, c.PerSample, c.Ratio, single)
+	}
+
+	if _, err := BatchInferenceCosts([]int{0}); err == nil {
+		t.Error("batch of 0 was costed")
+	}
+}
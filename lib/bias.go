@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+// ============================================================================
+// CIRCUIT 2E: Bias Circuit
+// InferenceCircuit at the degenerate input X = 0, where z = B and the
+// prediction is decided by sign(B) alone: a known-answer check of the
+// sigmoid threshold path.
+// ============================================================================
+
+type BiasCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	Label frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B); see LinearCircuit. W only
+	// enters through it.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// Threshold is the compiled-in Q16 decision threshold; see SigmoidCircuit.
+	Threshold int64 `gnark:"-"`
+	// LUT optionally supplies precomputed table values; see SigmoidCircuit.
+	LUT []int64 `gnark:"-"`
+
+	table *sigmoidTable
+}
+
+func (circuit *BiasCircuit) Define(api frontend.API) error {
+	if circuit.table == nil {
+		table, err := newSigmoidTable(api, circuit.LUT)
+		if err != nil {
+			return err
+		}
+		circuit.table = table
+	}
+	if err := assertModelCommitment(api, circuit.W, circuit.B, circuit.ModelCommitment); err != nil {
+		return err
+	}
+
+	// z = W*0 + B
+	prediction, _ := sigmoidPredict(api, circuit.table, circuit.B, thresholdOrDefault(circuit.Threshold))
+	api.AssertIsEqual(prediction, circuit.Label)
+	return nil
+}
+
+// BiasLabel is the known answer BiasCircuit proves at the default
+// threshold: 1 if b >= 0, else 0.
+func BiasLabel(b float64) int {
+	if NewScaled(b).Sign() >= 0 {
+		return 1
+	}
+	return 0
+}
+
+// BiasWitness returns the full BiasCircuit witness over field for the model
+// (w, b), with Label set to BiasLabel(b).
+func BiasWitness(field *big.Int, w, b float64) (witness.Witness, error) {
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	assignment := BiasCircuit{
+		W:               wScaled,
+		B:               bScaled,
+		Label:           BiasLabel(b),
+		ModelCommitment: modelCommitment(field, wScaled, bScaled),
+	}
+	full, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bias: %w", ErrWitness, err)
+	}
+	return full, nil
+}
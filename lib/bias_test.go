@@ -0,0 +1,56 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestBiasCircuit(t *testing.T) {
+	ccs := compiled(t, &BiasCircuit{})
+	field := DefaultCurve.ScalarField()
+	tests := []struct {
+		name string
+		b    float64
+		want int
+	}{
+		{"positive", 10, 1},
+		{"negative", -10, 0},
+		{"zero", 0, 1}, // z = 0 is the positive class
+		{"within one Q10 step above zero", 0.0001, 1},
+		{"within one Q10 step below zero", -0.0001, 0},
+		{"saturated positive", 50, 1},
+		{"saturated negative", -50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const w = 3.5 // irrelevant at X = 0
+			if got := BiasLabel(tt.b); got != tt.want {
+				t.Fatalf("BiasLabel = %d, want %d", got, tt.want)
+			}
+			if got := utils.PredictQuantized(w, tt.b, 0); got != tt.want {
+				t.Errorf("PredictQuantized at X = 0 is %d, want %d", got, tt.want)
+			}
+			full, err := BiasWitness(field, w, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ccs.IsSolved(full); err != nil {
+				t.Errorf("BiasWitness rejected: %v", err)
+			}
+
+			wScaled, bScaled := NewScaled(w), NewScaled(tt.b)
+			assignment := func(label int, commitment any) frontend.Circuit {
+				return &BiasCircuit{W: wScaled, B: bScaled, Label: label, ModelCommitment: commitment}
+			}
+			if err := solved(t, ccs, assignment(1-tt.want, modelCommitment(field, wScaled, bScaled))); err == nil {
+				t.Errorf("label %d accepted", 1-tt.want)
+			}
+			if err := solved(t, ccs, assignment(tt.want, testCommitment())); err == nil {
+				t.Error("another model's commitment accepted")
+			}
+		})
+	}
+}
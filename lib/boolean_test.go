@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// booleanCounting is frontend.API counting the AssertIsBoolean calls made
+// from lib's own code, not from the gnark gadgets it uses such as
+// api.ToBinary.
+type booleanCounting struct {
+	frontend.API
+	calls *int
+}
+
+func (api booleanCounting) AssertIsBoolean(v frontend.Variable) {
+	_, caller, _, _ := runtime.Caller(1)
+	_, self, _, _ := runtime.Caller(0)
+	if filepath.Dir(caller) == filepath.Dir(self) {
+		*api.calls++
+	}
+	api.API.AssertIsBoolean(v)
+}
+
+// countingCircuit compiles Circuit with its Define seeing booleanCounting.
+type countingCircuit struct {
+	Circuit frontend.Circuit
+
+	calls *int
+}
+
+func (c *countingCircuit) Define(api frontend.API) error {
+	return c.Circuit.Define(booleanCounting{api, c.calls})
+}
+
+// guardedPredictionCircuit is the check each classification circuit ends
+// with, on a Prediction taken from the witness as a faulty comparison
+// could produce it.
+type guardedPredictionCircuit struct {
+	Prediction frontend.Variable
+	Label      frontend.Variable `gnark:",public"`
+
+	unguarded bool
+}
+
+func (c *guardedPredictionCircuit) Define(api frontend.API) error {
+	if !c.unguarded {
+		api.AssertIsBoolean(c.Prediction)
+	}
+	api.AssertIsEqual(c.Prediction, c.Label)
+	return nil
+}
+
+func TestPredictionIsBoolean(t *testing.T) {
+	// one assertion per prediction, plus one per label where the circuit
+	// asserts its labels are boolean too
+	tests := []struct {
+		name    string
+		circuit frontend.Circuit
+		want    int
+	}{
+		{"sigmoid", &SigmoidCircuit{}, 1},
+		{"accuracy chunk", &AccuracyChunkCircuit{}, ChunkSize},
+		{"committed chunk", &CommittedChunkCircuit{}, 2 * ChunkSize},
+		{"private-label chunk", &PrivateLabelChunkCircuit{}, 2 * ChunkSize},
+		{"balanced chunk", &BalancedChunkCircuit{}, 2 * ChunkSize},
+		{"single proof", &AccuracyCircuit{}, NumSamples},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			compiled(t, &countingCircuit{Circuit: tt.circuit, calls: &calls})
+			if calls != tt.want {
+				t.Errorf("%d AssertIsBoolean calls, want %d", calls, tt.want)
+			}
+		})
+	}
+
+	// a prediction of 2 matching a malformed label of 2 passes the label
+	// check alone; the assertion rejects it
+	guarded := compiled(t, &guardedPredictionCircuit{})
+	unguarded := compiled(t, &guardedPredictionCircuit{unguarded: true})
+	for _, v := range []int{0, 1, 2} {
+		assignment := &guardedPredictionCircuit{Prediction: v, Label: v}
+		if err := solved(t, unguarded, assignment); err != nil {
+			t.Errorf("prediction %d without the assertion: %v", v, err)
+		}
+		if err := solved(t, guarded, assignment); (err != nil) != (v > 1) {
+			t.Errorf("prediction %d with the assertion: error %v", v, err)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package lib
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+// Save constraint system and keys to file. The data is written to a
+// temporary file in the same directory and renamed into place, so an
+// interrupted save never leaves a truncated cache behind. The file has no
+// config header (see ConfigHash), so LoadOrSetup recompiles rather than
+// trusting it.
+func SaveCircuitData(filename string, ccs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey) error {
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		return writeCircuitData(w, ccs, pk, vk)
+	})
+}
+
+// writeFileAtomic writes filename through write via a temporary file in the
+// same directory that is renamed into place, so readers never see a
+// partially written file.
+func writeFileAtomic(filename string, write func(io.Writer) error) error {
+	file, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // no-op once renamed
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filename)
+}
+
+func writeCircuitData(w io.Writer, ccs constraint.ConstraintSystem, pk, vk io.WriterTo) error {
+	// Write CCS
+	_, err := ccs.WriteTo(w)
+	if err != nil {
+		return err
+	}
+
+	// Write PK
+	_, err = pk.WriteTo(w)
+	if err != nil {
+		return err
+	}
+
+	// Write VK
+	_, err = vk.WriteTo(w)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Load constraint system and keys from file, skipping the config header of
+// a cache written by LoadOrSetup without checking it.
+func LoadCircuitData(filename string) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	return loadCircuitData(DefaultCurve, filename)
+}
+
+func loadCircuitData(curve ecc.ID, filename string) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	if _, err := readCacheHeader(r); err != nil {
+		return nil, nil, nil, err
+	}
+	return readCircuitData(curve, r)
+}
+
+func readCircuitData(curve ecc.ID, file io.Reader) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	// Read CCS
+	ccs := plonk.NewCS(curve)
+	_, err := ccs.ReadFrom(file)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: ccs: %w", ErrCacheCorrupt, err)
+	}
+
+	// Read PK
+	pk := plonk.NewProvingKey(curve)
+	_, err = pk.ReadFrom(file)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: pk: %w", ErrCacheCorrupt, err)
+	}
+
+	// Read VK
+	vk := plonk.NewVerifyingKey(curve)
+	_, err = vk.ReadFrom(file)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: vk: %w", ErrCacheCorrupt, err)
+	}
+
+	return ccs, pk, vk, nil
+}
+
+// LoadOrSetup returns the circuit's constraint system and keys from
+// cacheFile when it loads and matches circuit, and otherwise compiles and
+// sets up circuit and atomically rewrites cacheFile. A cache matches when its
+// header records circuit's ConfigHash and its public and secret input counts
+// are circuit's. A failure to write the cache is not an error; the next call
+// simply sets up again.
+func LoadOrSetup(cacheFile string, circuit frontend.Circuit) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	return loadOrSetup(DefaultCurve, cacheFile, circuit, func(string, ...any) {})
+}
+
+// LoadOrSetupCurve is LoadOrSetup on the given curve. A cache holding keys
+// for another curve fails to load and is set up again.
+func LoadOrSetupCurve(curve ecc.ID, cacheFile string, circuit frontend.Circuit) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	return loadOrSetup(curve, cacheFile, circuit, func(string, ...any) {})
+}
+
+// LoadOrSetupBackend is LoadOrSetupCurve under any backend, returning keys
+// that prove and verify through CircuitKeys. As in the pipeline, each
+// backend needs its own cacheFile.
+func LoadOrSetupBackend(backend Backend, curve ecc.ID, cacheFile string, circuit frontend.Circuit) (*CircuitKeys, error) {
+	return loadOrSetupKeys(backend, curve, cacheFile, circuit, nil, true, func(string, ...any) {})
+}
+
+func loadOrSetup(curve ecc.ID, cacheFile string, circuit frontend.Circuit, logf func(format string, args ...any)) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	k, err := loadOrSetupKeys(BackendPlonk, curve, cacheFile, circuit, nil, true, logf)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return k.CCS, k.plonkPK, k.plonkVK, nil
+}
+
+// loadOrSetupKeys is LoadOrSetup for any backend; each backend's cache holds
+// its own constraint system and keys, so they must not share a cacheFile.
+// Unless save is set a fresh setup is not written back. A non-nil srs is
+// used for a PLONK setup (see SetupWithSRS), and a cache set up against
+// another SRS is set up again.
+func loadOrSetupKeys(backend Backend, curve ecc.ID, cacheFile string, circuit frontend.Circuit, srs kzg.SRS, save bool, logf func(format string, args ...any)) (*CircuitKeys, error) {
+	config := ConfigHash(circuit)
+	if file, err := os.Open(cacheFile); err == nil {
+		k, err := readCacheFile(backend, curve, file, config)
+		file.Close()
+		if err == nil {
+			err = checkCircuitShape(backend, k.CCS, circuit)
+		}
+		if err == nil && srs != nil && backend == BackendPlonk {
+			err = checkKeysSRS(k, srs)
+		}
+		if err == nil {
+			logf("Loaded %s from cache\n", cacheFile)
+			return k, nil
+		}
+		logf("Error loading cache %s, recompiling: %v\n", cacheFile, err)
+	}
+
+	logf("Compiling and setting up circuit for %s...\n", cacheFile)
+	k, err := setupBackend(backend, curve, circuit, srs)
+	if err != nil {
+		return nil, err
+	}
+	if !save {
+		return k, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o755); err != nil {
+		logf("Warning: Failed to save cache: %v\n", err)
+	} else if err := writeCacheFile(cacheFile, config, k); err != nil {
+		logf("Warning: Failed to save cache: %v\n", err)
+	}
+	return k, nil
+}
+
+// checkCacheDir creates dir if it does not exist and checks that files can
+// be created in it, so an unusable cache directory is reported once rather
+// than by every failed save.
+func checkCacheDir(dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".write-check*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// cacheMagic starts a cache written by LoadOrSetup or WarmCaches, followed
+// by cacheFormatVersion and the circuit's raw ConfigHash.
+const (
+	cacheMagic         = "ZKLRCACHE"
+	cacheFormatVersion = 1
+)
+
+// ConfigHash returns a hex SHA-256 of the settings compiled into circuit
+// that its public and secret inputs do not show: the fixed-point and LUT
+// formats, MarginSteps, the chunk layout, the circuit's type and its
+// exported gnark:"-" scalar fields such as Threshold or IncludeBorderline,
+// and the contents of its scalar slice fields such as LUT, a nil LUT
+// counting as the table compilation computes for it. Caches record it, so
+// changing any of these recompiles the circuit rather than proving against
+// keys for the old one. A change to a circuit's Define
+// is not visible in any of them, so one that changes the constraints also
+// bumps the circuit's revision (see revisioned).
+func ConfigHash(circuit frontend.Circuit) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "precision=%d;input=%d;output=%d;maxInput=%d;margin=%d;chunk=%d;chunks=%d;samples=%d;maxMarks=%d;",
+		Precision, inputPrecision, outputPrecision, MaxInput, MarginSteps, ChunkSize, numChunks, NumSamples, MaxMarks)
+
+	v := reflect.Indirect(reflect.ValueOf(circuit))
+	fmt.Fprintf(h, "%s;", v.Type())
+	if r, ok := circuit.(revisioned); ok {
+		fmt.Fprintf(h, "revision=%d;", r.revision())
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() || f.Tag.Get("gnark") != "-" {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Slice, reflect.Array:
+			// e.g. LUT: its values are compiled in, so they are hashed too
+			if !isScalarKind(f.Type.Elem().Kind()) {
+				continue
+			}
+			value := v.Field(i).Interface()
+			if f.Name == "LUT" && v.Field(i).Kind() == reflect.Slice && v.Field(i).IsNil() {
+				value = ComputeSigmoidTable(lutConfigOf(v))
+			}
+			fmt.Fprintf(h, "%s=%#v;", f.Name, value)
+			continue
+		case reflect.Map, reflect.Struct, reflect.Pointer:
+			continue
+		}
+		fmt.Fprintf(h, "%s=%#v;", f.Name, v.Field(i).Interface())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lutConfigOf returns the LUTConfig a circuit with a nil LUT computes its
+// table for: DefaultLUTConfig with the circuit's InterpolationSteps, if it
+// has that field.
+func lutConfigOf(circuit reflect.Value) LUTConfig {
+	cfg := DefaultLUTConfig()
+	if steps := circuit.FieldByName("InterpolationSteps"); steps.IsValid() && steps.Kind() == reflect.Int {
+		cfg.InterpolationSteps = int(steps.Int())
+	}
+	return cfg
+}
+
+// isScalarKind reports whether values of kind print as themselves under
+// %#v, so ConfigHash can hash slices of them by content.
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// revisioned is implemented by circuits whose Define has changed since
+// their caches were first written; revision starts at 2 and increases with
+// every change to the constraints.
+type revisioned interface {
+	revision() int
+}
+
+// writeCacheFile atomically writes k to filename behind a header recording
+// config.
+func writeCacheFile(filename, config string, k *CircuitKeys) error {
+	sum, err := hex.DecodeString(config)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		header := append([]byte(cacheMagic), cacheFormatVersion)
+		if _, err := w.Write(append(header, sum...)); err != nil {
+			return err
+		}
+		return k.writeTo(w)
+	})
+}
+
+// readCacheFile reads a cache written by writeCacheFile, rejecting it as
+// stale before decoding any keys unless its header records config.
+func readCacheFile(backend Backend, curve ecc.ID, file io.Reader, config string) (*CircuitKeys, error) {
+	r := bufio.NewReader(file)
+	got, err := readCacheHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if got != config {
+		if got == "" {
+			got = "none"
+		}
+		return nil, fmt.Errorf("%w: stale: cache config hash %.12s, circuit has %.12s", ErrCacheCorrupt, got, config)
+	}
+	return readCircuitKeys(backend, curve, r)
+}
+
+// readCacheHeader consumes the header at the start of r and returns its
+// config hash, or "" if r starts directly with the constraint system, as a
+// SaveCircuitData file or a cache from before headers does.
+func readCacheHeader(r *bufio.Reader) (string, error) {
+	if magic, err := r.Peek(len(cacheMagic)); err != nil || string(magic) != cacheMagic {
+		return "", nil
+	}
+	r.Discard(len(cacheMagic))
+	version, err := r.ReadByte()
+	if err != nil {
+		return "", fmt.Errorf("%w: header: %w", ErrCacheCorrupt, err)
+	}
+	if version != cacheFormatVersion {
+		return "", fmt.Errorf("%w: cache format version %d, want %d", ErrCacheCorrupt, version, cacheFormatVersion)
+	}
+	var sum [sha256.Size]byte
+	if _, err := io.ReadFull(r, sum[:]); err != nil {
+		return "", fmt.Errorf("%w: header: %w", ErrCacheCorrupt, err)
+	}
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkCircuitShape reports a cache written for an older version of circuit
+// (e.g. before a public input was added) as stale, since proving with it
+// would fail or, worse, prove the old statement.
+func checkCircuitShape(backend Backend, ccs constraint.ConstraintSystem, circuit frontend.Circuit) error {
+	count, err := schema.Walk(circuit, reflect.TypeOf((*frontend.Variable)(nil)).Elem(), nil)
+	if err != nil {
+		return err
+	}
+	public := ccs.GetNbPublicVariables()
+	if backend == BackendGroth16 {
+		public-- // an R1CS counts its constant-one wire as public
+	}
+	if public != count.Public || ccs.GetNbSecretVariables() != count.Secret {
+		return fmt.Errorf("%w: stale: cache has %d public/%d secret inputs, circuit has %d/%d",
+			ErrCacheCorrupt, public, ccs.GetNbSecretVariables(), count.Public, count.Secret)
+	}
+	return nil
+}
+
+// Cache file names, relative to the cache directory.
+const (
+	LinearCacheFile     = "linear_circuit.cache"
+	SigmoidCacheFile    = "threshold_circuit.cache"
+	InferenceCacheFile  = "inference_circuit.cache"
+	ChunkCacheFile      = "accuracy_chunk_25.cache"
+	AggregatorCacheFile = "aggregator_circuit.cache"
+)
+
+// aggregatorCacheFile names the aggregator cache for a minCorrect policy; the
+// default policy keeps the original file name.
+func aggregatorCacheFile(minCorrect int) string {
+	if minCorrectOrDefault(minCorrect) == DefaultMinCorrect {
+		return AggregatorCacheFile
+	}
+	return fmt.Sprintf("aggregator_circuit_min%d.cache", minCorrect)
+}
+
+// chunkCacheFile names the chunk circuit cache; the default (borderline
+// excluding) circuit keeps the original file name.
+func chunkCacheFile(includeBorderline bool) string {
+	if includeBorderline {
+		return "accuracy_chunk_25_all.cache"
+	}
+	return ChunkCacheFile
+}
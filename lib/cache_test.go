@@ -0,0 +1,199 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestConfigHash(t *testing.T) {
+	lut := ComputeSigmoidTable(DefaultLUTConfig())
+	edited := slices.Clone(lut)
+	edited[len(edited)/2]++
+
+	tests := []struct {
+		name string
+		a, b frontend.Circuit
+		same bool
+	}{
+		{"same circuit", &SigmoidCircuit{}, &SigmoidCircuit{}, true},
+		{"same LUT", &SigmoidCircuit{LUT: lut}, &SigmoidCircuit{LUT: slices.Clone(lut)}, true},
+		{"nil LUT", &SigmoidCircuit{}, &SigmoidCircuit{LUT: lut}, true},
+		{"nil LUT with interpolation", &SigmoidCircuit{InterpolationSteps: 4}, &SigmoidCircuit{InterpolationSteps: 4, LUT: ComputeSigmoidTable(LUTConfig{InputPrecision: inputPrecision, OutputPrecision: outputPrecision, MaxInput: MaxInput, InterpolationSteps: 4})}, true},
+		{"nil inference LUT", &InferenceCircuit{}, &InferenceCircuit{LUT: lut}, true},
+		{"threshold", &SigmoidCircuit{}, &SigmoidCircuit{Threshold: DefaultThreshold + 1}, false},
+		{"one LUT entry", &SigmoidCircuit{LUT: lut}, &SigmoidCircuit{LUT: edited}, false},
+		{"borderline", NewAccuracyChunkCircuit(true), NewAccuracyChunkCircuit(false), false},
+		{"circuit type", &SigmoidCircuit{}, &InferenceCircuit{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := ConfigHash(tt.a) == ConfigHash(tt.b); same != tt.same {
+				t.Errorf("hashes equal = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestCacheRejectsOtherConfig(t *testing.T) {
+	circuit := &LinearCircuit{}
+	keys, err := SetupBackend(BackendPlonk, DefaultCurve, circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := sha256.Sum256([]byte("precision=16;"))
+	current := ConfigHash(circuit)
+
+	tests := []struct {
+		name    string
+		written string
+		stale   bool
+	}{
+		{"current config", current, false},
+		{"other config", hex.EncodeToString(old[:]), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "linear.cache")
+			if err := writeCacheFile(file, tt.written, keys); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			_, err = readCacheFile(BackendPlonk, DefaultCurve, f, current)
+			if !tt.stale && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if tt.stale && (!errors.Is(err, ErrCacheCorrupt) || !strings.Contains(err.Error(), "stale")) {
+				t.Fatalf("err = %v, want a stale ErrCacheCorrupt", err)
+			}
+
+			var log strings.Builder
+			logf := func(format string, args ...any) { log.WriteString(format) }
+			if _, err := loadOrSetupKeys(BackendPlonk, DefaultCurve, file, circuit, nil, false, logf); err != nil {
+				t.Fatal(err)
+			}
+			if recompiled := strings.Contains(log.String(), "recompiling"); recompiled != tt.stale {
+				t.Errorf("recompiled = %v, want %v; log: %s", recompiled, tt.stale, log.String())
+			}
+		})
+	}
+}
+
+func TestLoadOrSetupRecoversCorruptCache(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "linear.cache")
+	if _, _, _, err := LoadOrSetup(file, &LinearCircuit{}); err != nil {
+		t.Fatal(err)
+	}
+	good, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"garbage", []byte("not a cache file")},
+		{"header only", good[:len(cacheMagic)+1+sha256.Size]},
+		{"truncated", good[:len(good)/2]},
+		{"other format version", append(append([]byte(cacheMagic), cacheFormatVersion+1), good[len(cacheMagic)+1:]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(file, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var log strings.Builder
+			logf := func(format string, args ...any) { log.WriteString(format) }
+			ccs, pk, vk, err := loadOrSetup(DefaultCurve, file, &LinearCircuit{}, logf)
+			if err != nil {
+				t.Fatalf("not recovered: %v", err)
+			}
+			if !strings.Contains(log.String(), "recompiling") {
+				t.Errorf("corrupt cache was not reported; log: %s", log.String())
+			}
+
+			full, err := LinearWitness(ccs.Field(), testModel.w, testModel.b, 30)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys := plonkKeys(ccs, pk, vk)
+			proof, err := keys.Prove(full)
+			if err != nil {
+				t.Fatal(err)
+			}
+			public, err := full.Public()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := keys.Verify(proof, public); err != nil {
+				t.Errorf("recompiled keys: %v", err)
+			}
+
+			// the rewritten cache loads without another setup
+			log.Reset()
+			if _, _, _, err := loadOrSetup(DefaultCurve, file, &LinearCircuit{}, logf); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(log.String(), "Loaded") {
+				t.Errorf("rewritten cache did not load; log: %s", log.String())
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("cache directory holds %d files, want only the cache", len(entries))
+			}
+		})
+	}
+}
+
+func TestCheckCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr bool
+	}{
+		{"existing", dir, false},
+		{"created", filepath.Join(dir, "new", "nested"), false},
+		// not creatable even as root, unlike a read-only directory
+		{"a file", file, true},
+		{"under a file", filepath.Join(file, "cache"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCacheDir(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			entries, err := os.ReadDir(tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".write-check") {
+					t.Errorf("left %s behind", e.Name())
+				}
+			}
+		})
+	}
+}
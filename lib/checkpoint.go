@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint persists per-sample proofs as they are generated, so an
+// interrupted batch can resume without proving the same samples again.
+type Checkpoint struct {
+	dir string
+	// salt ties entries to the circuits they were proved with, e.g. the
+	// verifying key fingerprints; entries written under another salt are
+	// ignored.
+	salt string
+	// resume reuses existing entries; otherwise they are overwritten.
+	resume bool
+}
+
+// NewCheckpoint returns a checkpoint stored in dir, created on first write.
+// With resume, entries already in dir are reused when written under the same
+// salt; otherwise every sample is proved and its entry overwritten.
+func NewCheckpoint(dir, salt string, resume bool) *Checkpoint {
+	return &Checkpoint{dir: dir, salt: salt, resume: resume}
+}
+
+// checkpointEntry is the on-disk form of one sample's ProofData. Linear is
+// omitted for a run with PipelineConfig.SkipLinear.
+type checkpointEntry struct {
+	Key           string         `json:"key"`
+	SampleNum     int            `json:"sample"`
+	Mark          float64        `json:"mark"`
+	ExpectedLabel int            `json:"label"`
+	Linear        *ProofEnvelope `json:"linear,omitempty"`
+	Sigmoid       ProofEnvelope  `json:"sigmoid"`
+}
+
+// key identifies sample i of a batch by everything its proofs depend on.
+func (c *Checkpoint) key(w, b float64, i int, mark float64, label int) string {
+	h := sha256.New()
+	h.Write([]byte(c.salt))
+	for _, v := range []uint64{math.Float64bits(w), math.Float64bits(b), uint64(i), math.Float64bits(mark), uint64(label)} {
+		binary.Write(h, binary.LittleEndian, v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// load returns the checkpointed proofs for sample i, if present and written
+// for the same key.
+func (c *Checkpoint) load(key string, i int) (ProofData, bool) {
+	if !c.resume {
+		return ProofData{}, false
+	}
+	data, err := os.ReadFile(c.path(i))
+	if err != nil {
+		return ProofData{}, false
+	}
+	var e checkpointEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return ProofData{}, false
+	}
+
+	pd := ProofData{Mark: e.Mark, ExpectedLabel: e.ExpectedLabel, SampleNum: e.SampleNum}
+	if e.Linear != nil {
+		if _, pd.LinearProof, pd.LinearPublic, err = e.Linear.decode(); err != nil {
+			return ProofData{}, false
+		}
+	}
+	if _, pd.SigmoidProof, pd.SigmoidPublic, err = e.Sigmoid.decode(); err != nil {
+		return ProofData{}, false
+	}
+	return pd, true
+}
+
+// save atomically writes the proofs for sample i, made with backend.
+func (c *Checkpoint) save(key string, i int, backend Backend, pd ProofData) error {
+	var linear *ProofEnvelope
+	if pd.LinearProof != nil {
+		e, err := newProofEnvelope("linear", backend, pd.LinearProof, "", pd.LinearPublic)
+		if err != nil {
+			return err
+		}
+		linear = &e
+	}
+	sigmoid, err := newProofEnvelope("sigmoid", backend, pd.SigmoidProof, "", pd.SigmoidPublic)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(checkpointEntry{
+		Key:           key,
+		SampleNum:     pd.SampleNum,
+		Mark:          pd.Mark,
+		ExpectedLabel: pd.ExpectedLabel,
+		Linear:        linear,
+		Sigmoid:       sigmoid,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(c.path(i), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (c *Checkpoint) path(i int) string {
+	return filepath.Join(c.dir, fmt.Sprintf("sample_%06d.json", i+1))
+}
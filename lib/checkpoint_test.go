@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// encoded serialises proof, failing t on error.
+func encoded(t *testing.T, proof Proof) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckpointResume(t *testing.T) {
+	linear, sigmoid := sampleKeys(t, BackendGroth16)
+	marks := []float64{10, 30, 15, 40, 25}
+	labels := testLabels(marks)
+	run := func(ctx context.Context, checkpoint *Checkpoint, stopAfter int) ([]ProofData, int, int) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		progress := func(done, total int) {
+			if done == stopAfter {
+				cancel()
+			}
+		}
+		proofs, failures, timings, resumed := proveSamples(ctx, checkpoint, linear, sigmoid, testModel.w, testModel.b, marks, labels, 1, progress)
+		if len(failures) != 0 {
+			t.Fatalf("failures: %v", failures)
+		}
+		return proofs, resumed, timings.Count
+	}
+
+	dir := t.TempDir()
+	// interrupt after two proofs; the sample being fed when the run is
+	// cancelled may still be proved
+	interrupted, resumed, proved := run(context.Background(), NewCheckpoint(dir, "salt", true), 2)
+	n := len(interrupted)
+	if n < 2 || n == len(marks) || resumed != 0 || proved != n {
+		t.Fatalf("interrupted run: %d proofs, %d resumed, %d proved", n, resumed, proved)
+	}
+
+	tests := []struct {
+		name    string
+		salt    string
+		resume  bool
+		corrupt bool
+		resumed int
+	}{
+		{"resume", "salt", true, false, n},
+		{"resume with a corrupt entry", "salt", true, true, n - 1},
+		{"other salt", "other", true, false, 0},
+		{"without resume", "salt", false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runDir := t.TempDir()
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(runDir, e.Name()), data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.corrupt {
+				if err := os.WriteFile(filepath.Join(runDir, "sample_000001.json"), []byte("{"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			proofs, resumed, proved := run(context.Background(), NewCheckpoint(runDir, tt.salt, tt.resume), 0)
+			if resumed != tt.resumed || proved != len(marks)-tt.resumed {
+				t.Fatalf("%d resumed and %d proved, want %d resumed", resumed, proved, tt.resumed)
+			}
+			if len(proofs) != len(marks) {
+				t.Fatalf("%d proofs for %d samples", len(proofs), len(marks))
+			}
+			for i, pd := range proofs {
+				if pd.SampleNum != i+1 || pd.Mark != marks[i] || pd.ExpectedLabel != labels[i] {
+					t.Errorf("proof %d: sample %d, marks %v, label %d", i+1, pd.SampleNum, pd.Mark, pd.ExpectedLabel)
+				}
+				if err := linear.Verify(pd.LinearProof, pd.LinearPublic); err != nil {
+					t.Errorf("sample %d linear: %v", i+1, err)
+				}
+				if err := sigmoid.Verify(pd.SigmoidProof, pd.SigmoidPublic); err != nil {
+					t.Errorf("sample %d sigmoid: %v", i+1, err)
+				}
+				// Groth16 proofs are randomised, so only a reused proof has
+				// the interrupted run's bytes
+				reused := i < n && (!tt.corrupt || i > 0) && tt.resumed > 0
+				same := i < n && bytes.Equal(encoded(t, pd.SigmoidProof), encoded(t, interrupted[i].SigmoidProof))
+				if same != reused {
+					t.Errorf("sample %d: sigmoid proof reused = %v, want %v", i+1, same, reused)
+				}
+			}
+		})
+	}
+}
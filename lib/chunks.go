@@ -0,0 +1,284 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ChunkResult is a proved AccuracyChunkCircuit instance and its count of
+// correct predictions.
+type ChunkResult struct {
+	Key   string
+	Count int
+	Proof Proof
+	// Public is the public witness Proof verifies against. It is rebuilt on
+	// every call rather than cached.
+	Public witness.Witness
+}
+
+// ChunkCache stores chunk proofs keyed by a hash of the model and the
+// chunk's samples, so when a dataset grows or one sample changes only the
+// affected chunks are re-proved. Entries are kept in memory and, when dir is
+// set, as one file per chunk so they survive across runs. It is safe for
+// concurrent use.
+type ChunkCache struct {
+	// IncludeBorderline must match the chunk circuit the cache proves with
+	// (see AccuracyChunkCircuit); it is part of every key.
+	IncludeBorderline bool
+
+	dir     string
+	mu      sync.Mutex
+	entries map[string]ChunkResult
+}
+
+// NewChunkCache returns a cache persisting to dir, or memory-only if dir is "".
+func NewChunkCache(dir string) *ChunkCache {
+	return &ChunkCache{dir: dir, entries: make(map[string]ChunkResult)}
+}
+
+// ChunkKey hashes the fixed-point model parameters and a chunk's samples.
+func ChunkKey(w, b float64, marks []float64, labels []int) string {
+	h := sha256.New()
+	for _, v := range []float64{w, b} {
+		fmt.Fprintf(h, "%s;", NewScaled(v))
+	}
+	for i := range marks {
+		fmt.Fprintf(h, "%s,%d;", NewScaled(marks[i]), labels[i])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Prove returns the chunk proof for the given samples, reusing a cached
+// proof when one exists and still verifies under vk (a changed chunk circuit
+// invalidates old entries). A new proof is verified under vk before it is
+// cached or returned, so every result's count is backed by a verified proof.
+// The second result reports whether the proof came from the cache.
+func (c *ChunkCache) Prove(
+	ccs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey,
+	w, b float64, marks []float64, labels []int,
+) (ChunkResult, bool, error) {
+	return c.prove(plonkKeys(ccs, pk, vk), w, b, marks, labels)
+}
+
+// prove is Prove under any backend. Proofs from different backends must not
+// share a cache directory.
+func (c *ChunkCache) prove(keys *CircuitKeys, w, b float64, marks []float64, labels []int) (ChunkResult, bool, error) {
+	if len(marks) != ChunkSize || len(labels) != ChunkSize {
+		return ChunkResult{}, false, fmt.Errorf("%w: chunk needs %d samples, got %d", ErrWitness, ChunkSize, len(marks))
+	}
+	key := ChunkKey(w, b, marks, labels)
+	if c.IncludeBorderline {
+		key += "-all"
+	}
+
+	full, err := chunkWitness(keys.CCS.Field(), w, b, marks, labels, c.IncludeBorderline)
+	if err != nil {
+		return ChunkResult{}, false, err
+	}
+	public, err := full.Public()
+	if err != nil {
+		return ChunkResult{}, false, fmt.Errorf("%w: chunk public: %w", ErrWitness, err)
+	}
+
+	if r, ok := c.lookup(keys.Backend, curveOf(keys.CCS), key); ok && keys.Verify(r.Proof, public) == nil {
+		// trust the count the proof verified against, not the stored one
+		if r.Count, err = PublicChunkCount(public); err != nil {
+			return ChunkResult{}, false, err
+		}
+		r.Public = public
+		return r, true, nil
+	}
+
+	proof, count, _, err := proveChunk(keys, full)
+	if err != nil {
+		return ChunkResult{}, false, err
+	}
+	if err := keys.Verify(proof, public); err != nil {
+		return ChunkResult{}, false, fmt.Errorf("chunk: %w", err)
+	}
+	r := ChunkResult{Key: key, Count: count, Proof: proof}
+	c.store(r)
+	r.Public = public
+	return r, false, nil
+}
+
+func (c *ChunkCache) lookup(backend Backend, curve ecc.ID, key string) (ChunkResult, bool) {
+	c.mu.Lock()
+	r, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok || c.dir == "" {
+		return r, ok
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil || len(data) < 8 {
+		return ChunkResult{}, false
+	}
+	proof := newProof(backend, curve)
+	if _, err := proof.ReadFrom(bytes.NewReader(data[8:])); err != nil {
+		return ChunkResult{}, false
+	}
+	r = ChunkResult{Key: key, Count: int(binary.LittleEndian.Uint64(data)), Proof: proof}
+
+	c.mu.Lock()
+	c.entries[key] = r
+	c.mu.Unlock()
+	return r, true
+}
+
+// store records r in memory and, best effort, on disk; a failed write only
+// means the chunk is proved again next run.
+func (c *ChunkCache) store(r ChunkResult) {
+	c.mu.Lock()
+	c.entries[r.Key] = r
+	c.mu.Unlock()
+	if c.dir == "" {
+		return
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint64(r.Count))
+	if _, err := r.Proof.WriteTo(&buf); err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	os.WriteFile(c.path(r.Key), buf.Bytes(), 0o644)
+}
+
+func (c *ChunkCache) path(key string) string {
+	return filepath.Join(c.dir, "chunk_"+key+".proof")
+}
+
+// ProveChunkCount proves an AccuracyChunkCircuit over samples and returns
+// the proof, its proven count of eligible correct predictions and the public
+// witness it verifies against. The count is read back from the public
+// witness, so it is exactly the value the proof attests to. It is for the
+// default chunk circuit, which excludes borderline samples.
+func ProveChunkCount(pk plonk.ProvingKey, ccs constraint.ConstraintSystem, w, b float64, samples []utils.Sample) (plonk.Proof, int, witness.Witness, error) {
+	if len(samples) != ChunkSize {
+		return nil, 0, nil, fmt.Errorf("%w: chunk needs %d samples, got %d", ErrWitness, ChunkSize, len(samples))
+	}
+	marks := make([]float64, len(samples))
+	labels := make([]int, len(samples))
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+
+	full, err := chunkWitness(ccs.Field(), w, b, marks, labels, false)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return proveChunk(plonkKeys(ccs, pk, nil), full)
+}
+
+func proveChunk(keys *CircuitKeys, full witness.Witness) (Proof, int, witness.Witness, error) {
+	public, err := full.Public()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: chunk public: %w", ErrWitness, err)
+	}
+	proof, err := keys.Prove(full)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("chunk: %w", err)
+	}
+	count, err := PublicChunkCount(public)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return proof, count, public, nil
+}
+
+// PublicChunkCount extracts Count from an AccuracyChunkCircuit public
+// witness, laid out as [X..., Label..., Count, ModelCommitment].
+func PublicChunkCount(public witness.Witness) (int, error) {
+	v, err := publicElement(public, 2*ChunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("%w: chunk count: %w", ErrWitness, err)
+	}
+	if !v.IsUint64() || v.Uint64() > ChunkSize {
+		return 0, fmt.Errorf("%w: chunk count %s out of range", ErrWitness, v.String())
+	}
+	return int(v.Uint64()), nil
+}
+
+// BuildAggregatorWitness assembles the AggregatorCircuit assignment from the
+// public witnesses of its chunk proofs, in order, so the aggregator proves
+// over exactly the counts the chunks proved. Fewer than numChunks chunks
+// leave the remaining counts at 0, as for a small dataset's ChunkLayout.
+func BuildAggregatorWitness(chunkPublics []witness.Witness) (AggregatorCircuit, error) {
+	if len(chunkPublics) < 1 || len(chunkPublics) > numChunks {
+		return AggregatorCircuit{}, fmt.Errorf("%w: aggregator needs 1 to %d chunks, got %d", ErrWitness, numChunks, len(chunkPublics))
+	}
+	var counts [numChunks]*big.Int
+	for i := range counts {
+		counts[i] = big.NewInt(0)
+	}
+	for i, public := range chunkPublics {
+		count, err := PublicChunkCount(public)
+		if err != nil {
+			return AggregatorCircuit{}, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+		counts[i] = big.NewInt(int64(count))
+	}
+	return AggregatorCircuit{Count1: counts[0], Count2: counts[1], Count3: counts[2], Count4: counts[3]}, nil
+}
+
+func chunkWitness(field *big.Int, w, b float64, marks []float64, labels []int, includeBorderline bool) (witness.Witness, error) {
+	if err := checkMarks(marks); err != nil {
+		return nil, err
+	}
+	var assignment AccuracyChunkCircuit
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	xs := quantizeMarks(marks)
+	assignment.W = wScaled
+	assignment.B = bScaled
+	for i := 0; i < ChunkSize; i++ {
+		assignment.X[i] = xs[i]
+		assignment.Label[i] = big.NewInt(int64(labels[i]))
+	}
+	assignment.Count = chunkCount(wScaled, bScaled, xs, labels, includeBorderline)
+	assignment.ModelCommitment = modelCommitment(field, wScaled, bScaled)
+
+	full, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: chunk: %w", ErrWitness, err)
+	}
+	return full, nil
+}
+
+// chunkCount mirrors AccuracyChunkCircuit in Q32 to fill in the Count the
+// circuit will check: a sample counts if sign(z) matches its label and,
+// unless includeBorderline is set, |z| is at least MarginSteps in Q10.
+func chunkCount(wScaled, bScaled *big.Int, xs []*big.Int, labels []int, includeBorderline bool) int {
+	count := 0
+	for i := range xs {
+		z := linearZScaled(wScaled, bScaled, xs[i])
+
+		pred := 0
+		if z.Sign() >= 0 {
+			pred = 1
+		}
+		zIn := RescaleInt(z, Precision, inputPrecision)
+		eligible := includeBorderline || zIn.CmpAbs(big.NewInt(MarginSteps)) >= 0
+		if eligible && pred == labels[i] {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,304 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// testDataset returns NumSamples samples with marks 0, 1, ..., 99, labelled
+// by PredictQuantized for testModel, split into its numChunks chunks of marks
+// and labels.
+func testDataset() (marks [][]float64, labels [][]int) {
+	marks, labels = make([][]float64, numChunks), make([][]int, numChunks)
+	for c := range marks {
+		marks[c], labels[c] = make([]float64, ChunkSize), make([]int, ChunkSize)
+		for i := range marks[c] {
+			x := float64(c*ChunkSize + i)
+			marks[c][i], labels[c][i] = x, utils.PredictQuantized(testModel.w, testModel.b, x)
+		}
+	}
+	return marks, labels
+}
+
+func TestChunkKey(t *testing.T) {
+	marks, labels := testDataset()
+	keys := func(w, b float64, marks [][]float64, labels [][]int) []string {
+		k := make([]string, len(marks))
+		for c := range marks {
+			k[c] = ChunkKey(w, b, marks[c], labels[c])
+		}
+		return k
+	}
+	base := keys(testModel.w, testModel.b, marks, labels)
+
+	tests := []struct {
+		name    string
+		edit    func(marks [][]float64, labels [][]int)
+		w       float64
+		changed int // chunks whose key changes
+	}{
+		{"unchanged", func([][]float64, [][]int) {}, testModel.w, 0},
+		{"one mark", func(m [][]float64, _ [][]int) { m[2][7] += 0.5 }, testModel.w, 1},
+		{"one label", func(_ [][]float64, l [][]int) { l[0][3] = 1 - l[0][3] }, testModel.w, 1},
+		{"two chunks", func(m [][]float64, _ [][]int) { m[1][0]++; m[3][24]-- }, testModel.w, 2},
+		{"model", func([][]float64, [][]int) {}, testModel.w + 0.01, numChunks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, l := testDataset()
+			tt.edit(m, l)
+			changed := 0
+			for c, k := range keys(tt.w, testModel.b, m, l) {
+				if k != base[c] {
+					changed++
+				}
+			}
+			if changed != tt.changed {
+				t.Errorf("%d chunk keys changed, want %d", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestChunkCacheReprovesOnlyChangedChunk(t *testing.T) {
+	keys := chunkKeys(t)
+	dir := t.TempDir()
+	marks, labels := testDataset()
+
+	// proveAll proves every chunk through cache and returns how many were
+	// proved rather than taken from the cache.
+	proveAll := func(cache *ChunkCache) int {
+		t.Helper()
+		proved := 0
+		for c := range marks {
+			r, cached, err := cache.prove(keys, testModel.w, testModel.b, marks[c], labels[c])
+			if err != nil {
+				t.Fatalf("chunk %d: %v", c+1, err)
+			}
+			if r.Count != chunkCount(NewScaled(testModel.w), NewScaled(testModel.b), quantizeMarks(marks[c]), labels[c], false) {
+				t.Errorf("chunk %d: count %d", c+1, r.Count)
+			}
+			if !cached {
+				proved++
+			}
+		}
+		return proved
+	}
+
+	cache := NewChunkCache(dir)
+	if proved := proveAll(cache); proved != numChunks {
+		t.Fatalf("first run proved %d chunks, want %d", proved, numChunks)
+	}
+	if proved := proveAll(cache); proved != 0 {
+		t.Errorf("unchanged dataset re-proved %d chunks", proved)
+	}
+	marks[2][7] += 0.5
+	if proved := proveAll(cache); proved != 1 {
+		t.Errorf("one changed sample re-proved %d chunks, want 1", proved)
+	}
+	if proved := proveAll(NewChunkCache(dir)); proved != 0 {
+		t.Errorf("a new cache on the same directory re-proved %d chunks", proved)
+	}
+}
+
+func TestProveChunkCount(t *testing.T) {
+	heavy(t, "sets up the accuracy chunk circuit under PLONK")
+	ccs, pk, vk, err := Setup(&AccuracyChunkCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := testChunk()
+	// two samples mislabelled and one inside the margin around z = 0
+	samples[0].Label = 1 - samples[0].Label
+	samples[24].Label = 1 - samples[24].Label
+	samples[4].Marks = 20
+
+	proof, count, public, err := ProveChunkCount(pk, ccs, testModel.w, testModel.b, samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != ChunkSize-3 {
+		t.Errorf("count = %d, want %d", count, ChunkSize-3)
+	}
+	if proven, err := PublicChunkCount(public); err != nil || proven != count {
+		t.Errorf("public count %d (%v), returned %d", proven, err, count)
+	}
+	if err := Verify(proof, vk, public); err != nil {
+		t.Fatalf("proof does not verify against its public witness: %v", err)
+	}
+
+	marks := make([]float64, ChunkSize)
+	labels := make([]int, ChunkSize)
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	marks[4] = 19 // outside the margin, so the proven count is one higher
+	other, err := chunkWitness(ccs.Field(), testModel.w, testModel.b, marks, labels, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, err := other.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := PublicChunkCount(otherPublic); n != count+1 {
+		t.Fatalf("other chunk counts %d, want %d", n, count+1)
+	}
+	if err := Verify(proof, vk, otherPublic); err == nil {
+		t.Error("proof verified against another chunk's count")
+	}
+
+	if _, _, _, err := ProveChunkCount(pk, ccs, testModel.w, testModel.b, samples[:ChunkSize-1]); !errors.Is(err, ErrWitness) {
+		t.Errorf("short chunk: err = %v, want ErrWitness", err)
+	}
+}
+
+func TestBuildAggregatorWitness(t *testing.T) {
+	field := DefaultCurve.ScalarField()
+	marks, labels := testDataset()
+	// chunk c has its first c labels flipped; marks 20, in the first chunk, is
+	// borderline and excluded
+	want := []int{24, 24, 23, 22}
+	publics := make([]witness.Witness, numChunks)
+	for c := range publics {
+		for i := 0; i < c; i++ {
+			labels[c][i] = 1 - labels[c][i]
+		}
+		full, err := chunkWitness(field, testModel.w, testModel.b, marks[c], labels[c], false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if publics[c], err = full.Public(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linear, err := LinearWitness(field, testModel.w, testModel.b, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linearPublic, err := linear.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		publics []witness.Witness
+		counts  []int
+		wantErr bool
+	}{
+		{"all chunks", publics, want, false},
+		{"one chunk", publics[3:], []int{22, 0, 0, 0}, false},
+		{"reordered", []witness.Witness{publics[2], publics[0]}, []int{23, 24, 0, 0}, false},
+		{"no chunks", nil, nil, true},
+		{"too many chunks", append(publics[:numChunks:numChunks], publics[0]), nil, true},
+		{"not a chunk", []witness.Witness{publics[0], linearPublic}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildAggregatorWitness(tt.publics)
+			if tt.wantErr {
+				if !errors.Is(err, ErrWitness) {
+					t.Fatalf("err = %v, want ErrWitness", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range []frontend.Variable{got.Count1, got.Count2, got.Count3, got.Count4} {
+				if v.(*big.Int).Cmp(big.NewInt(int64(tt.counts[i]))) != 0 {
+					t.Errorf("count %d = %v, want %d", i+1, v, tt.counts[i])
+				}
+			}
+		})
+	}
+
+	// the aggregator proves over the counts the chunks proved: 93 in total
+	got, err := BuildAggregatorWitness(publics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for minCorrect, ok := range map[int]bool{93: true, 94: false} {
+		if err := solved(t, compiled(t, NewAggregatorCircuit(minCorrect)), &got); ok != (err == nil) {
+			t.Errorf("MinCorrect %d: %v, want satisfied = %v", minCorrect, err, ok)
+		}
+	}
+}
+
+func TestChunkCacheRejectsTamperedProof(t *testing.T) {
+	keys := chunkKeys(t)
+	marks, labels := testDataset()
+	dir := t.TempDir()
+	cache := NewChunkCache(dir)
+	var results [2]ChunkResult
+	for c := range results {
+		var err error
+		if results[c], _, err = cache.prove(keys, testModel.w, testModel.b, marks[c], labels[c]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// chunk 1 has the borderline marks 20 and counts 24; chunk 2 counts 25
+	if results[0].Count != 24 || results[1].Count != 25 {
+		t.Fatalf("counts %d and %d, want 24 and 25", results[0].Count, results[1].Count)
+	}
+	if err := keys.Verify(results[1].Proof, results[0].Public); !errors.Is(err, ErrVerify) {
+		t.Fatalf("chunk 2's proof against chunk 1: %v, want ErrVerify", err)
+	}
+
+	// entry returns chunk 2's proof stored under chunk 1's key, claiming count
+	entry := func(count uint64) []byte {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, count)
+		if _, err := results[1].Proof.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	honest, err := os.ReadFile(cache.path(results[0].Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		entry  []byte
+		cached bool
+	}{
+		{"honest entry", honest, true},
+		{"honest proof, inflated count", append(binary.LittleEndian.AppendUint64(nil, 25), honest[8:]...), true},
+		{"other chunk's proof", entry(24), false},
+		{"other chunk's proof and count", entry(25), false},
+		{"truncated", honest[:len(honest)/2], false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(cache.path(results[0].Key), tt.entry, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			r, cached, err := NewChunkCache(dir).prove(keys, testModel.w, testModel.b, marks[0], labels[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cached != tt.cached {
+				t.Errorf("cached = %v, want %v", cached, tt.cached)
+			}
+			// whatever the entry claims, the count is the proved one
+			if r.Count != 24 {
+				t.Errorf("count %d, want 24", r.Count)
+			}
+			if err := keys.Verify(r.Proof, r.Public); err != nil {
+				t.Errorf("returned proof: %v", err)
+			}
+		})
+	}
+}
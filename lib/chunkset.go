@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/witness"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ChunkSet is an append-only evaluation log split into ChunkSize chunks at
+// absolute sample positions, recording which chunks need proving. Appending
+// samples only touches the last, partially filled chunk and new ones, so the
+// earlier chunks keep their proofs.
+type ChunkSet struct {
+	Chunks []Chunk
+}
+
+// Chunk is one chunk of a ChunkSet.
+type Chunk struct {
+	// Samples holds up to ChunkSize samples; only the last chunk of a set
+	// may hold fewer.
+	Samples []utils.Sample
+	// Result is the chunk's latest proof, or nil if it was never proved.
+	Result *ChunkResult
+	// Stale is set while Result does not cover Samples, i.e. the chunk needs
+	// (re-)proving once it is full.
+	Stale bool
+}
+
+// Full reports whether the chunk has ChunkSize samples and so can be proved.
+func (c Chunk) Full() bool {
+	return len(c.Samples) == ChunkSize
+}
+
+// AppendSamples returns existing with samples appended: they fill the last
+// chunk if it is partial, then new chunks, each of which is marked stale.
+// existing is not modified.
+func AppendSamples(existing ChunkSet, samples []utils.Sample) (ChunkSet, error) {
+	for i, c := range existing.Chunks {
+		if len(c.Samples) > ChunkSize || (len(c.Samples) < ChunkSize && i != len(existing.Chunks)-1) {
+			return ChunkSet{}, fmt.Errorf("chunk %d has %d samples; only the last chunk may hold fewer than %d", i+1, len(c.Samples), ChunkSize)
+		}
+	}
+
+	set := ChunkSet{Chunks: append([]Chunk(nil), existing.Chunks...)}
+	for len(samples) > 0 {
+		last := len(set.Chunks) - 1
+		if last < 0 || set.Chunks[last].Full() {
+			set.Chunks = append(set.Chunks, Chunk{})
+			last++
+		}
+		c := &set.Chunks[last]
+		n := min(ChunkSize-len(c.Samples), len(samples))
+		// copy so existing's chunk keeps its own backing array
+		c.Samples = append(append([]utils.Sample(nil), c.Samples...), samples[:n]...)
+		c.Stale = true
+		samples = samples[n:]
+	}
+	return set, nil
+}
+
+// NeedsProving returns the indices of the full chunks that are stale.
+func (s ChunkSet) NeedsProving() []int {
+	var stale []int
+	for i, c := range s.Chunks {
+		if c.Stale && c.Full() {
+			stale = append(stale, i)
+		}
+	}
+	return stale
+}
+
+// ProveStale proves every chunk NeedsProving returns through cache, with
+// keys for the chunk circuit, and returns how many it proved, also on
+// error. Chunks that
+// are not stale keep their proofs; a partial last chunk stays stale until
+// enough samples are appended to fill it.
+func (s *ChunkSet) ProveStale(cache *ChunkCache, keys *CircuitKeys, w, b float64) (int, error) {
+	proved := 0
+	for _, i := range s.NeedsProving() {
+		c := &s.Chunks[i]
+		marks := make([]float64, len(c.Samples))
+		labels := make([]int, len(c.Samples))
+		for j, sample := range c.Samples {
+			marks[j], labels[j] = sample.Marks, sample.Label
+		}
+		r, _, err := cache.prove(keys, w, b, marks, labels)
+		if err != nil {
+			return proved, fmt.Errorf("chunk %d: %w", i+1, err)
+		}
+		c.Result = &r
+		c.Stale = false
+		proved++
+	}
+	return proved, nil
+}
+
+// Latest returns the public witnesses of the last n full chunks, in order,
+// e.g. for BuildAggregatorWitness to recombine cached and newly proven
+// counts. Every one of them must be proved and not stale.
+func (s ChunkSet) Latest(n int) ([]witness.Witness, error) {
+	full := len(s.Chunks)
+	if full > 0 && !s.Chunks[full-1].Full() {
+		full--
+	}
+	if n > full {
+		return nil, fmt.Errorf("%w: want %d full chunks, have %d", ErrWitness, n, full)
+	}
+	publics := make([]witness.Witness, n)
+	for k := range publics {
+		i := full - n + k
+		c := s.Chunks[i]
+		if c.Stale || c.Result == nil {
+			return nil, fmt.Errorf("%w: chunk %d is not proved", ErrWitness, i+1)
+		}
+		publics[k] = c.Result.Public
+	}
+	return publics, nil
+}
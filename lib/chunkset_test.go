@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// samplesFrom returns n samples with marks from, from+1, ..., labelled by
+// testModel.
+func samplesFrom(from, n int) []utils.Sample {
+	samples := make([]utils.Sample, n)
+	for i := range samples {
+		x := float64(from + i)
+		samples[i] = utils.Sample{Marks: x, Label: utils.PredictQuantized(testModel.w, testModel.b, x)}
+	}
+	return samples
+}
+
+func TestAppendSamples(t *testing.T) {
+	proved := &ChunkResult{Key: "proved"}
+	full := Chunk{Samples: samplesFrom(0, ChunkSize), Result: proved}
+	partial := Chunk{Samples: samplesFrom(ChunkSize, 20), Result: proved}
+
+	tests := []struct {
+		name     string
+		existing []Chunk
+		appended int
+		sizes    []int
+		stale    []bool
+		needs    []int
+	}{
+		{"into an empty set", nil, 10, []int{10}, []bool{true}, nil},
+		{"across new chunks", nil, 60, []int{25, 25, 10}, []bool{true, true, true}, []int{0, 1}},
+		{"after a full chunk", []Chunk{full}, 25, []int{25, 25}, []bool{false, true}, []int{1}},
+		{"filling the partial chunk", []Chunk{full, partial}, 5, []int{25, 25}, []bool{false, true}, []int{1}},
+		{"overflowing into a new chunk", []Chunk{full, partial}, 10, []int{25, 25, 5}, []bool{false, true, true}, []int{1}},
+		{"nothing", []Chunk{full, partial}, 0, []int{25, 20}, []bool{false, false}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := ChunkSet{Chunks: slices.Clone(tt.existing)}
+			next := 0
+			for _, c := range existing.Chunks {
+				next += len(c.Samples)
+			}
+			appended := samplesFrom(next, tt.appended)
+			set, err := AppendSamples(existing, appended)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var sizes []int
+			var stale []bool
+			var all []utils.Sample
+			for _, c := range set.Chunks {
+				sizes, stale = append(sizes, len(c.Samples)), append(stale, c.Stale)
+				all = append(all, c.Samples...)
+			}
+			if !slices.Equal(sizes, tt.sizes) || !slices.Equal(stale, tt.stale) {
+				t.Errorf("chunk sizes %v, stale %v; want %v, %v", sizes, stale, tt.sizes, tt.stale)
+			}
+			if want := samplesFrom(0, next+tt.appended); !slices.Equal(all, want) {
+				t.Errorf("samples %v, want %v in order", all, want)
+			}
+			if got := set.NeedsProving(); !slices.Equal(got, tt.needs) {
+				t.Errorf("NeedsProving = %v, want %v", got, tt.needs)
+			}
+			if len(set.Chunks) > 0 && len(tt.existing) > 0 && set.Chunks[0].Result != proved {
+				t.Error("the full chunk lost its proof")
+			}
+			for i, c := range existing.Chunks {
+				if !slices.Equal(c.Samples, tt.existing[i].Samples) || c.Stale != tt.existing[i].Stale {
+					t.Errorf("existing chunk %d was modified", i)
+				}
+			}
+		})
+	}
+
+	invalid := []struct {
+		name   string
+		chunks []Chunk
+	}{
+		{"partial chunk before the last", []Chunk{partial, full}},
+		{"over-full chunk", []Chunk{{Samples: samplesFrom(0, ChunkSize+1)}}},
+	}
+	for _, tt := range invalid {
+		if _, err := AppendSamples(ChunkSet{Chunks: tt.chunks}, samplesFrom(0, 1)); err == nil {
+			t.Errorf("%s: appended, want an error", tt.name)
+		}
+	}
+}
+
+func TestChunkSetProveStale(t *testing.T) {
+	keys := chunkKeys(t)
+	cache := NewChunkCache(t.TempDir())
+
+	set, err := AppendSamples(ChunkSet{}, samplesFrom(0, ChunkSize+10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proved, err := set.ProveStale(cache, keys, testModel.w, testModel.b); err != nil || proved != 1 {
+		t.Fatalf("proved %d chunks (%v), want the full one", proved, err)
+	}
+	if _, err := set.Latest(2); !errors.Is(err, ErrWitness) {
+		t.Errorf("Latest(2) with one full chunk: err = %v, want ErrWitness", err)
+	}
+	first := set.Chunks[0].Result
+
+	// 20 more samples fill the second chunk and overflow into a third
+	if set, err = AppendSamples(set, samplesFrom(ChunkSize+10, 20)); err != nil {
+		t.Fatal(err)
+	}
+	if got := set.NeedsProving(); !slices.Equal(got, []int{1}) {
+		t.Fatalf("NeedsProving = %v, want only the refilled chunk", got)
+	}
+	if _, err := set.Latest(2); !errors.Is(err, ErrWitness) {
+		t.Errorf("Latest(2) before re-proving: err = %v, want ErrWitness", err)
+	}
+	if proved, err := set.ProveStale(cache, keys, testModel.w, testModel.b); err != nil || proved != 1 {
+		t.Fatalf("proved %d chunks (%v), want only the refilled one", proved, err)
+	}
+	if set.Chunks[0].Result != first {
+		t.Error("the unchanged first chunk was proved again")
+	}
+	if !set.Chunks[2].Stale || set.Chunks[2].Result != nil {
+		t.Error("the partial third chunk was proved")
+	}
+
+	publics, err := set.Latest(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chunk 1 holds the borderline marks 20, which does not count
+	for i, want := range []int{ChunkSize - 1, ChunkSize} {
+		if got := set.Chunks[i].Result.Count; got != want {
+			t.Errorf("chunk %d count %d, want %d", i+1, got, want)
+		}
+		if err := keys.Verify(set.Chunks[i].Result.Proof, publics[i]); err != nil {
+			t.Errorf("chunk %d: %v", i+1, err)
+		}
+	}
+}
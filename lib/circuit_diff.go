@@ -0,0 +1,112 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// CircuitShape is what CompareCircuits records about one circuit, compiled
+// for PLONK on DefaultCurve.
+type CircuitShape struct {
+	NbConstraints       int
+	NbPublicVariables   int
+	NbSecretVariables   int
+	NbInternalVariables int
+	// ConfigHash is ConfigHash(circuit), which its caches are keyed by.
+	ConfigHash string
+	// CCSHash is a SHA-256 of the serialized constraint system, so it
+	// differs whenever any constraint does, even at the same counts.
+	CCSHash string
+	// VKFingerprint is of a setup against unsafekzg's SRS.
+	VKFingerprint string
+}
+
+// CircuitDiff reports how two circuits differ.
+type CircuitDiff struct {
+	A, B CircuitShape
+	// Differences describes each differing field of A and B, in
+	// CircuitShape order, e.g. "constraints: 1154 -> 1160"; it is empty
+	// when the circuits compile identically under the same config.
+	Differences []string
+	// InvalidatesVK is set when B's verifying key differs from A's, so
+	// proofs made for one do not verify under the other.
+	InvalidatesVK bool
+	// InvalidatesCaches is set when caches written for A are stale for B,
+	// i.e. their ConfigHash differs, so they are set up again.
+	InvalidatesCaches bool
+	// NeedsRevision is set when the constraints differ but the ConfigHash
+	// does not: caches written for A would still load for B and prove
+	// against A's keys, so B needs a new revision (see revisioned).
+	NeedsRevision bool
+}
+
+// CompareCircuits compiles a and b, sets them up and reports the
+// differences, e.g. to confirm that refactoring a circuit left its
+// constraints unchanged. Pass fresh circuit values, not ones already
+// compiled. Setup makes it as slow as setting both circuits up.
+func CompareCircuits(a, b frontend.Circuit) (CircuitDiff, error) {
+	var d CircuitDiff
+	var err error
+	if d.A, err = circuitShape(a); err != nil {
+		return d, fmt.Errorf("first circuit: %w", err)
+	}
+	if d.B, err = circuitShape(b); err != nil {
+		return d, fmt.Errorf("second circuit: %w", err)
+	}
+
+	fields := []struct {
+		name string
+		a, b any
+	}{
+		{"constraints", d.A.NbConstraints, d.B.NbConstraints},
+		{"public variables", d.A.NbPublicVariables, d.B.NbPublicVariables},
+		{"secret variables", d.A.NbSecretVariables, d.B.NbSecretVariables},
+		{"internal variables", d.A.NbInternalVariables, d.B.NbInternalVariables},
+		{"config hash", shortHash(d.A.ConfigHash), shortHash(d.B.ConfigHash)},
+		{"constraint system hash", shortHash(d.A.CCSHash), shortHash(d.B.CCSHash)},
+		{"vk", d.A.VKFingerprint, d.B.VKFingerprint},
+	}
+	for _, f := range fields {
+		if f.a != f.b {
+			d.Differences = append(d.Differences, fmt.Sprintf("%s: %v -> %v", f.name, f.a, f.b))
+		}
+	}
+	d.InvalidatesVK = d.A.VKFingerprint != d.B.VKFingerprint
+	d.InvalidatesCaches = d.A.ConfigHash != d.B.ConfigHash
+	d.NeedsRevision = d.A.CCSHash != d.B.CCSHash && !d.InvalidatesCaches
+	return d, nil
+}
+
+func circuitShape(circuit frontend.Circuit) (CircuitShape, error) {
+	// ConfigHash first: compiling may fill in a circuit's unexported state
+	s := CircuitShape{ConfigHash: ConfigHash(circuit)}
+	ccs, err := Compile(BackendPlonk, DefaultCurve, circuit)
+	if err != nil {
+		return s, err
+	}
+	s.NbConstraints = ccs.GetNbConstraints()
+	s.NbPublicVariables = ccs.GetNbPublicVariables()
+	s.NbSecretVariables = ccs.GetNbSecretVariables()
+	s.NbInternalVariables = ccs.GetNbInternalVariables()
+
+	h := sha256.New()
+	if _, err := ccs.WriteTo(h); err != nil {
+		return s, fmt.Errorf("encoding constraint system: %w", err)
+	}
+	s.CCSHash = hex.EncodeToString(h.Sum(nil))
+
+	_, vk, err := setupCompiled(ccs)
+	if err != nil {
+		return s, err
+	}
+	s.VKFingerprint = VKFingerprint(vk)
+	return s, nil
+}
+
+// shortHash abbreviates a hex hash for a message, like the cache errors do.
+func shortHash(h string) string {
+	return h[:min(12, len(h))]
+}
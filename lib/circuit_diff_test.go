@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// variantCircuit adds a constraint when extra is set. The unexported field
+// is not in its ConfigHash, like an edit to Define.
+type variantCircuit struct {
+	X     frontend.Variable `gnark:",public"`
+	Y     frontend.Variable
+	extra bool
+}
+
+func (c *variantCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.Y, c.Y), c.X)
+	if c.extra {
+		api.AssertIsDifferent(c.Y, 0)
+	}
+	return nil
+}
+
+func TestCompareCircuits(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        frontend.Circuit
+		differences []string // prefixes of the reported differences
+		vk, caches  bool
+		revision    bool
+	}{
+		{"itself", &LinearCircuit{}, &LinearCircuit{}, nil, false, false, false},
+		{"another MinCorrect", &AggregatorCircuit{}, NewAggregatorCircuit(90), []string{"config hash", "constraint system hash", "vk"}, true, true, false},
+		{"another circuit", &LinearCircuit{}, &AggregatorCircuit{}, []string{"constraints", "public variables", "secret variables", "internal variables", "config hash", "constraint system hash", "vk"}, true, true, false},
+		{"edited Define", &variantCircuit{}, &variantCircuit{extra: true}, []string{"constraints", "internal variables", "constraint system hash", "vk"}, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := CompareCircuits(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, diff := range d.Differences {
+				got = append(got, diff[:strings.Index(diff, ":")])
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.differences, ", ") {
+				t.Errorf("differences %q, want %v", d.Differences, tt.differences)
+			}
+			if d.InvalidatesVK != tt.vk || d.InvalidatesCaches != tt.caches || d.NeedsRevision != tt.revision {
+				t.Errorf("invalidates vk %v, caches %v, needs revision %v; want %v, %v, %v",
+					d.InvalidatesVK, d.InvalidatesCaches, d.NeedsRevision, tt.vk, tt.caches, tt.revision)
+			}
+		})
+	}
+
+	if _, err := CompareCircuits(&failingCircuit{}, &LinearCircuit{}); err == nil || !strings.HasPrefix(err.Error(), "first circuit") {
+		t.Errorf("failing first circuit: err = %v", err)
+	}
+	if _, err := CompareCircuits(&LinearCircuit{}, &failingCircuit{}); err == nil || !strings.HasPrefix(err.Error(), "second circuit") {
+		t.Errorf("failing second circuit: err = %v", err)
+	}
+}
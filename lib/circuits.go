@@ -0,0 +1,547 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
+)
+
+// ============================================================================
+// CIRCUIT 1: Linear Regression Circuit (z = W*X + B)
+// ============================================================================
+
+type LinearCircuit struct {
+	W frontend.Variable
+	B frontend.Variable
+	X frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B), binding the proof to the
+	// model used in the other circuits.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// Arith computes z; nil means DefaultArith. Z must be assigned with the
+	// same arithmetic: LinearZ for the default, LinearZRounded for
+	// ArithFor(rounding).
+	Arith FixedArith `gnark:"-"`
+}
+
+func (circuit *LinearCircuit) Define(api frontend.API) error {
+	if err := assertModelCommitment(api, circuit.W, circuit.B, circuit.ModelCommitment); err != nil {
+		return err
+	}
+
+	arith := arithOrDefault(circuit.Arith)
+	wx := arith.Mul(api, circuit.W, circuit.X)
+	z := arith.Add(api, wx, circuit.B)
+
+	api.AssertIsEqual(z, circuit.Z)
+	return nil
+}
+
+// ============================================================================
+// CIRCUIT 2: Sigmoid Classification Circuit
+// ============================================================================
+
+// Sigmoid LUT configuration, from package fixedpoint
+const inputPrecision = fixedpoint.InputPrecision   // input Q10
+const outputPrecision = fixedpoint.OutputPrecision // output Q16
+const MaxInput = fixedpoint.MaxInput               // cover [-8, 8]
+const MaxMarks = fixedpoint.MaxMarks               // accuracy circuits' marks in [0, 100]
+const MarginSteps = 8       // margin in Q10 steps (~0.0078125) around 0
+
+// DefaultThreshold is the Q16 decision threshold (0.5) used when a circuit's
+// Threshold is left at zero.
+const DefaultThreshold = 1 << (outputPrecision - 1)
+
+type SigmoidCircuit struct {
+	Z     frontend.Variable `gnark:",public"`
+	Label frontend.Variable `gnark:",public"`
+
+	// Threshold is the Q16 sigmoid value at or above which the prediction is 1.
+	// It is compiled in as a constant, so each threshold yields its own
+	// verifying key and a proof only verifies under the policy it was built for.
+	// Zero means DefaultThreshold.
+	Threshold int64 `gnark:"-"`
+
+	// LUT optionally supplies precomputed table values for DefaultLUTConfig
+	// (see LoadSigmoidTable); nil computes them during compilation.
+	LUT []int64 `gnark:"-"`
+
+	// RejectOnSaturation makes a z with |z| beyond MaxInput unprovable
+	// instead of clamping it to the end of the LUT, guaranteeing the model
+	// operated within the table's domain. Like Threshold it is compiled in.
+	RejectOnSaturation bool `gnark:"-"`
+
+	// InterpolationSteps, if above 1, compiles a table with only every
+	// InterpolationSteps-th entry of DefaultLUTConfig and interpolates
+	// between them (see LUTConfig.InterpolationSteps); LUT must then be for
+	// that configuration. Like Threshold it is compiled in.
+	InterpolationSteps int `gnark:"-"`
+
+	// ExposeProbability makes the LUT's sigmoid(Z), in Q16 times
+	// InterpolationSteps, a public output in Probability, so a verifier
+	// learns the model's confidence and can apply its own threshold. Only
+	// set it where revealing the confidence is acceptable. Probability must
+	// then be set, when compiling as well as in assignments; see
+	// NewProbabilitySigmoidCircuit. Like Threshold it is compiled in.
+	ExposeProbability bool `gnark:"-"`
+	// Probability is nil unless ExposeProbability is set. (gnark skips a
+	// nil pointer silently, but reports every empty slice.)
+	Probability *SigmoidProbability `gnark:",public"`
+
+	table *sigmoidTable
+}
+
+// SigmoidProbability is the public Probability output of a SigmoidCircuit.
+type SigmoidProbability struct {
+	Value frontend.Variable `gnark:",public"`
+}
+
+// NewSigmoidCircuit returns a SigmoidCircuit deciding at the given
+// probability threshold, e.g. 0.7.
+func NewSigmoidCircuit(threshold float64) *SigmoidCircuit {
+	return &SigmoidCircuit{Threshold: ThresholdToQ16(threshold)}
+}
+
+// NewProbabilitySigmoidCircuit is NewSigmoidCircuit with ExposeProbability
+// set and room for the Probability output.
+func NewProbabilitySigmoidCircuit(threshold float64) *SigmoidCircuit {
+	c := NewSigmoidCircuit(threshold)
+	c.ExposeProbability = true
+	c.Probability = &SigmoidProbability{}
+	return c
+}
+
+// ThresholdToQ16 converts a probability threshold in (0, 1) to the LUT
+// output domain.
+func ThresholdToQ16(threshold float64) int64 {
+	return int64(math.Round(threshold * float64(1<<outputPrecision)))
+}
+
+func thresholdOrDefault(threshold int64) int64 {
+	if threshold == 0 {
+		return DefaultThreshold
+	}
+	return threshold
+}
+
+// revision 2 asserts the prediction is boolean.
+func (circuit *SigmoidCircuit) revision() int { return 2 }
+
+func (circuit *SigmoidCircuit) Define(api frontend.API) error {
+	// Build LUT once (compiled into the circuit; values may come from the on-disk LUT cache)
+	if circuit.table == nil {
+		cfg := DefaultLUTConfig()
+		cfg.InterpolationSteps = circuit.InterpolationSteps
+		table, err := newSigmoidTableFor(api, cfg, circuit.LUT)
+		if err != nil {
+			return err
+		}
+		circuit.table = table
+	}
+
+	if circuit.ExposeProbability != (circuit.Probability != nil) {
+		return fmt.Errorf("sigmoid circuit: Probability must be set exactly when ExposeProbability is")
+	}
+	sigmoid, isSat := sigmoidValue(api, circuit.table, circuit.Z)
+	if circuit.ExposeProbability {
+		api.AssertIsEqual(sigmoid, circuit.Probability.Value)
+	}
+	prediction := sigmoidThreshold(api, circuit.table, sigmoid, thresholdOrDefault(circuit.Threshold))
+	// Defensive: a non-boolean prediction would compare against a
+	// malformed label instead of failing
+	api.AssertIsBoolean(prediction)
+	if circuit.RejectOnSaturation {
+		api.AssertIsEqual(isSat, 0)
+	}
+
+	// Enforce match with dataset label
+	api.AssertIsEqual(prediction, circuit.Label)
+	return nil
+}
+
+// sigmoidTable is a sigmoid LUT compiled into a circuit, holding every
+// steps-th Q10 index.
+type sigmoidTable struct {
+	lookup *logderivlookup.Table
+	steps  int
+}
+
+// newSigmoidTable builds the sigmoid LUT over [0, MaxInput] in Q10 -> Q16,
+// from values if given or computed from DefaultLUTConfig otherwise.
+func newSigmoidTable(api frontend.API, values []int64) (*sigmoidTable, error) {
+	return newSigmoidTableFor(api, DefaultLUTConfig(), values)
+}
+
+// newSigmoidTableFor is newSigmoidTable for cfg, which may only differ from
+// DefaultLUTConfig in InterpolationSteps.
+func newSigmoidTableFor(api frontend.API, cfg LUTConfig, values []int64) (*sigmoidTable, error) {
+	if err := cfg.checkInterpolation(); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = ComputeSigmoidTable(cfg)
+	}
+	if len(values) != cfg.Size() {
+		return nil, fmt.Errorf("sigmoid LUT has %d entries, circuit expects %d", len(values), cfg.Size())
+	}
+
+	table := logderivlookup.New(api)
+	for _, v := range values {
+		table.Insert(v)
+	}
+	if cfg.steps() > 1 {
+		// the last entry's upper neighbour, read with a zero weight
+		table.Insert(values[len(values)-1])
+	}
+	return &sigmoidTable{lookup: table, steps: cfg.steps()}, nil
+}
+
+func sigmoidPredict(api frontend.API, table *sigmoidTable, z frontend.Variable, threshold int64) (prediction, isSat frontend.Variable) {
+	sigmoid, isSat := sigmoidValue(api, table, z)
+	return sigmoidThreshold(api, table, sigmoid, threshold), isSat
+}
+
+// sigmoidThreshold returns 1 if a sigmoidValue is at or above the Q16
+// threshold and 0 otherwise.
+func sigmoidThreshold(api frontend.API, table *sigmoidTable, sigmoid frontend.Variable, threshold int64) frontend.Variable {
+	// Threshold (0.5 -> 32768 by default), scaled like sigmoid
+	cmpThresh := api.Cmp(sigmoid, threshold*int64(table.steps))
+	isLess := api.IsZero(api.Add(cmpThresh, 1)) // 1 if <
+	return api.Sub(1, isLess)                   // 1 if >=, else 0
+}
+
+// sigmoidValue returns the LUT's sigmoid of a Q32 z in Q16, times
+// table.steps, and whether |z| was clamped to the end of the table.
+func sigmoidValue(api frontend.API, table *sigmoidTable, z frontend.Variable) (sigmoid, isSat frontend.Variable) {
+	// Rescale Z from Q32 to Q10 for lookup domain (floor division)
+	zIn := RescaleVar(api, z, Precision, inputPrecision)
+
+	// Constants
+	oneOut := big.NewInt(1 << outputPrecision)               // 65536
+	maxTableIndex := big.NewInt(MaxInput << inputPrecision)   // 8192
+
+	// Signed handling via field midpoint: only zIn > FieldMidpoint is
+	// negative, so z == 0 reads sigmoid(0) = 0.5 exactly and predicts 1 at
+	// the default threshold, and any z < 0 floors to zIn <= -1 and predicts 0
+	isNeg := isNegative(api, zIn)
+
+	// |z|
+	absZ := api.Select(isNeg, api.Neg(zIn), zIn)
+
+	// Saturation to LUT domain
+	cmpMax := api.Cmp(absZ, maxTableIndex)
+	isSat = api.IsZero(api.Sub(1, cmpMax)) // 1 if absZ > max
+	clamped := api.Select(isSat, maxTableIndex, absZ)
+
+	// Lookup(sigmoid(|z|))
+	if table.steps > 1 {
+		return sigmoidInterpolated(api, table, clamped, isNeg), isSat
+	}
+	lut := table.lookup.Lookup(clamped)[0]
+	// Symmetry sigmoid(-x) = 1 - sigmoid(x)
+	return api.Select(isNeg, api.Sub(oneOut, lut), lut), isSat
+}
+
+// sigmoidInterpolated is sigmoidValue for a table with steps > 1:
+// sigmoid(|z|) is interpolated linearly between the entries either side of
+// the clamped Q10 |z|. Everything is kept scaled by steps, so there is no
+// division and no rounding beyond the table's own.
+func sigmoidInterpolated(api frontend.API, table *sigmoidTable, clamped, isNeg frontend.Variable) frontend.Variable {
+	k := bits.Len(uint(table.steps)) - 1
+	indexBits := bits.Len(uint(MaxInput << inputPrecision))
+	b := api.ToBinary(clamped, indexBits)
+	frac := api.FromBinary(b[:k]...)
+	idx := api.FromBinary(b[k:]...)
+
+	entries := table.lookup.Lookup(idx, api.Add(idx, 1))
+	lo, hi := entries[0], entries[1]
+	interp := api.Add(api.Mul(lo, table.steps), api.Mul(api.Sub(hi, lo), frac))
+
+	oneOut := big.NewInt(int64(table.steps) << outputPrecision)
+	return api.Select(isNeg, api.Sub(oneOut, interp), interp)
+}
+
+// ============================================================================
+// CIRCUIT 2B: Combined Inference Circuit (z = W*X + B, then sigmoid LUT)
+// Proves a single prediction end-to-end; Z never leaves the circuit, so there
+// is no unbound public value to link between two separate proofs.
+// ============================================================================
+
+type InferenceCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     frontend.Variable `gnark:",public"`
+	Label frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B); see LinearCircuit.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// Threshold is the compiled-in Q16 decision threshold; see SigmoidCircuit.
+	Threshold int64 `gnark:"-"`
+	// LUT optionally supplies precomputed table values; see SigmoidCircuit.
+	LUT []int64 `gnark:"-"`
+	// Arith computes z; nil means DefaultArith. The LUT lookup expects z in
+	// Q32, so Arith must have Precision 32.
+	Arith FixedArith `gnark:"-"`
+
+	table *sigmoidTable
+}
+
+func (circuit *InferenceCircuit) Define(api frontend.API) error {
+	if circuit.table == nil {
+		table, err := newSigmoidTable(api, circuit.LUT)
+		if err != nil {
+			return err
+		}
+		circuit.table = table
+	}
+	if err := assertModelCommitment(api, circuit.W, circuit.B, circuit.ModelCommitment); err != nil {
+		return err
+	}
+
+	arith := arithOrDefault(circuit.Arith)
+	z := arith.Add(api, arith.Mul(api, circuit.W, circuit.X), circuit.B)
+
+	prediction, _ := sigmoidPredict(api, circuit.table, z, thresholdOrDefault(circuit.Threshold))
+	api.AssertIsEqual(prediction, circuit.Label)
+	return nil
+}
+
+// ============================================================================
+// CIRCUIT 3A: Accuracy Chunk Circuit (25 samples)
+// Counts correct predictions for a chunk of samples.
+// ============================================================================
+
+const ChunkSize = 25
+
+type AccuracyChunkCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     [ChunkSize]frontend.Variable `gnark:",public"`
+	Label [ChunkSize]frontend.Variable `gnark:",public"`
+
+	// Count is the number of eligible, correct predictions in the chunk,
+	// exposed so the aggregator can bind to a proven value.
+	Count frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B); see LinearCircuit.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// IncludeBorderline counts every sample, dropping the margin check (and
+	// its constraints). The zero value keeps the original behaviour of
+	// skipping samples with |z| below MarginSteps in Q10.
+	IncludeBorderline bool `gnark:"-"`
+}
+
+// NewAccuracyChunkCircuit returns an AccuracyChunkCircuit that skips
+// borderline samples if excludeBorderline is set (the default behaviour).
+func NewAccuracyChunkCircuit(excludeBorderline bool) *AccuracyChunkCircuit {
+	return &AccuracyChunkCircuit{IncludeBorderline: !excludeBorderline}
+}
+
+// revision 2 asserts each prediction is boolean (see countCorrect);
+// revision 3 bounds each mark.
+func (c *AccuracyChunkCircuit) revision() int { return 3 }
+
+func (c *AccuracyChunkCircuit) Define(api frontend.API) error {
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+	sumCorrect := countCorrect(api, c.W, c.B, c.X[:], c.Label[:], !c.IncludeBorderline)
+
+	// No threshold here - just output the count
+	// The aggregator will enforce the global threshold
+	api.AssertIsEqual(sumCorrect, c.Count)
+	return nil
+}
+
+// countCorrect returns the number of samples whose prediction sign(W*x+B)
+// matches the label, skipping borderline samples with |z| below MarginSteps
+// in Q10 if excludeBorderline is set.
+func countCorrect(api frontend.API, wVar, bVar frontend.Variable, xs, labels []frontend.Variable, excludeBorderline bool) frontend.Variable {
+	w := New(api, wVar)
+	b := New(api, bVar)
+
+	margin := big.NewInt(MarginSteps)
+
+	sumCorrect := frontend.Variable(0)
+
+	for i := range xs {
+		// a mark is only eligible in [0, MaxMarks]; see assertMark
+		assertMark(api, xs[i])
+		x := New(api, xs[i])
+		z := w.Mul(x).Add(b)
+
+		// z == 0 is not negative and predicts 1, as in sigmoidPredict
+		isNeg := isNegative(api, z.Val)
+		prediction := api.Sub(1, isNeg)
+		api.AssertIsBoolean(prediction) // defensive, as in SigmoidCircuit
+
+		diff := api.Sub(prediction, labels[i])
+		equal := api.IsZero(diff)
+
+		if !excludeBorderline {
+			sumCorrect = api.Add(sumCorrect, equal)
+			continue
+		}
+
+		// floor division preserves sign, so zIn reuses isNeg
+		zIn := RescaleVar(api, z.Val, Precision, inputPrecision)
+		absZIn := api.Select(isNeg, api.Neg(zIn), zIn)
+		// divFloorPow2 bounds |zIn| below 2^(quotientBits-1), so the
+		// margin check need not compare over the whole field
+		isLessMargin := isLessBounded(api, absZIn, margin, quotientBits)
+		eligible := api.Sub(1, isLessMargin)
+
+		sumCorrect = api.Add(sumCorrect, api.Mul(eligible, equal))
+	}
+	return sumCorrect
+}
+
+// ============================================================================
+// CIRCUIT 3B: Aggregator Circuit
+// Takes counts from 4 chunks, each in [0, ChunkSize], and asserts
+// total >= MinCorrect (default 97)
+// ============================================================================
+
+// DefaultMinCorrect is the minimum number of correct predictions out of 100
+// (97%) used when a circuit's MinCorrect is left at zero.
+const DefaultMinCorrect = 97
+
+type AggregatorCircuit struct {
+	Count1 frontend.Variable `gnark:",public"`
+	Count2 frontend.Variable `gnark:",public"`
+	Count3 frontend.Variable `gnark:",public"`
+	Count4 frontend.Variable `gnark:",public"`
+
+	// MinCorrect is the compiled-in minimum total; like SigmoidCircuit's
+	// Threshold, each value yields its own verifying key. Zero means
+	// DefaultMinCorrect.
+	MinCorrect int `gnark:"-"`
+}
+
+// NewAggregatorCircuit returns an AggregatorCircuit asserting at least
+// minCorrect correct predictions across its chunks.
+func NewAggregatorCircuit(minCorrect int) *AggregatorCircuit {
+	return &AggregatorCircuit{MinCorrect: minCorrect}
+}
+
+// MinCorrectFor converts an accuracy policy in (0, 1] to the minimum number
+// of correct predictions out of n, rounding up so the policy is never
+// weakened.
+func MinCorrectFor(minAccuracy float64, n int) (int, error) {
+	if !(minAccuracy > 0 && minAccuracy <= 1) {
+		return 0, fmt.Errorf("minimum accuracy %v is not in (0, 1]", minAccuracy)
+	}
+	// the epsilon keeps e.g. 0.97*100 = 97.00000000000001 at 97
+	return int(math.Ceil(minAccuracy*float64(n) - 1e-9)), nil
+}
+
+func minCorrectOrDefault(minCorrect int) int {
+	if minCorrect == 0 {
+		return DefaultMinCorrect
+	}
+	return minCorrect
+}
+
+// revision 2 bounds every count; revision 3 compares the total with
+// isLessBounded.
+func (c *AggregatorCircuit) revision() int { return 3 }
+
+func (c *AggregatorCircuit) Define(api frontend.API) error {
+	// Each count must be a chunk's: in [0, ChunkSize]. Without this bound a
+	// single inflated count would meet any MinCorrect, or a "negative" one
+	// near the field modulus could wrap the total.
+	countBits := bits.Len(ChunkSize)
+	for _, count := range []frontend.Variable{c.Count1, c.Count2, c.Count3, c.Count4} {
+		api.ToBinary(count, countBits)
+		api.AssertIsEqual(isLessBounded(api, count, big.NewInt(ChunkSize+1), countBits), 1)
+	}
+
+	totalCorrect := api.Add(c.Count1, c.Count2)
+	totalCorrect = api.Add(totalCorrect, c.Count3)
+	totalCorrect = api.Add(totalCorrect, c.Count4)
+
+	// the counts bound the total to [0, numChunks*ChunkSize]
+	minCorrect := minCorrectOrDefault(c.MinCorrect)
+	if err := assertAtLeast(api, totalCorrect, minCorrect, numChunks*ChunkSize); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ============================================================================
+// CIRCUIT 3 (Legacy): Full Accuracy Circuit - kept for reference
+// ============================================================================
+
+const NumSamples = 100
+
+type AccuracyCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     [NumSamples]frontend.Variable `gnark:",public"`
+	Label [NumSamples]frontend.Variable `gnark:",public"`
+
+	// MinCorrect is the compiled-in minimum; see AggregatorCircuit.
+	MinCorrect int `gnark:"-"`
+	// IncludeBorderline disables the margin check; see AccuracyChunkCircuit.
+	IncludeBorderline bool `gnark:"-"`
+}
+
+// revision 2 asserts each prediction is boolean; revision 3 bounds each
+// mark and compares with isLessBounded.
+func (c *AccuracyCircuit) revision() int { return 3 }
+
+func (c *AccuracyCircuit) Define(api frontend.API) error {
+	w := New(api, c.W)
+	b := New(api, c.B)
+
+	margin := big.NewInt(MarginSteps)
+
+	// count correct predictions
+	sumCorrect := frontend.Variable(0)
+
+	for i := 0; i < NumSamples; i++ {
+		assertMark(api, c.X[i])
+		x := New(api, c.X[i])
+		// z = w*x + b  (fixed-point scaling inside Mul/Add)
+		z := w.Mul(x).Add(b)
+
+		// prediction = 1 if z >= 0 else 0
+		isNeg := isNegative(api, z.Val) // 1 if z > FieldMidpoint
+		prediction := api.Sub(1, isNeg)
+		api.AssertIsBoolean(prediction) // defensive, as in SigmoidCircuit
+
+		// eligibility: exclude borderline samples near 0 in Q10 domain
+		// zIn = floor(z / 2^(Precision-inputPrecision)) (Q10). Compute |zIn| >= MarginSteps ? 1 : 0
+		// floor division preserves sign, so zIn shares isNeg
+		eligible := frontend.Variable(1)
+		if !c.IncludeBorderline {
+			zIn := RescaleVar(api, z.Val, Precision, inputPrecision)
+			absZIn := api.Select(isNeg, api.Neg(zIn), zIn)
+			// |zIn| < 2^(quotientBits-1); see countCorrect
+			isLessMargin := isLessBounded(api, absZIn, margin, quotientBits) // 1 if absZIn < margin
+			eligible = api.Sub(1, isLessMargin)                              // 1 if >= margin, else 0
+		}
+
+		// equal = 1 if prediction == Label[i] else 0
+		diff := api.Sub(prediction, c.Label[i])
+		equal := api.IsZero(diff)
+
+		// count only eligible & correct
+		sumCorrect = api.Add(sumCorrect, api.Mul(eligible, equal))
+	}
+
+	// enforce sumCorrect >= minCorrect (97% of NumSamples by default)
+	minCorrect := minCorrectOrDefault(c.MinCorrect)
+	return assertAtLeast(api, sumCorrect, minCorrect, NumSamples)
+}
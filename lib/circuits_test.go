@@ -0,0 +1,332 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/constraint"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// inferenceAssignment is the InferenceCircuit assignment for testModel at
+// marks x claiming label.
+func inferenceAssignment(x float64, label int) *InferenceCircuit {
+	return &InferenceCircuit{
+		W:               NewScaled(testModel.w),
+		B:               NewScaled(testModel.b),
+		X:               NewScaled(x),
+		Label:           label,
+		ModelCommitment: testCommitment(),
+	}
+}
+
+func TestInferenceCircuitAgreesWithPredict(t *testing.T) {
+	ccs := compiled(t, &InferenceCircuit{})
+	for _, x := range []float64{0, 5, 10, 15, 19.5, 20, 20.5, 25, 50, 75, 100} {
+		want := utils.PredictClass(testModel.w, testModel.b, x)
+		if err := solved(t, ccs, inferenceAssignment(x, want)); err != nil {
+			t.Errorf("marks %v: label %d rejected: %v", x, want, err)
+		}
+		if err := solved(t, ccs, inferenceAssignment(x, 1-want)); err == nil {
+			t.Errorf("marks %v: label %d accepted", x, 1-want)
+		}
+	}
+}
+
+func TestSigmoidCircuitThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		z         float64 // sigmoid(0.8473) = 0.7
+		want      int
+	}{
+		{"default below 0.7", 0.5, 0.8, 1},
+		{"default above 0.7", 0.5, 0.9, 1},
+		{"0.7 below", 0.7, 0.8, 0},
+		{"0.7 above", 0.7, 0.9, 1},
+		{"0.7 negative z", 0.7, -0.9, 0},
+	}
+	circuits := map[float64]constraint.ConstraintSystem{
+		0.5: compiled(t, &SigmoidCircuit{}),
+		0.7: compiled(t, NewSigmoidCircuit(0.7)),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ccs := circuits[tt.threshold]
+			if err := solved(t, ccs, &SigmoidCircuit{Z: NewScaled(tt.z), Label: tt.want}); err != nil {
+				t.Errorf("label %d rejected: %v", tt.want, err)
+			}
+			if err := solved(t, ccs, &SigmoidCircuit{Z: NewScaled(tt.z), Label: 1 - tt.want}); err == nil {
+				t.Errorf("label %d accepted", 1-tt.want)
+			}
+		})
+	}
+
+	if got := NewSigmoidCircuit(0.7).Threshold; got != ThresholdToQ16(0.7) || got != 45875 {
+		t.Errorf("NewSigmoidCircuit(0.7).Threshold = %d, want 45875", got)
+	}
+}
+
+func TestSigmoidCircuitAgreesWithPredictQuantized(t *testing.T) {
+	ccs := compiled(t, &SigmoidCircuit{})
+	tests := []struct {
+		name     string
+		w, b     float64
+		boundary float64 // marks at which z = 0
+	}{
+		{"test model", testModel.w, testModel.b, 20},
+		{"bundled model", -0.85735312, 50.94705066, 59.4239},
+		{"borderline", 0.0001, -0.005, 50}, // |z| below one Q10 step
+		{"saturated", 2, -100, 50},         // |z| past the LUT's MaxInput
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wScaled, bScaled := NewScaled(tt.w), NewScaled(tt.b)
+			marks := []float64{tt.boundary - 0.01, tt.boundary, tt.boundary + 0.01}
+			for x := 0.0; x <= MaxMarks; x += 5 {
+				marks = append(marks, x)
+			}
+			for _, x := range marks {
+				z := LinearZ(wScaled, bScaled, x)
+				want := utils.PredictQuantized(tt.w, tt.b, x)
+				if err := solved(t, ccs, &SigmoidCircuit{Z: z, Label: want}); err != nil {
+					t.Fatalf("marks %v: PredictQuantized = %d rejected: %v", x, want, err)
+				}
+				if err := solved(t, ccs, &SigmoidCircuit{Z: z, Label: 1 - want}); err == nil {
+					t.Fatalf("marks %v: label %d accepted", x, 1-want)
+				}
+			}
+		})
+	}
+}
+
+func TestChunkCircuitBorderline(t *testing.T) {
+	samples := testChunk()
+	// z = 0 and z = -0.005, both correctly labelled but within MarginSteps
+	samples[4] = utils.Sample{Marks: 20, Label: utils.PredictQuantized(testModel.w, testModel.b, 20)}
+	samples[5] = utils.Sample{Marks: 20.01, Label: utils.PredictQuantized(testModel.w, testModel.b, 20.01)}
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+	xs := QuantizeDataset(samples)
+	labels := make([]int, ChunkSize)
+	for i, s := range samples {
+		labels[i] = s.Label
+	}
+	assignment := func(count int) *AccuracyChunkCircuit {
+		a := &AccuracyChunkCircuit{W: wScaled, B: bScaled, Count: count, ModelCommitment: testCommitment()}
+		for i := range xs {
+			a.X[i], a.Label[i] = xs[i], labels[i]
+		}
+		return a
+	}
+
+	tests := []struct {
+		name              string
+		excludeBorderline bool
+		count             int
+	}{
+		{"excluded", true, ChunkSize - 2},
+		{"included", false, ChunkSize},
+	}
+	sizes := map[bool]int{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkCount(wScaled, bScaled, xs, labels, !tt.excludeBorderline); got != tt.count {
+				t.Errorf("chunkCount = %d, want %d", got, tt.count)
+			}
+			ccs := compiled(t, NewAccuracyChunkCircuit(tt.excludeBorderline))
+			sizes[tt.excludeBorderline] = ccs.GetNbConstraints()
+			if err := solved(t, ccs, assignment(tt.count)); err != nil {
+				t.Errorf("count %d rejected: %v", tt.count, err)
+			}
+			for _, wrong := range []int{ChunkSize - 2, ChunkSize} {
+				if wrong != tt.count && solved(t, ccs, assignment(wrong)) == nil {
+					t.Errorf("count %d accepted", wrong)
+				}
+			}
+		})
+	}
+	if sizes[false] >= sizes[true] {
+		t.Errorf("including borderline samples takes %d constraints, excluding them %d", sizes[false], sizes[true])
+	}
+}
+
+func TestSigmoidCircuitRejectOnSaturation(t *testing.T) {
+	clamping := compiled(t, &SigmoidCircuit{})
+	rejecting := compiled(t, &SigmoidCircuit{RejectOnSaturation: true})
+	step := 1.0 / (1 << inputPrecision) // one LUT index
+	tests := []struct {
+		name      string
+		z         float64
+		saturates bool
+	}{
+		{"inside", 0.5, false},
+		{"negative inside", -7.99, false},
+		{"at MaxInput", MaxInput, false},
+		{"at -MaxInput", -MaxInput, false},
+		{"one index beyond MaxInput", MaxInput + step, true},
+		{"one index beyond -MaxInput", -MaxInput - step, true},
+		{"far beyond", 50, true},
+		{"far beyond negative", -50, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label := 0
+			if tt.z >= 0 {
+				label = 1
+			}
+			assignment := func(label int) *SigmoidCircuit { return &SigmoidCircuit{Z: NewScaled(tt.z), Label: label} }
+			// clamping (the default) proves every z, saturated or not
+			if err := solved(t, clamping, assignment(label)); err != nil {
+				t.Errorf("clamping: label %d rejected: %v", label, err)
+			}
+			err := solved(t, rejecting, assignment(label))
+			if tt.saturates && err == nil {
+				t.Error("RejectOnSaturation accepted a saturated z")
+			}
+			if !tt.saturates && err != nil {
+				t.Errorf("RejectOnSaturation rejected an unsaturated z: %v", err)
+			}
+			for name, ccs := range map[string]constraint.ConstraintSystem{"clamping": clamping, "rejecting": rejecting} {
+				if solved(t, ccs, assignment(1-label)) == nil {
+					t.Errorf("%s: label %d accepted", name, 1-label)
+				}
+			}
+		})
+	}
+	if ConfigHash(&SigmoidCircuit{}) == ConfigHash(&SigmoidCircuit{RejectOnSaturation: true}) {
+		t.Error("RejectOnSaturation shares the default circuit's cache")
+	}
+}
+
+// TestDecisionBoundary pins the prediction at and next to z = 0: z >= 0 is
+// class 1, and the first negative Q32 value, which floors to the Q10 index
+// -1, is class 0, in every circuit and in the off-chain predictions.
+func TestDecisionBoundary(t *testing.T) {
+	sigmoid := compiled(t, &SigmoidCircuit{})
+	bias := compiled(t, &BiasCircuit{})
+	const q10Step = 1 << (Precision - inputPrecision)
+	tests := []struct {
+		name string
+		z    int64 // Q32
+		want int
+	}{
+		{"zero", 0, 1},
+		{"+1 LSB", 1, 1},
+		{"-1 LSB", -1, 0},
+		{"+1 Q10 step", q10Step, 1},
+		{"-1 Q10 step", -q10Step, 0},
+		{"just above -1 Q10 step", -q10Step + 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := big.NewInt(tt.z)
+			b := math.Ldexp(float64(tt.z), -Precision)
+			if got := IsNegativeField(DefaultCurve.ScalarField(), z); got != (tt.z < 0) {
+				t.Errorf("IsNegativeField = %v", got)
+			}
+			for label, wantErr := range []bool{tt.want != 0, tt.want != 1} {
+				if err := solved(t, sigmoid, &SigmoidCircuit{Z: z, Label: label}); (err != nil) != wantErr {
+					t.Errorf("sigmoid circuit, label %d: err = %v", label, err)
+				}
+				// W = 0, so z = B
+				if err := solved(t, bias, &BiasCircuit{W: 0, B: z, Label: label, ModelCommitment: ModelCommitment(0, b)}); (err != nil) != wantErr {
+					t.Errorf("bias circuit, label %d: err = %v", label, err)
+				}
+			}
+			if got := utils.PredictQuantized(0, b, 0); got != tt.want {
+				t.Errorf("PredictQuantized = %d, want %d", got, tt.want)
+			}
+			if got := utils.PredictClass(0, b, 0); got != tt.want {
+				t.Errorf("PredictClass = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigmoidCircuitExposeProbability(t *testing.T) {
+	table := ComputeSigmoidTable(DefaultLUTConfig())
+	for _, threshold := range []float64{0.5, 0.7} {
+		t.Run(fmt.Sprint(threshold), func(t *testing.T) {
+			ccs := compiled(t, NewProbabilitySigmoidCircuit(threshold))
+			if n := ccs.GetNbPublicVariables(); n != 3 {
+				t.Errorf("%d public variables, want Z, Label and Probability", n)
+			}
+			for _, z := range []float64{-12, -2.5, -0.001, 0, 0.3, 0.8473, 4, 12} {
+				zScaled := NewScaled(z)
+				p := QuantizedSigmoid(DefaultLUTConfig(), table, zScaled)
+				if math.Abs(float64(p)/(1<<outputPrecision)-1/(1+math.Exp(-z))) > 0.01 {
+					t.Errorf("z %v: probability %d is not sigmoid(z) in Q16", z, p)
+				}
+				label := 0
+				if p >= ThresholdToQ16(threshold) {
+					label = 1
+				}
+				assignment := func(p int64) *SigmoidCircuit {
+					return &SigmoidCircuit{Z: zScaled, Label: label, Probability: &SigmoidProbability{Value: p}}
+				}
+				if err := solved(t, ccs, assignment(p)); err != nil {
+					t.Errorf("z %v: QuantizedSigmoid %d rejected: %v", z, p, err)
+				}
+				for _, wrong := range []int64{p - 1, p + 1} {
+					if err := solved(t, ccs, assignment(wrong)); err == nil {
+						t.Errorf("z %v: probability %d accepted", z, wrong)
+					}
+				}
+			}
+		})
+	}
+
+	mismatched := []*SigmoidCircuit{
+		{ExposeProbability: true},
+		{Probability: &SigmoidProbability{}},
+	}
+	for _, c := range mismatched {
+		if _, err := Compile(BackendPlonk, DefaultCurve, c); err == nil {
+			t.Errorf("ExposeProbability %v with Probability %v compiled", c.ExposeProbability, c.Probability)
+		}
+	}
+	if ConfigHash(&SigmoidCircuit{}) == ConfigHash(NewProbabilitySigmoidCircuit(0.5)) {
+		t.Error("exposing the probability does not change the ConfigHash")
+	}
+}
+
+func TestAggregatorCircuitBoundsCounts(t *testing.T) {
+	p := DefaultCurve.ScalarField()
+	fieldMinus := func(n int64) *big.Int { return new(big.Int).Sub(p, big.NewInt(n)) }
+	tests := []struct {
+		name       string
+		minCorrect int
+		counts     [4]any
+		valid      bool
+	}{
+		{"all correct", 0, [4]any{25, 25, 25, 25}, true},
+		{"at the threshold", 0, [4]any{25, 25, 25, 22}, true},
+		{"below the threshold", 0, [4]any{25, 25, 24, 22}, false},
+		// 1000 alone would meet the threshold
+		{"inflated count", 0, [4]any{1000, 0, 0, 0}, false},
+		{"one over ChunkSize", 0, [4]any{ChunkSize + 1, 25, 25, 25}, false},
+		{"one over with a low threshold", 1, [4]any{ChunkSize + 1, 0, 0, 0}, false},
+		{"ChunkSize with a low threshold", 1, [4]any{ChunkSize, 0, 0, 0}, true},
+		// a "negative" count wrapping another one past ChunkSize
+		{"negative count", 1, [4]any{fieldMinus(1), 25, 25, 25}, false},
+		{"fits the count bits, over ChunkSize", 1, [4]any{31, 0, 0, 0}, false},
+	}
+	circuits := map[int]constraint.ConstraintSystem{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ccs, ok := circuits[tt.minCorrect]
+			if !ok {
+				ccs = compiled(t, NewAggregatorCircuit(tt.minCorrect))
+				circuits[tt.minCorrect] = ccs
+			}
+			c := tt.counts
+			err := solved(t, ccs, &AggregatorCircuit{Count1: c[0], Count2: c[1], Count3: c[2], Count4: c[3]})
+			if (err == nil) != tt.valid {
+				t.Errorf("err = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
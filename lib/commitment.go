@@ -0,0 +1,199 @@
+package lib
+
+import (
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls377mimc "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
+	blsmimc "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/mimc"
+	cmimc "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ============================================================================
+// CIRCUIT 3C: Committed Accuracy Chunk Circuit
+// Same count as AccuracyChunkCircuit, but the samples are private and the
+// proof is bound to a public MiMC commitment of them instead. (gnark has no
+// Poseidon gadget; MiMC is its native-field hash and plays the same role.)
+// ============================================================================
+
+type CommittedChunkCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     [ChunkSize]frontend.Variable
+	Label [ChunkSize]frontend.Variable
+
+	// Commitment is ChunkCommitment of the chunk's (mark, label) pairs.
+	Commitment frontend.Variable `gnark:",public"`
+	// Count is the number of eligible, correct predictions; see
+	// AccuracyChunkCircuit.
+	Count frontend.Variable `gnark:",public"`
+
+	// ModelCommitment is ModelCommitment(W, B); see LinearCircuit.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// IncludeBorderline disables the margin check; see AccuracyChunkCircuit.
+	IncludeBorderline bool `gnark:"-"`
+}
+
+// revision 2 asserts each prediction is boolean (see countCorrect);
+// revision 3 asserts each label is and binds the model commitment; revision
+// 4 bounds each mark.
+func (c *CommittedChunkCircuit) revision() int { return 4 }
+
+func (c *CommittedChunkCircuit) Define(api frontend.API) error {
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < ChunkSize; i++ {
+		// private labels must be bits so the commitment means what it
+		// says; see PrivateLabelChunkCircuit
+		api.AssertIsBoolean(c.Label[i])
+		h.Write(c.X[i], c.Label[i])
+	}
+	api.AssertIsEqual(h.Sum(), c.Commitment)
+
+	api.AssertIsEqual(countCorrect(api, c.W, c.B, c.X[:], c.Label[:], !c.IncludeBorderline), c.Count)
+	return nil
+}
+
+// ChunkCommitment hashes a chunk's Q32 marks and labels, interleaved, with
+// curve's MiMC, matching CommittedChunkCircuit compiled for curve.
+func ChunkCommitment(curve ecc.ID, marks []float64, labels []int) *big.Int {
+	values := make([]*big.Int, 0, 2*len(marks))
+	for i := range marks {
+		values = append(values, NewScaled(marks[i]), big.NewInt(int64(labels[i])))
+	}
+	return mimcHashOn(curve, values)
+}
+
+// DatasetCommitment commits to a whole dataset as the MiMC hash of its
+// ChunkSize chunk commitments, in order. A verifier recomputes it from the
+// Commitment inputs of the chunk proofs, so the accuracy proof as a whole is
+// bound to one dataset. Trailing samples that do not fill a chunk are not
+// covered. curve is the one the chunk proofs are made on.
+func DatasetCommitment(curve ecc.ID, marks []float64, labels []int) *big.Int {
+	var chunks []*big.Int
+	for start := 0; start+ChunkSize <= len(marks); start += ChunkSize {
+		chunks = append(chunks, ChunkCommitment(curve, marks[start:start+ChunkSize], labels[start:start+ChunkSize]))
+	}
+	return CombineCommitments(curve, chunks)
+}
+
+// CombineCommitments hashes chunk commitments on curve into a dataset
+// commitment.
+func CombineCommitments(curve ecc.ID, chunks []*big.Int) *big.Int {
+	return mimcHashOn(curve, chunks)
+}
+
+// BuildCommittedChunkWitness returns the full CommittedChunkCircuit witness
+// for a chunk of exactly ChunkSize samples under model (w, b), on field, so
+// its commitments are on the curve the keys were set up for.
+func BuildCommittedChunkWitness(field *big.Int, w, b float64, samples []utils.Sample) (witness.Witness, error) {
+	if len(samples) != ChunkSize {
+		return nil, fmt.Errorf("%w: committed chunk needs %d samples, got %d", ErrWitness, ChunkSize, len(samples))
+	}
+	marks := make([]float64, len(samples))
+	labels := make([]int, len(samples))
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	if err := checkMarks(marks); err != nil {
+		return nil, err
+	}
+
+	var assignment CommittedChunkCircuit
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	xs := QuantizeDataset(samples)
+	assignment.W = wScaled
+	assignment.B = bScaled
+	for i := range samples {
+		assignment.X[i] = xs[i]
+		assignment.Label[i] = big.NewInt(int64(labels[i]))
+	}
+	assignment.Commitment = ChunkCommitment(curveOfField(field), marks, labels)
+	assignment.Count = chunkCount(wScaled, bScaled, xs, labels, false)
+	assignment.ModelCommitment = modelCommitment(field, wScaled, bScaled)
+
+	full, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: committed chunk: %w", ErrWitness, err)
+	}
+	return full, nil
+}
+
+// ModelCommitment is the MiMC hash of the Q32 model parameters that the
+// linear, inference and accuracy chunk circuits expose as their public
+// ModelCommitment, on DefaultCurve. Proofs whose commitments are equal were
+// made with the same W and B; see CheckSameModel.
+func ModelCommitment(w, b float64) *big.Int {
+	return modelCommitment(DefaultCurve.ScalarField(), NewScaled(w), NewScaled(b))
+}
+
+// modelCommitment is ModelCommitment over the scalar field of a circuit.
+func modelCommitment(field *big.Int, wScaled, bScaled *big.Int) *big.Int {
+	return mimcHashOn(curveOfField(field), []*big.Int{wScaled, bScaled})
+}
+
+// assertModelCommitment constrains commitment to be the MiMC hash of (w, b).
+func assertModelCommitment(api frontend.API, w, b, commitment frontend.Variable) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	h.Write(w, b)
+	api.AssertIsEqual(h.Sum(), commitment)
+	return nil
+}
+
+// CheckSameModel checks that public witnesses of LinearCircuit,
+// InferenceCircuit and AccuracyChunkCircuit proofs, which all end in
+// ModelCommitment, commit to the same W and B. Each proof binds its own
+// commitment, but only this comparison binds the proofs to one another.
+func CheckSameModel(publics ...witness.Witness) error {
+	var want *big.Int
+	for i, public := range publics {
+		got, err := publicElement(public, -1)
+		if err != nil {
+			return fmt.Errorf("%w: proof %d: %w", ErrPublicLink, i+1, err)
+		}
+		if want == nil {
+			want = got
+		} else if got.Cmp(want) != 0 {
+			return fmt.Errorf("%w: proof %d commits to model %s, proof 1 to %s", ErrPublicLink, i+1, got, want)
+		}
+	}
+	return nil
+}
+
+// mimcHashOn hashes values, reduced into curve's scalar field, with that
+// field's MiMC, matching std/hash/mimc in a circuit compiled for curve.
+func mimcHashOn(curve ecc.ID, values []*big.Int) *big.Int {
+	var h hash.Hash
+	switch curve {
+	case ecc.BLS12_381:
+		h = blsmimc.NewMiMC()
+	case ecc.BLS12_377:
+		h = bls377mimc.NewMiMC()
+	default:
+		h = cmimc.NewMiMC()
+	}
+	field := curve.ScalarField()
+	var block [32]byte
+	for _, v := range values {
+		new(big.Int).Mod(v, field).FillBytes(block[:])
+		h.Write(block[:])
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
@@ -0,0 +1,181 @@
+package lib
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// testChunk returns ChunkSize samples labelled as testModel predicts them,
+// marks 2, 6, ..., 98, none of them borderline.
+func testChunk() []utils.Sample {
+	samples := make([]utils.Sample, ChunkSize)
+	for i := range samples {
+		x := float64(4*i + 2)
+		samples[i] = utils.Sample{Marks: x, Label: utils.PredictQuantized(testModel.w, testModel.b, x)}
+	}
+	return samples
+}
+
+func TestCommittedChunkCircuit(t *testing.T) {
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		t.Run(curve.String(), func(t *testing.T) {
+			ccs, err := Compile(BackendPlonk, curve, &CommittedChunkCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			full, err := BuildCommittedChunkWitness(ccs.Field(), testModel.w, testModel.b, testChunk())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ccs.IsSolved(full); err != nil {
+				t.Fatalf("honest witness: %v", err)
+			}
+		})
+	}
+
+	ccs := compiled(t, &CommittedChunkCircuit{})
+	samples := testChunk()
+	marks := make([]float64, ChunkSize)
+	labels := make([]int, ChunkSize)
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	assignment := func(labels []int, commitment, model *big.Int) *CommittedChunkCircuit {
+		a := &CommittedChunkCircuit{W: NewScaled(testModel.w), B: NewScaled(testModel.b), Commitment: commitment, ModelCommitment: model}
+		for i := range samples {
+			a.X[i] = NewScaled(marks[i])
+			a.Label[i] = labels[i]
+		}
+		a.Count = chunkCount(NewScaled(testModel.w), NewScaled(testModel.b), QuantizeDataset(samples), labels, false)
+		return a
+	}
+	nonBoolean := append([]int(nil), labels...)
+	nonBoolean[0] = 2
+
+	tests := []struct {
+		name       string
+		assignment frontend.Circuit
+		ok         bool
+	}{
+		{"honest", assignment(labels, ChunkCommitment(ecc.BN254, marks, labels), testCommitment()), true},
+		{"non-boolean label", assignment(nonBoolean, ChunkCommitment(ecc.BN254, marks, nonBoolean), testCommitment()), false},
+		{"other model", assignment(labels, ChunkCommitment(ecc.BN254, marks, labels), ModelCommitment(0.5, -10)), false},
+		{"commitment on another curve", assignment(labels, ChunkCommitment(ecc.BLS12_381, marks, labels), testCommitment()), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := solved(t, ccs, tt.assignment); (err == nil) != tt.ok {
+				t.Errorf("solved = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDatasetCommitment(t *testing.T) {
+	samples := append(testChunk(), testChunk()...)
+	marks := make([]float64, len(samples))
+	labels := make([]int, len(samples))
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	chunk := ChunkCommitment(ecc.BN254, marks[:ChunkSize], labels[:ChunkSize])
+	if got, want := DatasetCommitment(ecc.BN254, marks, labels), CombineCommitments(ecc.BN254, []*big.Int{chunk, chunk}); got.Cmp(want) != 0 {
+		t.Errorf("DatasetCommitment = %s, want the combined chunk commitments %s", got, want)
+	}
+	if DatasetCommitment(ecc.BN254, marks, labels).Cmp(DatasetCommitment(ecc.BLS12_381, marks, labels)) == 0 {
+		t.Error("DatasetCommitment is the same on BN254 and BLS12-381")
+	}
+	labels[3] ^= 1
+	if DatasetCommitment(ecc.BN254, marks, labels).Cmp(CombineCommitments(ecc.BN254, []*big.Int{chunk, chunk})) == 0 {
+		t.Error("changing a label left the dataset commitment unchanged")
+	}
+}
+
+func TestCheckSameModel(t *testing.T) {
+	field := DefaultCurve.ScalarField()
+	public := func(full witness.Witness, err error) witness.Witness {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := full.Public()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	inference := func(w float64) witness.Witness {
+		a := &InferenceCircuit{
+			W: NewScaled(w), B: NewScaled(testModel.b), X: NewScaled(30), Label: 0,
+			ModelCommitment: ModelCommitment(w, testModel.b),
+		}
+		return public(frontend.NewWitness(a, field))
+	}
+	marks := make([]float64, ChunkSize)
+	labels := make([]int, ChunkSize)
+	for i, s := range testChunk() {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	linear := public(LinearWitness(field, testModel.w, testModel.b, 30))
+	chunk := public(chunkWitness(field, testModel.w, testModel.b, marks, labels, false))
+	otherW := testModel.w + 1.0/(1<<Precision) // one Q32 step
+
+	tests := []struct {
+		name    string
+		publics []witness.Witness
+		same    bool
+	}{
+		{"linear, inference and chunk", []witness.Witness{linear, inference(testModel.w), chunk}, true},
+		{"inference with another W", []witness.Witness{linear, inference(otherW), chunk}, false},
+		{"chunk with another W", []witness.Witness{linear, inference(testModel.w), public(chunkWitness(field, otherW, testModel.b, marks, labels, false))}, false},
+		{"linear with another B", []witness.Witness{public(LinearWitness(field, testModel.w, testModel.b+1, 30)), chunk}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSameModel(tt.publics...)
+			if tt.same && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if !tt.same && !errors.Is(err, ErrPublicLink) {
+				t.Fatalf("err = %v, want ErrPublicLink", err)
+			}
+		})
+	}
+}
+
+// TestModelCommitmentIsBound checks a circuit only accepts the commitment
+// of the W and B it was given, so a proof cannot claim another model's.
+func TestModelCommitmentIsBound(t *testing.T) {
+	ccs := compiled(t, &LinearCircuit{})
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+	otherW := NewScaled(testModel.w + 0.25)
+	tests := []struct {
+		name       string
+		w          *big.Int
+		commitment *big.Int
+		ok         bool
+	}{
+		{"own commitment", wScaled, testCommitment(), true},
+		{"other model's commitment", otherW, testCommitment(), false},
+		{"commitment off by one", wScaled, new(big.Int).Add(testCommitment(), big.NewInt(1)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewScaled(30)
+			err := solved(t, ccs, &LinearCircuit{W: tt.w, B: bScaled, X: x, Z: linearZScaled(tt.w, bScaled, x), ModelCommitment: tt.commitment})
+			if tt.ok && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Fatal("accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// isLessBounded returns 1 if v < c and 0 otherwise, for a value v known to
+// lie in [0, 2^bits) and a constant 0 < c <= 2^bits. Instead of api.Cmp's
+// comparison over the whole field (which also needs the field-midpoint sign
+// convention for signed values), it decomposes v - c + 2^bits into bits+1
+// bits and reads the top one, so it costs about bits+1 constraints. The
+// caller must guarantee v >= 0: the decomposition rejects v >= 2^bits + c,
+// but a small negative v would read as less than c.
+func isLessBounded(api frontend.API, v frontend.Variable, c *big.Int, bits int) frontend.Variable {
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	shifted := api.Add(v, new(big.Int).Sub(offset, c))
+	b := api.ToBinary(shifted, bits+1)
+	return api.Sub(1, b[bits])
+}
+
+// maxMarksScaled is MaxMarks in Q32.
+var maxMarksScaled = new(big.Int).Lsh(big.NewInt(MaxMarks), Precision)
+
+// assertMark asserts that a Q32 mark x is in [0, MaxMarks], the documented
+// range of marks. Knowing x is non-negative and bounded keeps x*W far from
+// the field modulus without relying on the field-midpoint convention.
+func assertMark(api frontend.API, x frontend.Variable) {
+	assertAtMost(api, x, maxMarksScaled)
+}
+
+// assertAtMost asserts 0 <= v <= bound for a constant bound >= 0. v is range
+// checked to bound's bit length with api.ToBinary and then compared with
+// isLessBounded, about twice that many constraints in all.
+func assertAtMost(api frontend.API, v frontend.Variable, bound *big.Int) {
+	n := max(bound.BitLen(), 1)
+	api.ToBinary(v, n)
+	api.AssertIsEqual(isLessBounded(api, v, new(big.Int).Add(bound, big.NewInt(1)), n), 1)
+}
+
+// assertAtLeast asserts v >= minimum for a v the caller knows is in [0,
+// vMax], such as a sum of bits, with isLessBounded over bits.Len(vMax) bits.
+// A minimum above vMax could never be met and is returned as an error
+// instead.
+func assertAtLeast(api frontend.API, v frontend.Variable, minimum, vMax int) error {
+	if minimum <= 0 {
+		return nil
+	}
+	if minimum > vMax {
+		return fmt.Errorf("minimum %d exceeds the largest possible value %d", minimum, vMax)
+	}
+	api.AssertIsEqual(isLessBounded(api, v, big.NewInt(int64(minimum)), bits.Len(uint(vMax))), 0)
+	return nil
+}
@@ -0,0 +1,185 @@
+package lib
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// lessCircuit asserts isLessBounded(V, C, Bits) == Want.
+type lessCircuit struct {
+	V    frontend.Variable
+	Want frontend.Variable
+	C    int64 `gnark:"-"`
+	Bits int   `gnark:"-"`
+}
+
+func (c *lessCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(isLessBounded(api, c.V, big.NewInt(c.C), c.Bits), c.Want)
+	return nil
+}
+
+func TestIsLessBounded(t *testing.T) {
+	ccs := compiled(t, &lessCircuit{C: 10, Bits: 4})
+	tests := []struct {
+		v    int64
+		want int
+	}{
+		{0, 1},
+		{9, 1},
+		{10, 0},
+		{15, 0},
+	}
+	for _, tt := range tests {
+		if err := solved(t, ccs, &lessCircuit{V: tt.v, Want: tt.want}); err != nil {
+			t.Errorf("isLessBounded(%d, 10) = %d: %v", tt.v, tt.want, err)
+		}
+		if err := solved(t, ccs, &lessCircuit{V: tt.v, Want: 1 - tt.want}); err == nil {
+			t.Errorf("isLessBounded(%d, 10) = %d was accepted", tt.v, 1-tt.want)
+		}
+	}
+}
+
+// markCircuit asserts assertMark(X).
+type markCircuit struct {
+	X frontend.Variable
+}
+
+func (c *markCircuit) Define(api frontend.API) error {
+	assertMark(api, c.X)
+	return nil
+}
+
+func TestAssertMark(t *testing.T) {
+	ccs := compiled(t, &markCircuit{})
+	lsb := big.NewInt(1)
+	tests := []struct {
+		name string
+		x    *big.Int
+		ok   bool
+	}{
+		{"zero", big.NewInt(0), true},
+		{"max", new(big.Int).Set(maxMarksScaled), true},
+		{"max plus one LSB", new(big.Int).Add(maxMarksScaled, lsb), false},
+		{"101", NewScaled(101), false},
+		{"minus one LSB", big.NewInt(-1), false},
+		{"minus one", NewScaled(-1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := solved(t, ccs, &markCircuit{X: tt.x})
+			if tt.ok && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Fatal("accepted")
+			}
+		})
+	}
+}
+
+// atLeastCircuit asserts assertAtLeast(V, Minimum, VMax).
+type atLeastCircuit struct {
+	V       frontend.Variable
+	Minimum int `gnark:"-"`
+	VMax    int `gnark:"-"`
+}
+
+func (c *atLeastCircuit) Define(api frontend.API) error {
+	return assertAtLeast(api, c.V, c.Minimum, c.VMax)
+}
+
+// cmpAtLeastCircuit is atLeastCircuit as the accuracy circuits wrote it
+// before isLessBounded, for comparing their cost.
+type cmpAtLeastCircuit struct {
+	V       frontend.Variable
+	Minimum int `gnark:"-"`
+}
+
+func (c *cmpAtLeastCircuit) Define(api frontend.API) error {
+	isLess := api.IsZero(api.Add(api.Cmp(c.V, c.Minimum), 1))
+	api.AssertIsEqual(isLess, 0)
+	return nil
+}
+
+func TestAssertAtLeast(t *testing.T) {
+	ccs := compiled(t, &atLeastCircuit{Minimum: 97, VMax: NumSamples})
+	tests := []struct {
+		v  int
+		ok bool
+	}{
+		{0, false},
+		{96, false},
+		{97, true},
+		{NumSamples, true},
+	}
+	for _, tt := range tests {
+		err := solved(t, ccs, &atLeastCircuit{V: tt.v})
+		if tt.ok && err != nil {
+			t.Errorf("%d >= 97 rejected: %v", tt.v, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%d >= 97 accepted", tt.v)
+		}
+	}
+
+	if _, err := Compile(BackendPlonk, DefaultCurve, &atLeastCircuit{Minimum: NumSamples + 1, VMax: NumSamples}); err == nil {
+		t.Error("minimum above vMax compiled")
+	}
+
+	bounded := compiled(t, &atLeastCircuit{Minimum: 97, VMax: NumSamples}).GetNbConstraints()
+	cmp := compiled(t, &cmpAtLeastCircuit{Minimum: 97}).GetNbConstraints()
+	if bounded >= cmp {
+		t.Errorf("assertAtLeast costs %d constraints, api.Cmp %d", bounded, cmp)
+	}
+}
+
+func TestChunkCircuitBoundsMarks(t *testing.T) {
+	ccs := compiled(t, &AccuracyChunkCircuit{})
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+	assignment := func(first float64) *AccuracyChunkCircuit {
+		samples := testChunk()
+		samples[0] = utils.Sample{Marks: first, Label: utils.PredictQuantized(testModel.w, testModel.b, first)}
+		a := &AccuracyChunkCircuit{W: wScaled, B: bScaled, ModelCommitment: testCommitment()}
+		xs := QuantizeDataset(samples)
+		labels := make([]int, ChunkSize)
+		for i, s := range samples {
+			a.X[i] = xs[i]
+			a.Label[i] = s.Label
+			labels[i] = s.Label
+		}
+		a.Count = chunkCount(wScaled, bScaled, xs, labels, false)
+		return a
+	}
+
+	tests := []struct {
+		marks float64
+		ok    bool
+	}{
+		{0, true},
+		{MaxMarks, true},
+		{MaxMarks + 1, false},
+		{-1, false},
+	}
+	for _, tt := range tests {
+		err := solved(t, ccs, assignment(tt.marks))
+		if tt.ok && err != nil {
+			t.Errorf("marks %v rejected: %v", tt.marks, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("marks %v accepted", tt.marks)
+		}
+
+		marks := make([]float64, ChunkSize)
+		labels := make([]int, ChunkSize)
+		marks[0] = tt.marks
+		_, err = chunkWitness(ccs.Field(), testModel.w, testModel.b, marks, labels, false)
+		if tt.ok != (err == nil) || (err != nil && !errors.Is(err, ErrWitness)) {
+			t.Errorf("chunkWitness with marks %v: %v", tt.marks, err)
+		}
+	}
+}
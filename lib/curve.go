@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsfr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+)
+
+// DefaultCurve is the curve circuits are proved on unless configured
+// otherwise.
+const DefaultCurve = ecc.BN254
+
+// SupportedCurves lists the curves the pipeline can prove on.
+var SupportedCurves = []ecc.ID{ecc.BN254, ecc.BLS12_381}
+
+// ParseCurve parses a curve name such as "bn254" or "bls12-381".
+func ParseCurve(name string) (ecc.ID, error) {
+	for _, c := range SupportedCurves {
+		if strings.EqualFold(strings.ReplaceAll(name, "-", "_"), c.String()) {
+			return c, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unsupported curve %q (want bn254 or bls12_381)", name)
+}
+
+// curveOf returns the curve whose scalar field ccs is defined over.
+func curveOf(ccs constraint.ConstraintSystem) ecc.ID {
+	return curveOfField(ccs.Field())
+}
+
+// curveOfWitness returns the curve whose scalar field w's values are in.
+func curveOfWitness(w witness.Witness) ecc.ID {
+	switch w.Vector().(type) {
+	case fr.Vector:
+		return ecc.BN254
+	case blsfr.Vector:
+		return ecc.BLS12_381
+	}
+	return ecc.UNKNOWN
+}
+
+func curveOfField(field *big.Int) ecc.ID {
+	for _, c := range append([]ecc.ID{RecursionInnerCurve}, SupportedCurves...) {
+		if c.ScalarField().Cmp(field) == 0 {
+			return c
+		}
+	}
+	return ecc.UNKNOWN
+}
@@ -0,0 +1,97 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+func TestParseCurve(t *testing.T) {
+	tests := []struct {
+		name string
+		want ecc.ID
+	}{
+		{"bn254", ecc.BN254},
+		{"BN254", ecc.BN254},
+		{"bls12-381", ecc.BLS12_381},
+		{"bls12_381", ecc.BLS12_381},
+		{"bls12-377", ecc.UNKNOWN},
+		{"", ecc.UNKNOWN},
+	}
+	for _, tt := range tests {
+		got, err := ParseCurve(tt.name)
+		if got != tt.want || (err == nil) != (tt.want != ecc.UNKNOWN) {
+			t.Errorf("ParseCurve(%q) = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestLinearCircuitOnCurves(t *testing.T) {
+	for _, curve := range SupportedCurves {
+		for _, backend := range []Backend{BackendPlonk, BackendGroth16} {
+			t.Run(curve.String()+"/"+backend.String(), func(t *testing.T) {
+				keys, err := SetupBackend(backend, curve, &LinearCircuit{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := curveOf(keys.CCS); got != curve {
+					t.Fatalf("compiled over %v", got)
+				}
+				full, err := LinearWitness(keys.CCS.Field(), testModel.w, testModel.b, 30)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := curveOfWitness(full); got != curve {
+					t.Fatalf("witness over %v", got)
+				}
+				proof, err := keys.Prove(full)
+				if err != nil {
+					t.Fatal(err)
+				}
+				public, err := full.Public()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := keys.Verify(proof, public); err != nil {
+					t.Fatalf("proof does not verify: %v", err)
+				}
+
+				other, err := LinearWitness(keys.CCS.Field(), testModel.w, testModel.b, 31)
+				if err != nil {
+					t.Fatal(err)
+				}
+				otherPublic, err := other.Public()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if keys.Verify(proof, otherPublic) == nil {
+					t.Error("proof verified against another sample's public witness")
+				}
+			})
+		}
+	}
+}
+
+// TestSigmoidCircuitOnCurves checks the sign of z is read against each
+// curve's own field midpoint.
+func TestSigmoidCircuitOnCurves(t *testing.T) {
+	for _, curve := range SupportedCurves {
+		t.Run(curve.String(), func(t *testing.T) {
+			ccs, err := Compile(BackendPlonk, curve, &SigmoidCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tt := range []struct {
+				z    float64
+				want int
+			}{{-5, 0}, {-0.001, 0}, {0, 1}, {5, 1}} {
+				if err := solved(t, ccs, &SigmoidCircuit{Z: NewScaled(tt.z), Label: tt.want}); err != nil {
+					t.Errorf("z = %v: label %d rejected: %v", tt.z, tt.want, err)
+				}
+				if err := solved(t, ccs, &SigmoidCircuit{Z: NewScaled(tt.z), Label: 1 - tt.want}); err == nil {
+					t.Errorf("z = %v: label %d accepted", tt.z, 1-tt.want)
+				}
+			}
+		})
+	}
+}
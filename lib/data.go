@@ -0,0 +1,21 @@
+package lib
+
+import (
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// loadTestData loads the dataset with utils.LoadDataset, so the pipeline and
+// every other caller share one parser and its errors, and splits it into
+// marks and labels.
+func loadTestData(path string) ([]float64, []int, error) {
+	samples, err := utils.LoadDataset(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	marks := make([]float64, len(samples))
+	labels := make([]int, len(samples))
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	return marks, labels, nil
+}
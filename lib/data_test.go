@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// TestLoadTestDataMatchesUtils checks the pipeline loads a dataset exactly
+// as utils.LoadDataset does, errors included.
+func TestLoadTestDataMatchesUtils(t *testing.T) {
+	for _, content := range []string{"marks,failed\n69,0\n12.5,1\n", "marks,failed\n69\n", "marks,failed\nabc,0\n"} {
+		path := filepath.Join(t.TempDir(), "data.csv")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		samples, wantErr := utils.LoadDataset(path)
+		marks, labels, err := loadTestData(path)
+		if (err == nil) != (wantErr == nil) || (err != nil && err.Error() != wantErr.Error()) {
+			t.Fatalf("%q: err = %v, want %v", content, err, wantErr)
+		}
+		if len(marks) != len(samples) || len(labels) != len(samples) {
+			t.Fatalf("%q: %d marks, %d labels, want %d", content, len(marks), len(labels), len(samples))
+		}
+		for i, s := range samples {
+			if marks[i] != s.Marks || labels[i] != s.Label {
+				t.Errorf("%q: sample %d = (%v, %d), want %+v", content, i, marks[i], labels[i], s)
+			}
+		}
+	}
+}
+
+// TestFractionalMarksScaleAlike checks fractional marks keep their fraction
+// through the pipeline and scale to the same X as the simulation's
+// QuantizeDataset of utils.LoadDataset.
+func TestFractionalMarksScaleAlike(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte("marks,failed\n12.5,1\n69,0\n33.25,1\n99.75,0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := utils.LoadDataset(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	marks, _, err := loadTestData(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		marks float64
+		whole float64
+	}{
+		{12.5, 12},
+		{69, 69},
+		{33.25, 33},
+		{99.75, 99},
+	}
+	want := QuantizeDataset(samples)
+	got := quantizeMarks(marks)
+	for i, tt := range tests {
+		if got[i].Cmp(want[i]) != 0 {
+			t.Errorf("marks %v: pipeline X = %v, simulation X = %v", tt.marks, got[i], want[i])
+		}
+		if exact := NewScaled(tt.marks); got[i].Cmp(exact) != 0 {
+			t.Errorf("marks %v: X = %v, want %v", tt.marks, got[i], exact)
+		}
+		if fractional := tt.marks != tt.whole; fractional == (got[i].Cmp(NewScaled(tt.whole)) == 0) {
+			t.Errorf("marks %v: X = %v, fraction lost", tt.marks, got[i])
+		}
+	}
+}
@@ -0,0 +1,67 @@
+//go:build zklr_insecure_seed
+
+package lib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// SetInsecureProofSeed makes Prove deterministic, or with a nil seed
+// random again: the prover's blinding factors are drawn from a stream
+// seeded by seed rather than crypto/rand, so the same witness always
+// yields the same proof bytes under the same keys, e.g. for golden tests
+// of the proof encoding. Across processes the keys must be loaded from a
+// cache or set up against a fixed SRS, as unsafekzg samples a new one in
+// every process. It only exists in builds with the zklr_insecure_seed tag,
+// as anyone who knows the seed can strip the blinding and recover the
+// private inputs. Prove fails while lib is proving, setting up or drawing a
+// salt on another goroutine; see deterministicProve for its caveats.
+func (k *CircuitKeys) SetInsecureProofSeed(seed []byte) {
+	k.proofSeed = seed
+}
+
+// deterministicProve replaces crypto/rand.Reader with a seededReader until
+// the returned function is called. gnark's provers draw their blinding
+// factors from crypto/rand.Reader directly and take no ProverOption for
+// another source, so this is the only way to make their output
+// reproducible. It is only accepted while nothing else in lib is drawing
+// randomness, and lib waits for it to finish before drawing again; anything
+// outside lib reading crypto/rand meanwhile still gets predictable bytes,
+// so it must only run where lib is all the process does, as in a test,
+// which is why it needs the zklr_insecure_seed build tag.
+func deterministicProve(seed []byte) (restore func(), err error) {
+	if !randomnessMu.TryLock() {
+		return nil, fmt.Errorf("%w: InsecureProofSeed: another proof, setup or salt is drawing randomness concurrently", ErrProve)
+	}
+	saved := rand.Reader
+	rand.Reader = seededReader(seed)
+	return func() {
+		rand.Reader = saved
+		randomnessMu.Unlock()
+	}, nil
+}
+
+// seededReader fills every read with the start of the stream of
+// SHA-256(seed || counter) blocks. Every read gets the same bytes, so the
+// order in which the PLONK prover's concurrent goroutines draw their
+// blinding does not change the proof. The first byte is always zero, so
+// rejection sampling such as crypto/rand.Int (gnark's Randomize hint)
+// accepts the first draw rather than retrying the same bytes forever.
+type seededReader []byte
+
+func (seed seededReader) Read(p []byte) (int, error) {
+	n := 0
+	for counter := uint64(0); n < len(p); counter++ {
+		h := sha256.New()
+		h.Write(seed)
+		binary.Write(h, binary.BigEndian, counter)
+		n += copy(p[n:], h.Sum(nil))
+	}
+	if n > 0 {
+		p[0] = 0
+	}
+	return n, nil
+}
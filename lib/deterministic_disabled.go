@@ -0,0 +1,12 @@
+//go:build !zklr_insecure_seed
+
+package lib
+
+import "fmt"
+
+// deterministicProve is unreachable without the zklr_insecure_seed build
+// tag, as nothing can set a CircuitKeys' proof seed; see
+// SetInsecureProofSeed.
+func deterministicProve([]byte) (restore func(), err error) {
+	return nil, fmt.Errorf("%w: deterministic proofs need the zklr_insecure_seed build tag", ErrProve)
+}
@@ -0,0 +1,94 @@
+//go:build zklr_insecure_seed
+
+package lib
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// proofBytes proves full under keys and returns the proof's encoding.
+func proofBytes(t *testing.T, keys *CircuitKeys, full witness.Witness) []byte {
+	t.Helper()
+	proof, err := keys.Prove(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSetInsecureProofSeed(t *testing.T) {
+	for _, backend := range []Backend{BackendPlonk, BackendGroth16} {
+		t.Run(backend.String(), func(t *testing.T) {
+			keys, err := SetupBackend(backend, DefaultCurve, &LinearCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			full, err := LinearWitness(keys.CCS.Field(), testModel.w, testModel.b, 30)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader := rand.Reader
+
+			keys.SetInsecureProofSeed([]byte("seed"))
+			first := proofBytes(t, keys, full)
+			if !bytes.Equal(proofBytes(t, keys, full), first) {
+				t.Error("two proofs with the same seed differ")
+			}
+			if rand.Reader != reader {
+				t.Error("crypto/rand.Reader was not restored")
+			}
+			public, err := full.Public()
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof, err := keys.Prove(full)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := keys.Verify(proof, public); err != nil {
+				t.Errorf("seeded proof does not verify: %v", err)
+			}
+
+			keys.SetInsecureProofSeed([]byte("other seed"))
+			if bytes.Equal(proofBytes(t, keys, full), first) {
+				t.Error("another seed gave the same proof")
+			}
+			keys.SetInsecureProofSeed(nil)
+			if bytes.Equal(proofBytes(t, keys, full), proofBytes(t, keys, full)) {
+				t.Error("two unseeded proofs are identical")
+			}
+		})
+	}
+}
+
+func TestSetInsecureProofSeedRejectedConcurrently(t *testing.T) {
+	keys, err := SetupBackend(BackendPlonk, DefaultCurve, &LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := LinearWitness(keys.CCS.Field(), testModel.w, testModel.b, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.SetInsecureProofSeed([]byte("seed"))
+
+	// as if another goroutine were proving or drawing a salt
+	done := drawingRandomness()
+	_, err = keys.Prove(full)
+	done()
+	if !errors.Is(err, ErrProve) {
+		t.Fatalf("err = %v, want ErrProve", err)
+	}
+	if _, err := keys.Prove(full); err != nil {
+		t.Fatalf("after the other draw finished: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"math/big"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// Disagreement is a sample on which the float64 model and the circuit's
+// quantized computation predict differently. Both predict 1 when z >= 0, so
+// they only disagree when z is close enough to zero for the Q32 rounding of
+// W and X (or the float64 rounding) to flip its sign.
+type Disagreement struct {
+	SampleNum int     `json:"sample"` // 1-based position in the samples
+	Marks     float64 `json:"marks"`
+	Label     int     `json:"label"`
+	// Z is the float64 w*x + b; QuantizedZ is the circuit's Q32 z
+	// (LinearZ) converted back to a float.
+	Z          float64 `json:"z"`
+	QuantizedZ float64 `json:"quantized_z"`
+	// FloatPrediction is utils.PredictClass, CircuitPrediction is
+	// utils.PredictQuantized. A sample whose Label matches FloatPrediction
+	// still fails the sigmoid proof.
+	FloatPrediction   int `json:"float_prediction"`
+	CircuitPrediction int `json:"circuit_prediction"`
+}
+
+// DisagreementReport lists every sample whose float and quantized
+// predictions differ, in sample order.
+func DisagreementReport(w, b float64, samples []utils.Sample) []Disagreement {
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	var out []Disagreement
+	for i, s := range samples {
+		float := utils.PredictClass(w, b, s.Marks)
+		circuit := utils.PredictQuantized(w, b, s.Marks)
+		if float == circuit {
+			continue
+		}
+		qz, _ := new(big.Float).Quo(new(big.Float).SetInt(LinearZ(wScaled, bScaled, s.Marks)),
+			new(big.Float).SetInt(scalingFactor)).Float64()
+		out = append(out, Disagreement{
+			SampleNum:         i + 1,
+			Marks:             s.Marks,
+			Label:             s.Label,
+			Z:                 w*s.Marks + b,
+			QuantizedZ:        qz,
+			FloatPrediction:   float,
+			CircuitPrediction: circuit,
+		})
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestDisagreementReport(t *testing.T) {
+	// b = -1e-12 makes the float z at marks 0 a tiny negative number, class
+	// 0, but it quantizes to a Q32 z of 0, which the circuit calls class 1.
+	const w, b = 0.1, -1e-12
+	samples := []utils.Sample{{Marks: 50, Label: 1}, {Marks: 0, Label: 0}}
+
+	got := DisagreementReport(w, b, samples)
+	if len(got) != 1 {
+		t.Fatalf("got %d disagreements, want 1: %+v", len(got), got)
+	}
+	d := got[0]
+	if d.SampleNum != 2 || d.Marks != 0 || d.Label != 0 {
+		t.Errorf("reported sample %d (marks %v, label %d), want sample 2", d.SampleNum, d.Marks, d.Label)
+	}
+	if d.FloatPrediction != 0 || d.CircuitPrediction != 1 {
+		t.Errorf("predictions float %d, circuit %d, want 0, 1", d.FloatPrediction, d.CircuitPrediction)
+	}
+	if d.Z >= 0 || d.QuantizedZ != 0 {
+		t.Errorf("z = %v, quantized z = %v, want z < 0 and quantized z = 0", d.Z, d.QuantizedZ)
+	}
+
+	if got := DisagreementReport(testModel.w, testModel.b, testChunk()); len(got) != 0 {
+		t.Errorf("testChunk has %d disagreements, want 0", len(got))
+	}
+}
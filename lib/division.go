@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// quotientBits bounds the magnitude of a signed quotient returned by
+// divFloorPow2: |q| < 2^(quotientBits-1). It is far above any value the
+// circuits produce while keeping q*2^k well below the field modulus, which is
+// what makes the quotient unique.
+const quotientBits = 128
+
+func init() {
+	// divFloorPow2Hint is no longer emitted, but stays registered so circuit
+	// caches compiled before DivChecked can still be solved.
+	solver.RegisterHint(divFloorPow2Hint, divFloorHint)
+}
+
+// divFloorPow2Hint computes q = floor(v / 2^k) and r = v - q*2^k for a signed
+// v (values above the field midpoint are negative). inputs = [v, k].
+func divFloorPow2Hint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	divisor := new(big.Int).Lsh(big.NewInt(1), uint(inputs[1].Uint64()))
+	return divFloorHint(field, []*big.Int{inputs[0], divisor}, outputs)
+}
+
+// divFloorHint computes q = floor(v / d) and r = v - q*d for a signed v
+// (values above the field midpoint are negative) and d > 0. inputs = [v, d].
+func divFloorHint(field *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	v := fieldToSigned(field, inputs[0])
+
+	q, r := new(big.Int).DivMod(v, inputs[1], new(big.Int))
+	outputs[0].Mod(q, field)
+	outputs[1].Set(r)
+	return nil
+}
+
+// maxDivisor bounds DivChecked's divisor so that q*d stays far below half
+// the field modulus for any |q| < 2^(quotientBits-1).
+var maxDivisor = new(big.Int).Lsh(big.NewInt(1), 64)
+
+// DivChecked returns floor(v / divisor) for a signed v and a constant
+// 0 < divisor <= 2^64. The quotient and remainder come from a hint and are
+// constrained with q*divisor + r == v, 0 <= r < divisor and a bounded q, so
+// no other quotient satisfies the circuit. Unlike api.Div, which multiplies
+// by the field inverse, it matches integer division for any v.
+func DivChecked(api frontend.API, v frontend.Variable, divisor *big.Int) frontend.Variable {
+	if divisor.Sign() <= 0 || divisor.Cmp(maxDivisor) > 0 {
+		panic("DivChecked: divisor out of range (0, 2^64]")
+	}
+	res, err := api.Compiler().NewHint(divFloorHint, 2, v, divisor)
+	if err != nil {
+		panic(err)
+	}
+	q, r := res[0], res[1]
+
+	rc := rangecheck.New(api)
+	bits := divisor.BitLen()
+	isPow2 := new(big.Int).Lsh(big.NewInt(1), uint(bits-1)).Cmp(divisor) == 0
+	switch {
+	case bits == 1:
+		api.AssertIsEqual(r, 0)
+	case isPow2:
+		rc.Check(r, bits-1)
+	default:
+		// r < 2^bits and r + 2^bits - divisor < 2^bits give r < divisor
+		rc.Check(r, bits)
+		rc.Check(api.Add(r, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), divisor)), bits)
+	}
+	// shift q into [0, 2^quotientBits) so it can be range checked unsigned
+	offset := new(big.Int).Lsh(big.NewInt(1), quotientBits-1)
+	rc.Check(api.Add(q, offset), quotientBits)
+
+	api.AssertIsEqual(api.Add(api.Mul(q, divisor), r), v)
+	return q
+}
+
+// divFloorPow2 returns floor(v / 2^k) for a signed fixed-point value v; see
+// DivChecked.
+func divFloorPow2(api frontend.API, v frontend.Variable, k int) frontend.Variable {
+	return DivChecked(api, v, new(big.Int).Lsh(big.NewInt(1), uint(k)))
+}
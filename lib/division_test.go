@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+)
+
+// divCircuit asserts Q = DivChecked(V, divisor).
+type divCircuit struct {
+	V frontend.Variable
+	Q frontend.Variable `gnark:",public"`
+
+	divisor *big.Int
+}
+
+func (c *divCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(DivChecked(api, c.V, c.divisor), c.Q)
+	return nil
+}
+
+// forgedDivHint returns a divFloorHint replacement that runs the honest
+// hint and then lets forge change its quotient and remainder.
+func forgedDivHint(forge func(field, d, q, r *big.Int)) solver.Hint {
+	return func(field *big.Int, inputs, outputs []*big.Int) error {
+		if err := divFloorHint(field, inputs, outputs); err != nil {
+			return err
+		}
+		forge(field, inputs[1], outputs[0], outputs[1])
+		outputs[0].Mod(outputs[0], field)
+		outputs[1].Mod(outputs[1], field)
+		return nil
+	}
+}
+
+func TestDivCheckedRejectsForgedQuotient(t *testing.T) {
+	forgeries := []struct {
+		name  string
+		forge func(field, d, q, r *big.Int)
+	}{
+		{"quotient + 1", func(_, d, q, r *big.Int) { q.Add(q, big.NewInt(1)); r.Sub(r, d) }},
+		{"quotient - 1", func(_, d, q, r *big.Int) { q.Sub(q, big.NewInt(1)); r.Add(r, d) }},
+		{"field division", func(field, d, q, r *big.Int) {
+			// what api.Div returns: v * d^-1 in the field, remainder 0
+			v := new(big.Int).Add(new(big.Int).Mul(q, d), r)
+			q.Mul(v, new(big.Int).ModInverse(d, field))
+			r.SetInt64(0)
+		}},
+	}
+	divisors := map[string]*big.Int{
+		"one":      big.NewInt(1),
+		"2^32":     new(big.Int).Lsh(big.NewInt(1), 32),
+		"odd":      big.NewInt(1000003),
+		"2^64":     new(big.Int).Set(maxDivisor),
+		"2^64 - 1": new(big.Int).Sub(maxDivisor, big.NewInt(1)),
+	}
+	values := []*big.Int{big.NewInt(0), big.NewInt(7), big.NewInt(-7), NewScaled(testModel.w * 30), new(big.Int).Lsh(big.NewInt(-3), 90)}
+	for name, d := range divisors {
+		t.Run(name, func(t *testing.T) {
+			ccs := compiled(t, &divCircuit{divisor: d})
+			for _, v := range values {
+				q, r := new(big.Int).DivMod(v, d, new(big.Int))
+				full, err := frontend.NewWitness(&divCircuit{V: v, Q: q}, ccs.Field())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := ccs.IsSolved(full); err != nil {
+					t.Errorf("%v / %v = %v rejected: %v", v, d, q, err)
+				}
+				for _, f := range forgeries {
+					forged, forgedR := new(big.Int).Set(q), new(big.Int).Set(r)
+					f.forge(ccs.Field(), d, forged, forgedR)
+					if forged.Mod(forged, ccs.Field()).Cmp(new(big.Int).Mod(q, ccs.Field())) == 0 {
+						continue // e.g. field division of an exact multiple
+					}
+					full, err := frontend.NewWitness(&divCircuit{V: v, Q: forged}, ccs.Field())
+					if err != nil {
+						t.Fatal(err)
+					}
+					if ccs.IsSolved(full, solver.OverrideHint(solver.GetHintID(divFloorHint), forgedDivHint(f.forge))) == nil {
+						t.Errorf("%v / %v: forged %s quotient accepted", v, d, f.name)
+					}
+				}
+			}
+		})
+	}
+}
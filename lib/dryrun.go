@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+)
+
+// bn254G1CompressedSize is the size in bytes of a compressed BN254 G1 point.
+const bn254G1CompressedSize = 32
+
+// CircuitStats describes a compiled circuit and the KZG SRS its setup needs.
+type CircuitStats struct {
+	Name                string
+	NbConstraints       int
+	NbInternalVariables int
+	NbPublicVariables   int
+	NbSecretVariables   int
+	// SRSSize is SRSSize of the circuit: the number of G1 points in the
+	// canonical SRS.
+	SRSSize int
+	// SRSBytes estimates the compressed size of the canonical plus Lagrange SRS.
+	SRSBytes int
+}
+
+// CircuitInfo compiles circuit and reports its size and SRS requirements,
+// without running Setup. (The result type is CircuitStats, since a type
+// cannot share the function's name.)
+func CircuitInfo(circuit frontend.Circuit) (CircuitStats, error) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	if err != nil {
+		return CircuitStats{}, fmt.Errorf("%w: %w", ErrCircuitCompile, err)
+	}
+
+	lagrangeSize := srsLagrangeSize(ccs)
+	return CircuitStats{
+		NbConstraints:       ccs.GetNbConstraints(),
+		NbInternalVariables: ccs.GetNbInternalVariables(),
+		NbPublicVariables:   ccs.GetNbPublicVariables(),
+		NbSecretVariables:   ccs.GetNbSecretVariables(),
+		SRSSize:             SRSSize(ccs),
+		SRSBytes:            (2*lagrangeSize + 3) * bn254G1CompressedSize,
+	}, nil
+}
+
+// DryRun compiles every registered circuit and reports its size, without
+// running Setup or Prove.
+func DryRun() ([]CircuitStats, error) {
+	var stats []CircuitStats
+	for _, c := range Circuits(CircuitsAll) {
+		st, err := CircuitInfo(c.New(CircuitOptions{}))
+		if err != nil {
+			return stats, fmt.Errorf("%s circuit: %w", c.Name, err)
+		}
+		st.Name = c.Name
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
@@ -0,0 +1,46 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestCircuitInfo(t *testing.T) {
+	tests := []struct {
+		name           string
+		circuit        func() frontend.Circuit // a circuit compiles only once
+		public, secret int
+	}{
+		// X, Z and ModelCommitment are public; W and B secret
+		{"linear", func() frontend.Circuit { return &LinearCircuit{} }, 3, 2},
+		// Z and Label
+		{"sigmoid", func() frontend.Circuit { return &SigmoidCircuit{} }, 2, 0},
+		// X, Label and ModelCommitment public; W and B secret
+		{"inference", func() frontend.Circuit { return &InferenceCircuit{} }, 3, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := CircuitInfo(tt.circuit())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.NbPublicVariables != tt.public || info.NbSecretVariables != tt.secret {
+				t.Errorf("%d public, %d secret variables, want %d and %d", info.NbPublicVariables, info.NbSecretVariables, tt.public, tt.secret)
+			}
+			ccs := compiled(t, tt.circuit())
+			if info.NbConstraints != ccs.GetNbConstraints() || info.NbInternalVariables != ccs.GetNbInternalVariables() {
+				t.Errorf("%d constraints, %d internal variables, compiled %d and %d",
+					info.NbConstraints, info.NbInternalVariables, ccs.GetNbConstraints(), ccs.GetNbInternalVariables())
+			}
+			if info.SRSSize != SRSSize(ccs) || info.SRSSize < info.NbConstraints+info.NbPublicVariables {
+				t.Errorf("SRSSize %d for %d constraints", info.SRSSize, info.NbConstraints)
+			}
+		})
+	}
+
+	if _, err := CircuitInfo(&failingCircuit{}); !errors.Is(err, ErrCircuitCompile) {
+		t.Errorf("failing circuit: err = %v, want ErrCircuitCompile", err)
+	}
+}
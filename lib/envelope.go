@@ -0,0 +1,167 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// VKFingerprint returns a short SHA-256 hex digest of the serialized
+// verifying key, so a prover and verifier can check they hold the same key
+// before trusting (or blaming) a proof.
+func VKFingerprint(vk plonk.VerifyingKey) string {
+	var buf bytes.Buffer
+	if _, err := vk.WriteTo(&buf); err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+// ProofEnvelope is a self-describing JSON encoding of one proof and the
+// public witness it verifies against. VKFingerprint names the verifying key
+// the proof was made for; the byte fields are gnark's binary encodings.
+// Backend is empty for PLONK proofs. Version is the envelope format; see
+// envelopeVersion.
+type ProofEnvelope struct {
+	Version       int    `json:"version"`
+	Circuit       string `json:"circuit"`
+	Curve         string `json:"curve"`
+	Backend       string `json:"backend,omitempty"`
+	VKFingerprint string `json:"vk_fingerprint"`
+	Proof         []byte `json:"proof"`
+	PublicWitness []byte `json:"public_witness"`
+}
+
+// NewProofEnvelope wraps proof for the named circuit, made under vk.
+func NewProofEnvelope(circuit string, proof plonk.Proof, vk plonk.VerifyingKey, public witness.Witness) (ProofEnvelope, error) {
+	return newProofEnvelope(circuit, BackendPlonk, proof, VKFingerprint(vk), public)
+}
+
+// Envelope wraps proof for the named circuit, made under k.
+func (k *CircuitKeys) Envelope(circuit string, proof Proof, public witness.Witness) (ProofEnvelope, error) {
+	return newProofEnvelope(circuit, k.Backend, proof, k.VKFingerprint(), public)
+}
+
+func newProofEnvelope(circuit string, backend Backend, proof Proof, vkFingerprint string, public witness.Witness) (ProofEnvelope, error) {
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return ProofEnvelope{}, fmt.Errorf("encoding proof: %w", err)
+	}
+	publicData, err := public.MarshalBinary()
+	if err != nil {
+		return ProofEnvelope{}, fmt.Errorf("%w: marshal: %w", ErrWitness, err)
+	}
+	e := ProofEnvelope{
+		Version:       envelopeVersion,
+		Circuit:       circuit,
+		Curve:         curveOfWitness(public).String(),
+		VKFingerprint: vkFingerprint,
+		Proof:         buf.Bytes(),
+		PublicWitness: publicData,
+	}
+	if backend != BackendPlonk {
+		e.Backend = backend.String()
+	}
+	return e, nil
+}
+
+// envelopeVersion is the envelope format this build writes. Version 1 is
+// the format from before envelopes carried a version, which has no
+// "version" field; version 2 only adds the field.
+const envelopeVersion = 2
+
+// UnmarshalJSON decodes an envelope of any version from 1 to
+// envelopeVersion, upgrading it in memory to envelopeVersion, so verifiers
+// accept envelopes from older provers. A newer or unknown version is an
+// ErrFormatVersion error.
+func (e *ProofEnvelope) UnmarshalJSON(data []byte) error {
+	type plain ProofEnvelope // without the UnmarshalJSON method
+	var p struct {
+		plain
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	p.plain.Version = 1
+	if p.Version != nil {
+		p.plain.Version = *p.Version
+	}
+	upgraded, err := upgradeEnvelope(ProofEnvelope(p.plain))
+	if err != nil {
+		return err
+	}
+	*e = upgraded
+	return nil
+}
+
+// upgradeEnvelope converts e, one version at a time, to envelopeVersion.
+func upgradeEnvelope(e ProofEnvelope) (ProofEnvelope, error) {
+	if e.Version < 1 || e.Version > envelopeVersion {
+		return e, fmt.Errorf("%w: proof envelope version %d, this build reads 1 to %d", ErrFormatVersion, e.Version, envelopeVersion)
+	}
+	for e.Version < envelopeVersion {
+		switch e.Version {
+		case 1:
+			// the fields are unchanged; only the version is recorded
+			e.Version = 2
+		}
+	}
+	return e, nil
+}
+
+// Verify checks the envelope's PLONK proof under vk. A proof made for a
+// different key fails with ErrVKMismatch before any pairing check is
+// attempted.
+func (e ProofEnvelope) Verify(vk plonk.VerifyingKey) error {
+	return plonkKeys(nil, nil, vk).VerifyEnvelope(e)
+}
+
+// VerifyEnvelope checks e's proof under k, like ProofEnvelope.Verify; a
+// proof from the other backend is also an ErrVKMismatch.
+func (k *CircuitKeys) VerifyEnvelope(e ProofEnvelope) error {
+	if fp := k.VKFingerprint(); fp != e.VKFingerprint {
+		return fmt.Errorf("%w: %s proof needs vk %s, have %s", ErrVKMismatch, e.Circuit, e.VKFingerprint, fp)
+	}
+	backend, proof, public, err := e.decode()
+	if err != nil {
+		return err
+	}
+	if backend != k.Backend {
+		return fmt.Errorf("%w: %s proof is %s, vk is %s", ErrVKMismatch, e.Circuit, backend, k.Backend)
+	}
+	return k.Verify(proof, public)
+}
+
+// decode returns the envelope's backend, proof and public witness.
+func (e ProofEnvelope) decode() (Backend, Proof, witness.Witness, error) {
+	curve, err := ParseCurve(e.Curve)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	backend := BackendPlonk
+	if e.Backend != "" {
+		if backend, err = ParseBackend(e.Backend); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+
+	proof, err := ReadProof(backend, curve, e.Proof)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("decoding %s proof: %w", e.Circuit, err)
+	}
+	public, err := witness.New(curve.ScalarField())
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("%w: %w", ErrWitness, err)
+	}
+	if err := public.UnmarshalBinary(e.PublicWitness); err != nil {
+		return 0, nil, nil, fmt.Errorf("%w: unmarshal: %w", ErrWitness, err)
+	}
+	return backend, proof, public, nil
+}
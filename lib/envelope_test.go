@@ -0,0 +1,197 @@
+package lib
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// setupKeys runs backend's setup of circuit on DefaultCurve.
+func setupKeys(t *testing.T, backend Backend, circuit frontend.Circuit) *CircuitKeys {
+	t.Helper()
+	keys, err := SetupBackend(backend, DefaultCurve, circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestVKFingerprint(t *testing.T) {
+	rounding, err := ArithFor(RoundNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linear := setupKeys(t, BackendPlonk, &LinearCircuit{})
+	tests := []struct {
+		name string
+		a, b *CircuitKeys
+		same bool
+	}{
+		{"same keys", linear, linear, true},
+		{"other circuit", linear, setupKeys(t, BackendPlonk, &markCircuit{}), false},
+		{"other arithmetic", linear, setupKeys(t, BackendPlonk, &LinearCircuit{Arith: rounding}), false},
+		{"other setup", linear, setupKeys(t, BackendGroth16, &LinearCircuit{}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := tt.a.VKFingerprint(), tt.b.VKFingerprint()
+			for _, fp := range []string{a, b} {
+				if raw, err := hex.DecodeString(fp); err != nil || len(raw) != 8 {
+					t.Fatalf("fingerprint %q is not 8 hex bytes", fp)
+				}
+			}
+			if (a == b) != tt.same {
+				t.Errorf("fingerprints %s and %s, want equal = %v", a, b, tt.same)
+			}
+		})
+	}
+	if VKFingerprint(linear.plonkVK) != linear.VKFingerprint() {
+		t.Error("VKFingerprint and CircuitKeys.VKFingerprint differ")
+	}
+}
+
+func TestProofEnvelopeDetectsVKMismatch(t *testing.T) {
+	rounding, err := ArithFor(RoundNearest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linear := setupKeys(t, BackendPlonk, &LinearCircuit{})
+	full, err := LinearWitness(linear.CCS.Field(), testModel.w, testModel.b, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := full.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := linear.Prove(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := linear.Envelope("linear", proof, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ProofEnvelope
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.VKFingerprint != linear.VKFingerprint() || decoded.Curve != ecc.BN254.String() {
+		t.Fatalf("envelope records vk %s on %s", decoded.VKFingerprint, decoded.Curve)
+	}
+
+	tests := []struct {
+		name    string
+		keys    *CircuitKeys
+		wantErr error
+	}{
+		{"own keys", linear, nil},
+		{"another circuit version", setupKeys(t, BackendPlonk, &LinearCircuit{Arith: rounding}), ErrVKMismatch},
+		{"groth16 keys", setupKeys(t, BackendGroth16, &LinearCircuit{}), ErrVKMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.keys.VerifyEnvelope(decoded)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := decoded.Verify(linear.plonkVK); err != nil {
+		t.Errorf("ProofEnvelope.Verify: %v", err)
+	}
+}
+
+func TestProofEnvelopeVersions(t *testing.T) {
+	linear := setupKeys(t, BackendPlonk, &LinearCircuit{})
+	full, err := LinearWitness(linear.CCS.Field(), testModel.w, testModel.b, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := full.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := linear.Prove(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := linear.Envelope("linear", proof, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Version != envelopeVersion {
+		t.Fatalf("new envelope has version %d, want %d", envelope.Version, envelopeVersion)
+	}
+
+	// withVersion encodes envelope with its version field set to v, or
+	// removed as version 1 wrote it for nil
+	withVersion := func(v any) []byte {
+		data, err := json.Marshal(envelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatal(err)
+		}
+		delete(fields, "version")
+		if v != nil {
+			fields["version"] = v
+		}
+		if data, err = json.Marshal(fields); err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"v1 without a version field", withVersion(nil), nil},
+		{"explicit v1", withVersion(1), nil},
+		{"v2", withVersion(2), nil},
+		{"newer version", withVersion(envelopeVersion + 1), ErrFormatVersion},
+		{"version 0", withVersion(0), ErrFormatVersion},
+		{"negative version", withVersion(-1), ErrFormatVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded ProofEnvelope
+			err := json.Unmarshal(tt.data, &decoded)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Version != envelopeVersion {
+				t.Errorf("decoded version %d, want it upgraded to %d", decoded.Version, envelopeVersion)
+			}
+			if err := linear.VerifyEnvelope(decoded); err != nil {
+				t.Errorf("upgraded envelope rejected: %v", err)
+			}
+		})
+	}
+
+	var decoded ProofEnvelope
+	if err := json.Unmarshal([]byte(`{"version": "two"}`), &decoded); err == nil || errors.Is(err, ErrFormatVersion) {
+		t.Errorf("string version: err = %v, want a JSON error", err)
+	}
+}
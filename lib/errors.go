@@ -0,0 +1,20 @@
+package lib
+
+import "errors"
+
+// Sentinel errors for the failure categories of the proving pipeline.
+// Underlying gnark errors are wrapped, so callers can use errors.Is to
+// classify a failure and errors.Unwrap/As to reach the original cause.
+var (
+	ErrCircuitCompile = errors.New("circuit compilation failed")
+	ErrSetup          = errors.New("circuit setup failed")
+	ErrSRSTooSmall    = errors.New("SRS is too small for the circuit")
+	ErrWitness        = errors.New("witness construction failed")
+	ErrProve          = errors.New("proof generation failed")
+	ErrVerify         = errors.New("proof verification failed")
+	ErrCacheCorrupt   = errors.New("circuit cache is corrupt")
+	ErrFormatVersion  = errors.New("unsupported format version")
+	ErrPublicLink     = errors.New("public witnesses do not agree")
+	ErrSelfTest       = errors.New("known-answer self-test failed")
+	ErrVKMismatch     = errors.New("proof was made for a different verifying key")
+)
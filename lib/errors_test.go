@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// failingCircuit fails to compile.
+type failingCircuit struct {
+	X frontend.Variable
+}
+
+func (c *failingCircuit) Define(api frontend.API) error {
+	return errors.New("failingCircuit")
+}
+
+func TestErrorsIs(t *testing.T) {
+	keys, err := SetupBackend(BackendPlonk, DefaultCurve, &LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	field := keys.CCS.Field()
+	wScaled, bScaled, xScaled := NewScaled(testModel.w), NewScaled(testModel.b), NewScaled(30)
+	z := linearZScaled(wScaled, bScaled, xScaled)
+
+	sentinels := []error{ErrCircuitCompile, ErrSetup, ErrWitness, ErrProve, ErrVerify, ErrCacheCorrupt}
+	tests := []struct {
+		name string
+		want error
+		run  func() error
+	}{
+		{"compile", ErrCircuitCompile, func() error {
+			_, err := Compile(BackendPlonk, DefaultCurve, &failingCircuit{})
+			return err
+		}},
+		{"setup of an uncompilable circuit", ErrCircuitCompile, func() error {
+			_, err := SetupBackend(BackendPlonk, DefaultCurve, &failingCircuit{})
+			return err
+		}},
+		{"malformed witness", ErrWitness, func() error {
+			_, err := UnmarshalWitness([]byte{1, 2, 3})
+			return err
+		}},
+		{"unsatisfied witness", ErrProve, func() error {
+			full, err := linearWitness(field, wScaled, bScaled, xScaled, new(big.Int).Add(z, big.NewInt(1)))
+			if err != nil {
+				return err
+			}
+			_, err = keys.Prove(full)
+			return err
+		}},
+		{"other public witness", ErrVerify, func() error {
+			full, err := LinearWitness(field, testModel.w, testModel.b, 30)
+			if err != nil {
+				return err
+			}
+			proof, err := keys.Prove(full)
+			if err != nil {
+				return err
+			}
+			other, err := LinearWitness(field, testModel.w, testModel.b, 31)
+			if err != nil {
+				return err
+			}
+			public, err := other.Public()
+			if err != nil {
+				return err
+			}
+			return keys.Verify(proof, public)
+		}},
+		{"malformed proof bytes", ErrVerify, func() error {
+			return VerifyBytes([]byte{1, 2, 3}, nil, nil)
+		}},
+		{"truncated cache", ErrCacheCorrupt, func() error {
+			var buf bytes.Buffer
+			if err := keys.writeTo(&buf); err != nil {
+				return err
+			}
+			_, err := readCircuitKeys(BackendPlonk, DefaultCurve, bytes.NewReader(buf.Bytes()[:buf.Len()/2]))
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want errors.Is %v", err, tt.want)
+			}
+			for _, other := range sentinels {
+				if other != tt.want && errors.Is(err, other) {
+					t.Errorf("err = %v also matches %v", err, other)
+				}
+			}
+			if err.Error() == tt.want.Error() {
+				t.Errorf("err = %v carries no underlying cause", err)
+			}
+		})
+	}
+}
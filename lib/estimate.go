@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// EstimateRuntime predicts how long RunPipeline(cfg) will spend proving. It
+// sets up the selected circuits (from cache when possible, so the real run
+// does not pay setup again), times exactly one proof of each type and
+// extrapolates to the dataset size, the number of chunks and cfg.Concurrency.
+func EstimateRuntime(cfg PipelineConfig) (time.Duration, error) {
+	p, err := newPipeline(cfg)
+	if err != nil {
+		return 0, err
+	}
+	p.w, p.b, err = utils.LoadModelParameters(cfg.ModelPath)
+	if err != nil {
+		return 0, fmt.Errorf("loading model: %w", err)
+	}
+	if len(p.marks) == 0 {
+		return 0, fmt.Errorf("dataset %s has no samples", cfg.DatasetPath)
+	}
+
+	// Each stage is timed with a sample the circuit accepts: the label is
+	// the circuit's own prediction rather than the dataset's.
+	x := p.marks[0]
+	label := utils.PredictQuantized(p.w, p.b, x)
+	workers := p.cfg.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	plan := PlanProofs(len(p.marks), ChunkSize)
+	rounds := time.Duration((plan.Linear + workers - 1) / workers)
+
+	var total time.Duration
+	if p.cfg.Circuits.Has(CircuitsPerSample) {
+		var linear *CircuitKeys
+		if !p.cfg.SkipLinear {
+			if linear, err = p.setupCircuit("linear", p.circuitOptions(0)); err != nil {
+				return 0, err
+			}
+		}
+		sigmoid, err := p.setupCircuit("sigmoid", p.circuitOptions(0))
+		if err != nil {
+			return 0, err
+		}
+		_, t, err := proveSample(linear, sigmoid, NewScaled(p.w), NewScaled(p.b), x, NewScaled(x), label)
+		if err != nil {
+			return 0, fmt.Errorf("timing sample proof: %w", err)
+		}
+		total += rounds * (t.LinearWitness + t.LinearProve + t.SigmoidWitness + t.SigmoidProve)
+	}
+
+	if p.cfg.Circuits.Has(CircuitsInference) {
+		inference, err := p.setupCircuit("inference", p.circuitOptions(0))
+		if err != nil {
+			return 0, err
+		}
+		d, err := timeProof(inference, &InferenceCircuit{W: NewScaled(p.w), B: NewScaled(p.b), X: NewScaled(x), Label: label, ModelCommitment: modelCommitment(inference.CCS.Field(), NewScaled(p.w), NewScaled(p.b))})
+		if err != nil {
+			return 0, fmt.Errorf("timing inference proof: %w", err)
+		}
+		total += time.Duration(plan.Inference) * d
+	}
+
+	if p.cfg.Circuits.Has(CircuitsAccuracy) {
+		layout, minCorrect, err := p.accuracyPolicy()
+		if err != nil {
+			return 0, err
+		}
+		c, err := p.setupAccuracy(layout, minCorrect)
+		if err != nil {
+			return 0, err
+		}
+
+		marks, labels := layout.chunk(0, p.b, p.marks, p.labels)
+		full, err := chunkWitness(c.chunk.CCS.Field(), p.w, p.b, marks, labels, p.cfg.IncludeBorderline)
+		if err != nil {
+			return 0, err
+		}
+		start := time.Now()
+		if _, err := c.chunk.Prove(full); err != nil {
+			return 0, fmt.Errorf("timing chunk proof: %w", err)
+		}
+		total += time.Duration(plan.Chunk) * time.Since(start)
+
+		d, err := timeProof(c.agg, &AggregatorCircuit{Count1: ChunkSize, Count2: ChunkSize, Count3: ChunkSize, Count4: ChunkSize})
+		if err != nil {
+			return 0, fmt.Errorf("timing aggregator proof: %w", err)
+		}
+		total += time.Duration(plan.Aggregator) * d
+	}
+	return total, nil
+}
+
+// timeProof builds the witness for assignment and returns how long witness
+// construction and proving took.
+func timeProof(keys *CircuitKeys, assignment frontend.Circuit) (time.Duration, error) {
+	start := time.Now()
+	full, err := frontend.NewWitness(assignment, keys.CCS.Field())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrWitness, err)
+	}
+	if _, err := keys.Prove(full); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
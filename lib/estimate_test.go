@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEstimateRuntime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("sets up the linear and sigmoid circuits")
+	}
+	dir := t.TempDir()
+	model := writeModel(t, dir, "model.txt", testModel.w, testModel.b)
+	dataset := func(n int) string {
+		var csv strings.Builder
+		csv.WriteString("marks,failed\n")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&csv, "%d,0\n", i%(MaxMarks+1))
+		}
+		path := filepath.Join(dir, fmt.Sprintf("data%d.csv", n))
+		if err := os.WriteFile(path, []byte(csv.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name        string
+		samples     int
+		concurrency int
+	}{
+		{"10 samples", 10, 1},
+		{"100 samples", 100, 1},
+		{"100 samples on 4 workers", 100, 4},
+	}
+	estimates := make([]float64, len(tests))
+	for i, tt := range tests {
+		d, err := EstimateRuntime(PipelineConfig{
+			DatasetPath: dataset(tt.samples),
+			ModelPath:   model,
+			CacheDir:    filepath.Join(dir, "cache"), // shared, so only the first sets up
+			Circuits:    CircuitsPerSample,
+			Backend:     BackendGroth16,
+			Concurrency: tt.concurrency,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if d <= 0 {
+			t.Fatalf("%s: estimate %v, want it positive", tt.name, d)
+		}
+		estimates[i] = d.Seconds()
+		t.Logf("%s: %v", tt.name, d)
+	}
+	// each estimate times its own proof, so allow for noise in the ratios
+	if estimates[1] < 3*estimates[0] {
+		t.Errorf("10x the samples only raised the estimate from %.3gs to %.3gs", estimates[0], estimates[1])
+	}
+	if estimates[2] > estimates[1]/2 {
+		t.Errorf("4 workers only lowered the estimate from %.3gs to %.3gs", estimates[1], estimates[2])
+	}
+
+	if _, err := EstimateRuntime(PipelineConfig{DatasetPath: dataset(0), ModelPath: model, CacheDir: filepath.Join(dir, "cache")}); err == nil {
+		t.Error("empty dataset estimated")
+	}
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
+)
+
+// FixedArith is the fixed-point arithmetic a circuit computes z = W*X + B
+// with, over variables holding scaled integers. Implementations differ in
+// precision and rounding; the methods take the api so values are stateless
+// and can be set on a circuit before it is compiled.
+type FixedArith interface {
+	Mul(api frontend.API, a, b frontend.Variable) frontend.Variable
+	Add(api frontend.API, a, b frontend.Variable) frontend.Variable
+	Sub(api frontend.API, a, b frontend.Variable) frontend.Variable
+}
+
+// DefaultArith is the arithmetic circuits use when none is set: truncating
+// Q32, which LinearZ reproduces off-chain.
+var DefaultArith FixedArith = TruncatingQ{Precision: Precision}
+
+// Rounding is how an arithmetic rescales products, shared with utils.ComputeZ
+// and LinearZRounded so that off-chain z matches the circuit's exactly.
+type Rounding = fixedpoint.Rounding
+
+const (
+	Truncate     = fixedpoint.Truncate
+	RoundNearest = fixedpoint.RoundNearest
+)
+
+// ArithFor returns the Q32 arithmetic with rounding r: TruncatingQ for
+// Truncate, RoundingQ for RoundNearest. Set it as a circuit's Arith and
+// compute its Z with LinearZRounded(r, ...).
+func ArithFor(r Rounding) (FixedArith, error) {
+	switch r {
+	case Truncate:
+		return TruncatingQ{Precision: Precision}, nil
+	case RoundNearest:
+		return RoundingQ{Precision: Precision}, nil
+	}
+	return nil, fmt.Errorf("unknown rounding %s", r)
+}
+
+func arithOrDefault(arith FixedArith) FixedArith {
+	if arith == nil {
+		return DefaultArith
+	}
+	return arith
+}
+
+// TruncatingQ is fixed-point arithmetic with Precision fractional bits whose
+// Mul rounds toward negative infinity (floor division by 2^Precision).
+type TruncatingQ struct {
+	Precision int
+}
+
+func (q TruncatingQ) Mul(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return RescaleVar(api, api.Mul(a, b), 2*uint(q.Precision), uint(q.Precision))
+}
+
+func (q TruncatingQ) Add(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return api.Add(a, b)
+}
+
+func (q TruncatingQ) Sub(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return api.Sub(a, b)
+}
+
+// RoundingQ is fixed-point arithmetic with Precision fractional bits whose
+// Mul rounds to nearest, ties toward positive infinity. It halves the worst
+// case error of TruncatingQ at the cost of one addition.
+type RoundingQ struct {
+	Precision int
+}
+
+func (q RoundingQ) Mul(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	half := new(big.Int).Lsh(big.NewInt(1), uint(q.Precision-1))
+	return RescaleVar(api, api.Add(api.Mul(a, b), half), 2*uint(q.Precision), uint(q.Precision))
+}
+
+func (q RoundingQ) Add(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return api.Add(a, b)
+}
+
+func (q RoundingQ) Sub(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	return api.Sub(a, b)
+}
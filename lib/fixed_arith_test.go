@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// arithCircuit asserts Prod, Sum and Diff are A*B, A+B and A-B under arith.
+type arithCircuit struct {
+	A, B            frontend.Variable
+	Prod, Sum, Diff frontend.Variable `gnark:",public"`
+	arith           FixedArith
+}
+
+func (c *arithCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.arith.Mul(api, c.A, c.B), c.Prod)
+	api.AssertIsEqual(c.arith.Add(api, c.A, c.B), c.Sum)
+	api.AssertIsEqual(c.arith.Sub(api, c.A, c.B), c.Diff)
+	return nil
+}
+
+func TestFixedArith(t *testing.T) {
+	one := big.NewInt(1)
+	half := new(big.Int).Rsh(scalingFactor, 1)
+	tests := []struct {
+		name      string
+		a, b      *big.Int
+		truncated *big.Int // A*B under TruncatingQ
+		rounded   *big.Int // A*B under RoundingQ
+	}{
+		{"exact", NewScaled(0.5), NewScaled(3), NewScaled(1.5), NewScaled(1.5)},
+		{"exact negative", NewScaled(-0.5), NewScaled(3), NewScaled(-1.5), NewScaled(-1.5)},
+		{"tie", one, half, big.NewInt(0), big.NewInt(1)},
+		{"negative tie", big.NewInt(-1), half, big.NewInt(-1), big.NewInt(0)},
+		{"below half", one, new(big.Int).Sub(half, one), big.NewInt(0), big.NewInt(0)},
+		{"above half", big.NewInt(-1), new(big.Int).Add(half, one), big.NewInt(-1), big.NewInt(-1)},
+		{"model", NewScaled(testModel.w), NewScaled(30.1), LinearZ(NewScaled(testModel.w), big.NewInt(0), 30.1), LinearZRounded(RoundNearest, NewScaled(testModel.w), big.NewInt(0), 30.1)},
+	}
+	ariths := []struct {
+		rounding Rounding
+		arith    FixedArith
+	}{
+		{Truncate, TruncatingQ{Precision: Precision}},
+		{RoundNearest, RoundingQ{Precision: Precision}},
+	}
+	for _, a := range ariths {
+		t.Run(a.rounding.String(), func(t *testing.T) {
+			if got, err := ArithFor(a.rounding); err != nil || got != a.arith {
+				t.Errorf("ArithFor = %v, %v; want %v", got, err, a.arith)
+			}
+			ccs := compiled(t, &arithCircuit{arith: a.arith})
+			for _, tt := range tests {
+				prod := tt.truncated
+				if a.rounding == RoundNearest {
+					prod = tt.rounded
+				}
+				// the circuit agrees with the off-chain mirror
+				if mirror := linearZRounded(a.rounding, tt.a, big.NewInt(0), tt.b); mirror.Cmp(prod) != 0 {
+					t.Errorf("%s: off-chain product %v, want %v", tt.name, mirror, prod)
+				}
+				assignment := func(prod *big.Int) *arithCircuit {
+					return &arithCircuit{
+						A: tt.a, B: tt.b, Prod: prod,
+						Sum:  new(big.Int).Add(tt.a, tt.b),
+						Diff: new(big.Int).Sub(tt.a, tt.b),
+					}
+				}
+				if err := solved(t, ccs, assignment(prod)); err != nil {
+					t.Errorf("%s: product %v rejected: %v", tt.name, prod, err)
+				}
+				if solved(t, ccs, assignment(new(big.Int).Add(prod, one))) == nil {
+					t.Errorf("%s: product %v + 1 accepted", tt.name, prod)
+				}
+			}
+		})
+	}
+	if _, err := ArithFor(Rounding(99)); err == nil {
+		t.Error("ArithFor accepted an unknown rounding")
+	}
+}
+
+// TestComputeZMatchesLinearCircuit checks that utils.ComputeZ, scaled back to
+// Q32, is exactly the z the linear circuit accepts under each rounding mode.
+func TestComputeZMatchesLinearCircuit(t *testing.T) {
+	field := DefaultCurve.ScalarField()
+	models := []struct{ w, b float64 }{
+		{testModel.w, testModel.b},
+		{-0.85735312, 50.94705066},
+		{1.0 / 3, -7},
+	}
+	marks := []float64{0, 1, 20, 33.3, 59.4239, 1.0 / 3, 99.999999, MaxMarks}
+	differ := 0
+	for _, r := range []Rounding{Truncate, RoundNearest} {
+		t.Run(r.String(), func(t *testing.T) {
+			arith, err := ArithFor(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ccs := compiled(t, &LinearCircuit{Arith: arith})
+			other := RoundNearest
+			if r == RoundNearest {
+				other = Truncate
+			}
+			for _, m := range models {
+				wScaled, bScaled := NewScaled(m.w), NewScaled(m.b)
+				for _, x := range marks {
+					assignment := func(z *big.Int) *LinearCircuit {
+						return &LinearCircuit{W: wScaled, B: bScaled, X: NewScaled(x), Z: z, ModelCommitment: modelCommitment(field, wScaled, bScaled)}
+					}
+					z := NewScaled(utils.ComputeZ(m.w, m.b, x, r))
+					if err := solved(t, ccs, assignment(z)); err != nil {
+						t.Errorf("w %v, b %v, marks %v: ComputeZ %v rejected: %v", m.w, m.b, x, z, err)
+					}
+					if zOther := NewScaled(utils.ComputeZ(m.w, m.b, x, other)); zOther.Cmp(z) != 0 {
+						differ++
+						if err := solved(t, ccs, assignment(zOther)); err == nil {
+							t.Errorf("w %v, b %v, marks %v: the %s z accepted", m.w, m.b, x, other)
+						}
+					}
+				}
+			}
+		})
+	}
+	if differ == 0 {
+		t.Error("the rounding modes never differ on these samples")
+	}
+}
@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// Precision and ScalingFactor are the Q32 format of W, B, X and z, defined
+// once in package fixedpoint for the circuits and utils alike.
+const Precision = fixedpoint.Precision
+const ScalingFactor = fixedpoint.ScalingFactor
+var scalingFactor = new(big.Int).Lsh(big.NewInt(1), Precision)
+
+type FixedPoint struct {
+	Val frontend.Variable
+	Api frontend.API
+}
+
+func New(api frontend.API, v frontend.Variable) FixedPoint {
+	return FixedPoint{Val: v, Api: api}
+}
+
+func (a FixedPoint) Mul(b FixedPoint) FixedPoint {
+	// floor division, matching the off-chain big.Int computation of z
+	return New(a.Api, TruncatingQ{Precision: Precision}.Mul(a.Api, a.Val, b.Val))
+}
+
+func (a FixedPoint) Add(b FixedPoint) FixedPoint {
+	res := a.Api.Add(a.Val, b.Val)
+	return New(a.Api, res)
+}
+
+func (a FixedPoint) Sub(b FixedPoint) FixedPoint {
+	res := a.Api.Sub(a.Val, b.Val)
+	return New(a.Api, res)
+}
+
+func (a FixedPoint) Neg() FixedPoint {
+	res := a.Api.Neg(a.Val)
+	return New(a.Api, res)
+}
+
+// NewScaled converts a float to its Q32 fixed-point representation.
+func NewScaled(val float64) *big.Int {
+	f := new(big.Float).SetFloat64(val)
+	sf := new(big.Float).SetInt(scalingFactor)
+	f.Mul(f, sf)
+	res, _ := f.Int(nil)
+	return res
+}
+
+// scaledToFloat converts a Q32 value back to the nearest float64.
+func scaledToFloat(v *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), new(big.Float).SetInt(scalingFactor)).Float64()
+	return f
+}
+
+// NewScaledFromString converts a decimal string to Q32 without going through
+// float64, parsing it into a big.Float with the given mantissa precision in
+// bits (e.g. 128). Use it for model parameters with more significant digits
+// than a float64 can hold.
+func NewScaledFromString(s string, precision uint) (*big.Int, error) {
+	f, _, err := big.ParseFloat(s, 10, precision, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", s, err)
+	}
+	f.Mul(f, new(big.Float).SetInt(scalingFactor))
+	res, _ := f.Int(nil)
+	return res, nil
+}
+
+// LinearZ computes z = floor(w*x / 2^32) + b off-chain, exactly as
+// LinearCircuit does, for Q32 w and b.
+func LinearZ(wScaled, bScaled *big.Int, x float64) *big.Int {
+	return linearZScaled(wScaled, bScaled, NewScaled(x))
+}
+
+// LinearZRounded is LinearZ for a circuit whose Arith is ArithFor(rounding):
+// the product is rounded to nearest (ties up) for RoundNearest, as RoundingQ
+// does, instead of floored.
+func LinearZRounded(rounding Rounding, wScaled, bScaled *big.Int, x float64) *big.Int {
+	return linearZRounded(rounding, wScaled, bScaled, NewScaled(x))
+}
+
+// linearZScaled is LinearZ for an x already in Q32.
+func linearZScaled(wScaled, bScaled, xScaled *big.Int) *big.Int {
+	return linearZRounded(Truncate, wScaled, bScaled, xScaled)
+}
+
+func linearZRounded(rounding Rounding, wScaled, bScaled, xScaled *big.Int) *big.Int {
+	z := new(big.Int).Mul(wScaled, xScaled)
+	if rounding == RoundNearest {
+		z.Add(z, new(big.Int).Rsh(scalingFactor, 1))
+	}
+	z = RescaleInt(z, 2*Precision, Precision)
+	return z.Add(z, bScaled)
+}
+
+// QuantizeDataset returns NewScaled of every sample's marks, in order, so a
+// dataset is scaled to Q32 once rather than once per proof.
+func QuantizeDataset(samples []utils.Sample) []*big.Int {
+	xs := make([]*big.Int, len(samples))
+	for i, s := range samples {
+		xs[i] = NewScaled(s.Marks)
+	}
+	return xs
+}
+
+func quantizeMarks(marks []float64) []*big.Int {
+	xs := make([]*big.Int, len(marks))
+	for i, x := range marks {
+		xs[i] = NewScaled(x)
+	}
+	return xs
+}
+
+// checkMarks rejects marks outside [0, MaxMarks], which the accuracy
+// circuits would fail to prove, before a witness is built for them.
+func checkMarks(marks []float64) error {
+	for i, x := range marks {
+		if !(x >= 0 && x <= MaxMarks) {
+			return fmt.Errorf("%w: sample %d: marks %v outside [0, %d]", ErrWitness, i+1, x, MaxMarks)
+		}
+	}
+	return nil
+}
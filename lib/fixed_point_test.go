@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// truncatedQ32 returns s * 2^32 truncated toward zero, computed exactly.
+func truncatedQ32(t *testing.T, s string) *big.Int {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		t.Fatalf("bad decimal %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(scalingFactor))
+	return new(big.Int).Quo(r.Num(), r.Denom())
+}
+
+func TestNewScaledFromString(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		float      float64
+		floatLoses bool // NewScaled(float) differs from the exact Q32
+	}{
+		{"model weight", "-0.857353124567890123", -0.857353124567890123, false},
+		{"one LSB above 2^24", "16777216.00000000023283064365386962890625", 16777216.00000000023283064365386962890625, true},
+		{"one LSB below -2^24", "-16777216.00000000023283064365386962890625", -16777216.00000000023283064365386962890625, true},
+		{"integer", "69", 69, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := truncatedQ32(t, tt.s)
+			got, err := NewScaledFromString(tt.s, 128)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(want) != 0 {
+				t.Errorf("NewScaledFromString = %v, want %v", got, want)
+			}
+			if loses := NewScaled(tt.float).Cmp(want) != 0; loses != tt.floatLoses {
+				t.Errorf("NewScaled(%v) = %v, exact %v", tt.float, NewScaled(tt.float), want)
+			}
+			if f53, err := NewScaledFromString(tt.s, 53); err != nil || f53.Cmp(NewScaled(tt.float)) != 0 {
+				t.Errorf("at 53 bits: %v, %v, want NewScaled's %v", f53, err, NewScaled(tt.float))
+			}
+		})
+	}
+
+	if _, err := NewScaledFromString("-0.85.7", 128); err == nil {
+		t.Error("malformed decimal accepted")
+	}
+}
+
+// fixedPointCircuit asserts Diff = A - B and Neg = -A with FixedPoint.
+type fixedPointCircuit struct {
+	A, B      frontend.Variable
+	Diff, Neg frontend.Variable `gnark:",public"`
+}
+
+func (c *fixedPointCircuit) Define(api frontend.API) error {
+	a, b := New(api, c.A), New(api, c.B)
+	api.AssertIsEqual(a.Sub(b).Val, c.Diff)
+	api.AssertIsEqual(a.Neg().Val, c.Neg)
+	// a - b + b is a again, so Sub keeps the Q32 scaling
+	api.AssertIsEqual(a.Sub(b).Add(b).Val, c.A)
+	return nil
+}
+
+func TestFixedPointSub(t *testing.T) {
+	ccs := compiled(t, &fixedPointCircuit{})
+	tests := []struct {
+		a, b float64
+		diff float64
+	}{
+		{1.5, 0.25, 1.25},
+		{0.25, 1.5, -1.25},
+		{-0.5, -0.5, 0},
+		{testModel.w, testModel.b, testModel.w - testModel.b},
+		{100, -8, 108},
+	}
+	for _, tt := range tests {
+		a, b := NewScaled(tt.a), NewScaled(tt.b)
+		want := NewScaled(tt.diff)
+		if d := new(big.Int).Sub(a, b); d.Cmp(want) != 0 {
+			t.Fatalf("%v - %v: scaled difference %v, want %v", tt.a, tt.b, d, want)
+		}
+		neg := new(big.Int).Neg(a)
+		if err := solved(t, ccs, &fixedPointCircuit{A: a, B: b, Diff: want, Neg: neg}); err != nil {
+			t.Errorf("%v - %v = %v rejected: %v", tt.a, tt.b, tt.diff, err)
+		}
+		if solved(t, ccs, &fixedPointCircuit{A: a, B: b, Diff: NewScaled(tt.b - tt.a), Neg: neg}) == nil && tt.a != tt.b {
+			t.Errorf("%v - %v: reversed difference accepted", tt.a, tt.b)
+		}
+		if solved(t, ccs, &fixedPointCircuit{A: a, B: b, Diff: want, Neg: a}) == nil && tt.a != 0 {
+			t.Errorf("-%v: %v accepted", tt.a, tt.a)
+		}
+	}
+}
+
+func TestScalingAgreesWithUtils(t *testing.T) {
+	// the constants are fixedpoint's in both packages; check the values
+	// built from them agree
+	if scalingFactor.Cmp(big.NewInt(ScalingFactor)) != 0 {
+		t.Fatalf("scalingFactor = %v, want %d", scalingFactor, ScalingFactor)
+	}
+	for _, v := range []float64{0, 1, -1, 0.5, 1.0 / 3, -1.0 / 3, testModel.w, -0.85735312, 50.94705066, 1e-10, -1e-10, 100, -1e6} {
+		scaled := NewScaled(v)
+		if got := utils.FloatToFixed(v); !scaled.IsInt64() || got != scaled.Int64() {
+			t.Errorf("%v: utils.FloatToFixed = %d, NewScaled = %v", v, got, scaled)
+		}
+		if got, want := utils.FixedToFloat(utils.FloatToFixed(v)), scaledToFloat(scaled); got != want {
+			t.Errorf("%v: utils.FixedToFloat = %v, scaledToFloat = %v", v, got, want)
+		}
+		for _, r := range []Rounding{Truncate, RoundNearest} {
+			z := LinearZRounded(r, NewScaled(testModel.w), NewScaled(testModel.b), v)
+			if got, want := utils.ComputeZ(testModel.w, testModel.b, v, r), scaledToFloat(z); got != want {
+				t.Errorf("%v, %s: utils.ComputeZ = %v, LinearZRounded = %v", v, r, got, want)
+			}
+		}
+	}
+}
+
+func TestQuantizeDataset(t *testing.T) {
+	marks := []float64{0, 0.1, 1.0 / 3, 20, 59.4239, 99.999999, MaxMarks, 1e-12, 20}
+	samples := make([]utils.Sample, len(marks))
+	for i, x := range marks {
+		samples[i] = utils.Sample{Marks: x, Label: i % 2}
+	}
+
+	xs := QuantizeDataset(samples)
+	if len(xs) != len(samples) {
+		t.Fatalf("%d values for %d samples", len(xs), len(samples))
+	}
+	perMark := quantizeMarks(marks)
+	for i, x := range marks {
+		if want := NewScaled(x); xs[i].Cmp(want) != 0 || perMark[i].Cmp(want) != 0 {
+			t.Errorf("marks %v: QuantizeDataset %v, quantizeMarks %v, want NewScaled %v", x, xs[i], perMark[i], want)
+		}
+	}
+	// equal marks still get their own value, which a caller may modify
+	xs[3].SetInt64(-1)
+	if xs[8].Cmp(NewScaled(20)) != 0 {
+		t.Error("QuantizeDataset values for equal marks share storage")
+	}
+	if got := QuantizeDataset(nil); len(got) != 0 {
+		t.Errorf("QuantizeDataset(nil) = %v", got)
+	}
+}
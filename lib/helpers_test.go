@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// compiled compiles circuit for PLONK on DefaultCurve, failing the test on
+// error.
+func compiled(t testing.TB, circuit frontend.Circuit) constraint.ConstraintSystem {
+	t.Helper()
+	ccs, err := Compile(BackendPlonk, DefaultCurve, circuit)
+	if err != nil {
+		t.Fatalf("compiling %T: %v", circuit, err)
+	}
+	return ccs
+}
+
+// solved reports whether assignment satisfies ccs. Unlike test.IsSolved it
+// reduces negative assignments into the field first.
+func solved(t testing.TB, ccs constraint.ConstraintSystem, assignment frontend.Circuit) error {
+	t.Helper()
+	full, err := frontend.NewWitness(assignment, ccs.Field())
+	if err != nil {
+		t.Fatalf("witness for %T: %v", assignment, err)
+	}
+	return ccs.IsSolved(full)
+}
+
+// heavyTestsEnv names the environment variable that opts in to the tests
+// taking over a minute each, so that a plain go test ./... stays well inside
+// its default timeout.
+const heavyTestsEnv = "ZKLR_HEAVY_TESTS"
+
+// heavy skips the test unless heavyTestsEnv is 1, and always under -short.
+func heavy(t testing.TB, reason string) {
+	t.Helper()
+	if testing.Short() || os.Getenv(heavyTestsEnv) != "1" {
+		t.Skipf("%s; set %s=1 to run it", reason, heavyTestsEnv)
+	}
+}
+
+// testModel is the model the tests prove with: W < 0, so higher marks
+// predict 0 (Pass), with the decision boundary at marks = 20.
+var testModel = struct{ w, b float64 }{-0.5, 10}
+
+// testCommitment is ModelCommitment for testModel on DefaultCurve.
+func testCommitment() *big.Int {
+	return ModelCommitment(testModel.w, testModel.b)
+}
+
+// chunkKeys returns Groth16 keys for the default AccuracyChunkCircuit on
+// DefaultCurve, set up once per test binary. Groth16 proves a chunk in about
+// a second where PLONK takes half a minute, but the setup still takes most
+// of a minute, so tests using it are skipped under -short.
+func chunkKeys(t *testing.T) *CircuitKeys {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("sets up the accuracy chunk circuit")
+	}
+	chunkKeysOnce.Do(func() {
+		chunkKeysResult, chunkKeysErr = SetupBackend(BackendGroth16, DefaultCurve, &AccuracyChunkCircuit{})
+	})
+	if chunkKeysErr != nil {
+		t.Fatal(chunkKeysErr)
+	}
+	return chunkKeysResult
+}
+
+var (
+	chunkKeysOnce   sync.Once
+	chunkKeysResult *CircuitKeys
+	chunkKeysErr    error
+)
+
+// sampleKeys sets up the linear and sigmoid circuits under backend on
+// DefaultCurve, skipping the test under -short. The sigmoid table is
+// coarse to keep the setup quick; its witnesses are built the same way.
+func sampleKeys(t testing.TB, backend Backend) (linear, sigmoid *CircuitKeys) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("sets up the linear and sigmoid circuits")
+	}
+	linear, err := SetupBackend(backend, DefaultCurve, &LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigmoid, err = SetupBackend(backend, DefaultCurve, &SigmoidCircuit{InterpolationSteps: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return linear, sigmoid
+}
+
+// testLabels returns testModel's prediction for each of marks.
+func testLabels(marks []float64) []int {
+	labels := make([]int, len(marks))
+	for i, x := range marks {
+		labels[i] = utils.PredictQuantized(testModel.w, testModel.b, x)
+	}
+	return labels
+}
@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// CacheInfo describes a circuit cache file, as written by SaveCircuitData
+// or the pipeline's setup.
+type CacheInfo struct {
+	Backend Backend
+	Curve   ecc.ID
+	// ConfigHash is the ConfigHash recorded in the cache header, or "" for
+	// a file without one (see SaveCircuitData).
+	ConfigHash string
+	// GnarkVersion is the gnark version that serialized the constraint
+	// system. After the header the cache is gnark's constraint system,
+	// proving key and verifying key encodings in turn.
+	GnarkVersion  string
+	NbConstraints int
+	// NbPublic, NbSecret and NbInternal are the variable counts as gnark
+	// reports them; NbPublic includes the constant 1 wire under Groth16.
+	NbPublic      int
+	NbSecret      int
+	NbInternal    int
+	VKFingerprint string
+}
+
+// InspectCache loads the constraint system and keys in filename without
+// knowing which backend and curve wrote it, trying each in turn, and
+// describes them.
+func InspectCache(filename string) (CacheInfo, error) {
+	for _, backend := range []Backend{BackendPlonk, BackendGroth16} {
+		for _, curve := range SupportedCurves {
+			k, config, err := readCacheAs(filename, backend, curve)
+			if err != nil {
+				continue
+			}
+			info := CacheInfo{
+				Backend:       backend,
+				Curve:         curve,
+				ConfigHash:    config,
+				NbConstraints: k.CCS.GetNbConstraints(),
+				NbPublic:      k.CCS.GetNbPublicVariables(),
+				NbSecret:      k.CCS.GetNbSecretVariables(),
+				NbInternal:    k.CCS.GetNbInternalVariables(),
+				VKFingerprint: k.VKFingerprint(),
+			}
+			// every concrete constraint system embeds constraint.System
+			if v := reflect.ValueOf(k.CCS).Elem().FieldByName("GnarkVersion"); v.IsValid() {
+				info.GnarkVersion = v.String()
+			}
+			return info, nil
+		}
+	}
+	if _, err := os.Stat(filename); err != nil {
+		return CacheInfo{}, err
+	}
+	return CacheInfo{}, fmt.Errorf("%w: %s is not a cache for any supported backend and curve", ErrCacheCorrupt, filename)
+}
+
+// readCacheAs reads filename as backend's cache on curve, requiring every
+// byte to be consumed, and returns it with its header's config hash.
+func readCacheAs(filename string, backend Backend, curve ecc.ID) (k *CircuitKeys, config string, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	defer func() {
+		// decoding another backend's or curve's encoding can panic
+		if r := recover(); r != nil {
+			k, config, err = nil, "", fmt.Errorf("%w: %v", ErrCacheCorrupt, r)
+		}
+	}()
+
+	r := bufio.NewReader(file)
+	if config, err = readCacheHeader(r); err != nil {
+		return nil, "", err
+	}
+	k, err = readCircuitKeys(backend, curve, r)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		return nil, "", fmt.Errorf("%w: trailing data", ErrCacheCorrupt)
+	}
+	return k, config, nil
+}
@@ -0,0 +1,104 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+func TestInspectCache(t *testing.T) {
+	dir := t.TempDir()
+	discard := func(string, ...any) {}
+	circuit := &LinearCircuit{}
+
+	tests := []struct {
+		backend Backend
+		curve   ecc.ID
+	}{
+		{BackendPlonk, DefaultCurve},
+		{BackendGroth16, DefaultCurve},
+		{BackendPlonk, ecc.BLS12_381},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend.String()+"/"+tt.curve.String(), func(t *testing.T) {
+			file := filepath.Join(dir, tt.backend.String()+"_"+tt.curve.String()+".cache")
+			keys, err := loadOrSetupKeys(tt.backend, tt.curve, file, &LinearCircuit{}, nil, true, discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := InspectCache(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := CacheInfo{
+				Backend:       tt.backend,
+				Curve:         tt.curve,
+				ConfigHash:    ConfigHash(circuit),
+				GnarkVersion:  gnark.Version.String(),
+				NbConstraints: keys.CCS.GetNbConstraints(),
+				NbPublic:      keys.CCS.GetNbPublicVariables(),
+				NbSecret:      keys.CCS.GetNbSecretVariables(),
+				NbInternal:    keys.CCS.GetNbInternalVariables(),
+				VKFingerprint: keys.VKFingerprint(),
+			}
+			if info != want {
+				t.Errorf("InspectCache = %+v,\nwant %+v", info, want)
+			}
+			// LinearCircuit has X, Z and ModelCommitment public and W, B secret
+			if info.NbSecret != 2 || info.NbPublic < 3 {
+				t.Errorf("%d public and %d secret variables, want 3 (+1) and 2", info.NbPublic, info.NbSecret)
+			}
+		})
+	}
+
+	t.Run("without a header", func(t *testing.T) {
+		ccs, pk, vk, err := Setup(&LinearCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "bare.cache")
+		if err := SaveCircuitData(file, ccs, pk, vk); err != nil {
+			t.Fatal(err)
+		}
+		info, err := InspectCache(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.ConfigHash != "" || info.Backend != BackendPlonk || info.NbConstraints != ccs.GetNbConstraints() {
+			t.Errorf("InspectCache = %+v, want a PLONK cache without a config hash", info)
+		}
+	})
+
+	valid, err := os.ReadFile(filepath.Join(dir, "plonk_bn254.cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"truncated", valid[:len(valid)/2], ErrCacheCorrupt},
+		{"trailing data", append(valid, 0), ErrCacheCorrupt},
+		{"not a cache", []byte("no keys here"), ErrCacheCorrupt},
+		{"missing", nil, os.ErrNotExist},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "invalid.cache")
+			os.Remove(file)
+			if tt.data != nil {
+				if err := os.WriteFile(file, tt.data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := InspectCache(file); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
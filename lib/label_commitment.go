@@ -0,0 +1,158 @@
+package lib
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ============================================================================
+// CIRCUIT 3D: Private-Label Accuracy Chunk Circuit
+// Same count as AccuracyChunkCircuit over public marks, but the labels are
+// private and only a salted MiMC commitment to them is public, so the
+// verifier learns the count and not which predictions were correct.
+// ============================================================================
+
+type PrivateLabelChunkCircuit struct {
+	W     frontend.Variable
+	B     frontend.Variable
+	X     [ChunkSize]frontend.Variable `gnark:",public"`
+	Label [ChunkSize]frontend.Variable
+	// Salt blinds LabelCommitment: without it a verifier could recover the
+	// labels by hashing all 2^ChunkSize label vectors.
+	Salt frontend.Variable
+
+	// LabelCommitment is LabelCommitment(Label, Salt).
+	LabelCommitment frontend.Variable `gnark:",public"`
+	// Count is the number of eligible, correct predictions; see
+	// AccuracyChunkCircuit.
+	Count           frontend.Variable `gnark:",public"`
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// IncludeBorderline disables the margin check; see AccuracyChunkCircuit.
+	IncludeBorderline bool `gnark:"-"`
+}
+
+// revision 2 asserts each prediction is boolean (see countCorrect);
+// revision 3 bounds each mark.
+func (c *PrivateLabelChunkCircuit) revision() int { return 3 }
+
+func (c *PrivateLabelChunkCircuit) Define(api frontend.API) error {
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Salt)
+	for i := 0; i < ChunkSize; i++ {
+		// public labels are checked by the verifier; private ones must be
+		// bits here so the commitment means what it says
+		api.AssertIsBoolean(c.Label[i])
+		h.Write(c.Label[i])
+	}
+	api.AssertIsEqual(h.Sum(), c.LabelCommitment)
+
+	api.AssertIsEqual(countCorrect(api, c.W, c.B, c.X[:], c.Label[:], !c.IncludeBorderline), c.Count)
+	return nil
+}
+
+// NewLabelSalt returns a uniformly random salt for LabelCommitment. The data
+// owner keeps it secret alongside the labels; reusing it across commitments
+// lets equal label vectors be recognised.
+func NewLabelSalt() (*big.Int, error) {
+	defer drawingRandomness()()
+	salt, err := rand.Int(rand.Reader, DefaultCurve.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("label salt: %w", err)
+	}
+	return salt, nil
+}
+
+// LabelCommitment hashes salt followed by labels with MiMC, matching
+// PrivateLabelChunkCircuit compiled on DefaultCurve.
+func LabelCommitment(labels []int, salt *big.Int) *big.Int {
+	return labelCommitment(DefaultCurve.ScalarField(), labels, salt)
+}
+
+// labelCommitment is LabelCommitment over the scalar field of a circuit.
+func labelCommitment(field *big.Int, labels []int, salt *big.Int) *big.Int {
+	values := make([]*big.Int, 0, 1+len(labels))
+	values = append(values, salt)
+	for _, l := range labels {
+		values = append(values, big.NewInt(int64(l)))
+	}
+	return mimcHashOn(curveOfField(field), values)
+}
+
+// ProvePrivateLabelChunk is ProveChunkCount for a compiled
+// PrivateLabelChunkCircuit. The returned public witness, laid out as [X...,
+// LabelCommitment, Count, ModelCommitment], carries no labels; the data
+// owner checks LabelCommitment against LabelCommitment(labels, salt).
+func ProvePrivateLabelChunk(keys *CircuitKeys, w, b float64, samples []utils.Sample, salt *big.Int) (Proof, int, witness.Witness, error) {
+	if len(samples) != ChunkSize {
+		return nil, 0, nil, fmt.Errorf("%w: chunk needs %d samples, got %d", ErrWitness, ChunkSize, len(samples))
+	}
+	marks := make([]float64, len(samples))
+	labels := make([]int, len(samples))
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+	if err := checkMarks(marks); err != nil {
+		return nil, 0, nil, err
+	}
+
+	field := keys.CCS.Field()
+	var assignment PrivateLabelChunkCircuit
+	wScaled, bScaled := NewScaled(w), NewScaled(b)
+	xs := QuantizeDataset(samples)
+	assignment.W = wScaled
+	assignment.B = bScaled
+	for i := 0; i < ChunkSize; i++ {
+		assignment.X[i] = xs[i]
+		assignment.Label[i] = big.NewInt(int64(labels[i]))
+	}
+	assignment.Salt = salt
+	assignment.LabelCommitment = labelCommitment(field, labels, salt)
+	assignment.Count = chunkCount(wScaled, bScaled, xs, labels, false)
+	assignment.ModelCommitment = modelCommitment(field, wScaled, bScaled)
+
+	full, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: private-label chunk: %w", ErrWitness, err)
+	}
+	public, err := full.Public()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: private-label chunk public: %w", ErrWitness, err)
+	}
+	proof, err := keys.Prove(full)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("private-label chunk: %w", err)
+	}
+	count, err := PublicPrivateLabelCount(public)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return proof, count, public, nil
+}
+
+// PublicPrivateLabelCount extracts Count from a PrivateLabelChunkCircuit
+// public witness.
+func PublicPrivateLabelCount(public witness.Witness) (int, error) {
+	v, err := publicElement(public, ChunkSize+1)
+	if err != nil {
+		return 0, fmt.Errorf("%w: chunk count: %w", ErrWitness, err)
+	}
+	if !v.IsUint64() || v.Uint64() > ChunkSize {
+		return 0, fmt.Errorf("%w: chunk count %s out of range", ErrWitness, v.String())
+	}
+	return int(v.Uint64()), nil
+}
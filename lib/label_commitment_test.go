@@ -0,0 +1,142 @@
+package lib
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestLabelCommitment(t *testing.T) {
+	_, labels := testDataset()
+	salt := big.NewInt(12345)
+	flipped := append([]int(nil), labels[0]...)
+	flipped[3] = 1 - flipped[3]
+	tests := []struct {
+		name   string
+		labels []int
+		salt   *big.Int
+		same   bool
+	}{
+		{"same labels and salt", append([]int(nil), labels[0]...), big.NewInt(12345), true},
+		{"one label flipped", flipped, salt, false},
+		{"other salt", labels[0], big.NewInt(12346), false},
+		{"other chunk", labels[1], salt, false},
+	}
+	want := LabelCommitment(labels[0], salt)
+	for _, tt := range tests {
+		if same := LabelCommitment(tt.labels, tt.salt).Cmp(want) == 0; same != tt.same {
+			t.Errorf("%s: commitments equal = %v, want %v", tt.name, same, tt.same)
+		}
+	}
+
+	a, err := NewLabelSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewLabelSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Cmp(b) == 0 || a.Cmp(DefaultCurve.ScalarField()) >= 0 {
+		t.Errorf("salts %v and %v, want two distinct field elements", a, b)
+	}
+}
+
+func TestPrivateLabelChunkCircuit(t *testing.T) {
+	ccs := compiled(t, &PrivateLabelChunkCircuit{})
+	marks, labels := testDataset()
+	salt := big.NewInt(12345)
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+	xs := quantizeMarks(marks[0])
+
+	// assignment claims count for the first chunk's marks with labels and
+	// a commitment to committed
+	assignment := func(labels, committed []int, count int64) *PrivateLabelChunkCircuit {
+		c := &PrivateLabelChunkCircuit{
+			W: wScaled, B: bScaled, Salt: salt,
+			LabelCommitment: LabelCommitment(committed, salt),
+			Count:           count,
+			ModelCommitment: testCommitment(),
+		}
+		for i := range c.X {
+			c.X[i], c.Label[i] = xs[i], labels[i]
+		}
+		return c
+	}
+	// the same count as the public-label circuit: 24, as marks 20 is
+	// borderline and excluded
+	if count := chunkCount(wScaled, bScaled, xs, labels[0], false); count != 24 {
+		t.Fatalf("chunk count %v, want 24", count)
+	}
+	flipped := append([]int(nil), labels[0]...)
+	flipped[3] = 1 - flipped[3]
+	nonBoolean := append([]int(nil), labels[0]...)
+	nonBoolean[3] = 2
+
+	tests := []struct {
+		name              string
+		labels, committed []int
+		count             int64
+		ok                bool
+	}{
+		{"honest", labels[0], labels[0], 24, true},
+		{"flipped label, honest count", flipped, flipped, 23, true},
+		{"inflated count", labels[0], labels[0], 25, false},
+		{"labels other than committed", flipped, labels[0], 23, false},
+		{"non-boolean label", nonBoolean, nonBoolean, 23, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := solved(t, ccs, assignment(tt.labels, tt.committed, tt.count)); tt.ok != (err == nil) {
+				t.Errorf("%v, want satisfied = %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestProvePrivateLabelChunk(t *testing.T) {
+	if testing.Short() {
+		t.Skip("sets up the private-label chunk circuit")
+	}
+	keys, err := SetupBackend(BackendGroth16, DefaultCurve, &PrivateLabelChunkCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	marks, labels := testDataset()
+	samples := make([]utils.Sample, ChunkSize)
+	for i := range samples {
+		samples[i] = utils.Sample{Marks: marks[0][i], Label: labels[0][i]}
+	}
+	salt, err := NewLabelSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, count, public, err := ProvePrivateLabelChunk(keys, testModel.w, testModel.b, samples, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 24 {
+		t.Errorf("count %d, want 24", count)
+	}
+	if err := keys.Verify(proof, public); err != nil {
+		t.Fatal(err)
+	}
+
+	// the public witness is [X..., LabelCommitment, Count, ModelCommitment]:
+	// the labels are not in it
+	if n := reflect.ValueOf(public.Vector()).Len(); n != ChunkSize+3 {
+		t.Fatalf("%d public values, want %d", n, ChunkSize+3)
+	}
+	for i, want := range []*big.Int{LabelCommitment(labels[0], salt), big.NewInt(24), testCommitment()} {
+		if got, err := publicElement(public, ChunkSize+i); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("public value %d = %v, %v; want %v", ChunkSize+i, got, err, want)
+		}
+	}
+
+	if _, _, _, err := ProvePrivateLabelChunk(keys, testModel.w, testModel.b, samples[1:], salt); !errors.Is(err, ErrWitness) {
+		t.Errorf("short chunk: %v, want ErrWitness", err)
+	}
+}
@@ -0,0 +1,61 @@
+package lib
+
+import "fmt"
+
+// ChunkLayout is how the accuracy proof covers a dataset of TotalSamples:
+// Chunks ChunkSize chunks starting at sample First (0-based), covering
+// Samples real samples followed by Padding padding samples in the last chunk.
+type ChunkLayout struct {
+	TotalSamples int `json:"total_samples"`
+	First        int `json:"first"`
+	Samples      int `json:"samples"`
+	Chunks       int `json:"chunks"`
+	Padding      int `json:"padding"`
+}
+
+// PlanChunks returns the layout with the fewest chunks for n samples. Up to
+// numChunks*ChunkSize samples are all covered, by ceil(n/ChunkSize) chunks
+// whose last one is padded; the aggregator counts the chunks it is not
+// given as 0. Larger datasets are covered by the most recent numChunks
+// complete chunks, aligned to absolute sample positions so that appending
+// samples leaves earlier chunks (and their cache keys) unchanged; samples
+// before them and after the last complete chunk are not covered.
+func PlanChunks(n int) (ChunkLayout, error) {
+	if n < 1 {
+		return ChunkLayout{}, fmt.Errorf("accuracy proof needs at least one sample")
+	}
+	if n >= numChunks*ChunkSize {
+		return ChunkLayout{
+			TotalSamples: n,
+			First:        (n/ChunkSize - numChunks) * ChunkSize,
+			Samples:      numChunks * ChunkSize,
+			Chunks:       numChunks,
+		}, nil
+	}
+	chunks := chunksFor(n, ChunkSize)
+	return ChunkLayout{TotalSamples: n, Samples: n, Chunks: chunks, Padding: chunks*ChunkSize - n}, nil
+}
+
+func (l ChunkLayout) String() string {
+	s := fmt.Sprintf("%d chunk(s) of %d over samples %d-%d of %d", l.Chunks, ChunkSize, l.First+1, l.First+l.Samples, l.TotalSamples)
+	if l.Padding > 0 {
+		s += fmt.Sprintf(", last chunk padded with %d", l.Padding)
+	}
+	return s
+}
+
+// chunk returns the marks and labels of chunk i of the layout over marks
+// and labels, padded for model bias b. A padding sample is a mark of 0
+// labelled against BiasLabel(b), the prediction at X = 0, so it never
+// counts as correct.
+func (l ChunkLayout) chunk(i int, b float64, marks []float64, labels []int) ([]float64, []int) {
+	start := l.First + i*ChunkSize
+	end := min(start+ChunkSize, l.First+l.Samples)
+	chunkMarks := append(make([]float64, 0, ChunkSize), marks[start:end]...)
+	chunkLabels := append(make([]int, 0, ChunkSize), labels[start:end]...)
+	for len(chunkMarks) < ChunkSize {
+		chunkMarks = append(chunkMarks, 0)
+		chunkLabels = append(chunkLabels, 1-BiasLabel(b))
+	}
+	return chunkMarks, chunkLabels
+}
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlanChunks(t *testing.T) {
+	tests := []struct {
+		n    int
+		want ChunkLayout
+	}{
+		{1, ChunkLayout{TotalSamples: 1, Samples: 1, Chunks: 1, Padding: 24}},
+		{24, ChunkLayout{TotalSamples: 24, Samples: 24, Chunks: 1, Padding: 1}},
+		{25, ChunkLayout{TotalSamples: 25, Samples: 25, Chunks: 1}},
+		{26, ChunkLayout{TotalSamples: 26, Samples: 26, Chunks: 2, Padding: 24}},
+		{40, ChunkLayout{TotalSamples: 40, Samples: 40, Chunks: 2, Padding: 10}},
+		{99, ChunkLayout{TotalSamples: 99, Samples: 99, Chunks: 4, Padding: 1}},
+		{100, ChunkLayout{TotalSamples: 100, Samples: 100, Chunks: 4}},
+		// past four chunks: the latest complete ones, at absolute positions
+		{124, ChunkLayout{TotalSamples: 124, Samples: 100, Chunks: 4}},
+		{125, ChunkLayout{TotalSamples: 125, First: 25, Samples: 100, Chunks: 4}},
+		{260, ChunkLayout{TotalSamples: 260, First: 150, Samples: 100, Chunks: 4}},
+	}
+	for _, tt := range tests {
+		got, err := PlanChunks(tt.n)
+		if err != nil {
+			t.Fatalf("%d samples: %v", tt.n, err)
+		}
+		if got != tt.want {
+			t.Errorf("%d samples: %+v, want %+v", tt.n, got, tt.want)
+		}
+		if got.Samples+got.Padding != got.Chunks*ChunkSize || got.Padding >= ChunkSize {
+			t.Errorf("%d samples: %+v pads more than the last chunk", tt.n, got)
+		}
+	}
+	if _, err := PlanChunks(0); err == nil {
+		t.Error("PlanChunks(0) succeeded")
+	}
+	layout, _ := PlanChunks(40)
+	if got, want := layout.String(), "2 chunk(s) of 25 over samples 1-40 of 40, last chunk padded with 10"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestChunkLayoutChunk(t *testing.T) {
+	marks, labels := testDataset()
+	all, allLabels := slices.Concat(marks...), slices.Concat(labels...)
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+
+	for _, n := range []int{10, 40, 100} {
+		layout, err := PlanChunks(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var covered []float64
+		total := 0
+		for i := range layout.Chunks {
+			m, l := layout.chunk(i, testModel.b, all[:n], allLabels[:n])
+			if len(m) != ChunkSize || len(l) != ChunkSize {
+				t.Fatalf("%d samples, chunk %d: %d marks and %d labels", n, i, len(m), len(l))
+			}
+			covered = append(covered, m...)
+			total += chunkCount(wScaled, bScaled, quantizeMarks(m), l, false)
+		}
+		if !slices.Equal(covered[:n], all[:n]) {
+			t.Errorf("%d samples: chunks cover %v", n, covered[:n])
+		}
+		// padding never counts as correct
+		if want := chunkCount(wScaled, bScaled, quantizeMarks(all[:n]), allLabels[:n], false); total != want {
+			t.Errorf("%d samples: %d correct across the chunks, want %d", n, total, want)
+		}
+	}
+}
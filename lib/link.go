@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+
+	bls377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	blsfr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// Positions of Z in the public witness vectors: LinearCircuit exposes [X, Z,
+// ModelCommitment] and SigmoidCircuit exposes [Z, Label], in struct field
+// order.
+const (
+	linearPublicZ  = 1
+	sigmoidPublicZ = 0
+)
+
+// CheckPublicLink checks that a linear proof and a sigmoid proof are about
+// the same Z. Each proof only verifies against its own public witness, so
+// without this check a valid linear proof could be paired with a valid
+// sigmoid proof for an unrelated Z.
+func CheckPublicLink(linearPublic, sigmoidPublic witness.Witness) error {
+	linearZ, err := publicElement(linearPublic, linearPublicZ)
+	if err != nil {
+		return fmt.Errorf("%w: linear: %w", ErrPublicLink, err)
+	}
+	sigmoidZ, err := publicElement(sigmoidPublic, sigmoidPublicZ)
+	if err != nil {
+		return fmt.Errorf("%w: sigmoid: %w", ErrPublicLink, err)
+	}
+	if linearZ.Cmp(sigmoidZ) != 0 {
+		return fmt.Errorf("%w: linear Z %s != sigmoid Z %s", ErrPublicLink, linearZ.String(), sigmoidZ.String())
+	}
+	return nil
+}
+
+// publicElement returns element i of a public witness on any supported
+// curve; a negative i counts from the end.
+func publicElement(w witness.Witness, i int) (*big.Int, error) {
+	var n int
+	var elem func(int) *big.Int
+	switch vec := w.Vector().(type) {
+	case fr.Vector:
+		n, elem = len(vec), func(i int) *big.Int { return vec[i].BigInt(new(big.Int)) }
+	case blsfr.Vector:
+		n, elem = len(vec), func(i int) *big.Int { return vec[i].BigInt(new(big.Int)) }
+	case bls377fr.Vector:
+		n, elem = len(vec), func(i int) *big.Int { return vec[i].BigInt(new(big.Int)) }
+	default:
+		return nil, fmt.Errorf("unexpected witness vector type %T", w.Vector())
+	}
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return nil, fmt.Errorf("public witness has %d elements, need index %d", n, i)
+	}
+	return elem(i), nil
+}
@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// linkWitnesses returns the public linear witness for testModel at marks x
+// and the public sigmoid witness for z on curve.
+func linkWitnesses(t *testing.T, curve ecc.ID, x float64, z *big.Int) (linear, sigmoid witness.Witness) {
+	t.Helper()
+	field := curve.ScalarField()
+	fullLinear, err := LinearWitness(field, testModel.w, testModel.b, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fullSigmoid, err := sigmoidWitness(field, z, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linear, err = fullLinear.Public(); err != nil {
+		t.Fatal(err)
+	}
+	if sigmoid, err = fullSigmoid.Public(); err != nil {
+		t.Fatal(err)
+	}
+	return linear, sigmoid
+}
+
+func TestCheckPublicLink(t *testing.T) {
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+	z := LinearZ(wScaled, bScaled, 30)
+	tests := []struct {
+		name     string
+		curve    ecc.ID
+		sigmoidZ *big.Int
+		linked   bool
+	}{
+		{"same Z", ecc.BN254, z, true},
+		{"same Z on BLS12-381", ecc.BLS12_381, z, true},
+		{"other sample's Z", ecc.BN254, LinearZ(wScaled, bScaled, 31), false},
+		{"Z off by one LSB", ecc.BN254, new(big.Int).Add(z, big.NewInt(1)), false},
+		{"negated Z", ecc.BN254, new(big.Int).Neg(z), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linear, sigmoid := linkWitnesses(t, tt.curve, 30, tt.sigmoidZ)
+			err := CheckPublicLink(linear, sigmoid)
+			if tt.linked && err != nil {
+				t.Fatalf("rejected: %v", err)
+			}
+			if !tt.linked && !errors.Is(err, ErrPublicLink) {
+				t.Fatalf("err = %v, want ErrPublicLink", err)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,254 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// LUTConfig identifies a sigmoid lookup table: Q-format of the index, of the
+// value, and the largest |z| covered.
+type LUTConfig struct {
+	InputPrecision  int
+	OutputPrecision int
+	MaxInput        int
+	// InterpolationSteps, if above 1, keeps only every InterpolationSteps-th
+	// index and interpolates linearly in between, shrinking the table by
+	// that factor for a few constraints per lookup. It must be a power of
+	// two that divides MaxInput << InputPrecision; 0 and 1 keep every index.
+	InterpolationSteps int
+}
+
+// DefaultLUTConfig returns the configuration the circuits are compiled
+// with. It is built from constants on every call, so no caller can change
+// it for the rest of the process.
+func DefaultLUTConfig() LUTConfig {
+	return LUTConfig{
+		InputPrecision:  inputPrecision,
+		OutputPrecision: outputPrecision,
+		MaxInput:        MaxInput,
+	}
+}
+
+// Size returns the number of table entries, covering indices [0, MaxInput] in
+// the input Q-format inclusive, every InterpolationSteps-th.
+func (c LUTConfig) Size() int {
+	return c.MaxInput<<c.InputPrecision/c.steps() + 1
+}
+
+func (c LUTConfig) steps() int {
+	return max(c.InterpolationSteps, 1)
+}
+
+func (c LUTConfig) checkInterpolation() error {
+	s := c.steps()
+	if s&(s-1) != 0 || (c.MaxInput<<c.InputPrecision)%s != 0 {
+		return fmt.Errorf("sigmoid LUT interpolation steps %d must be a power of two dividing %d", s, c.MaxInput<<c.InputPrecision)
+	}
+	return nil
+}
+
+func (c LUTConfig) fileName() string {
+	if c.steps() > 1 {
+		return fmt.Sprintf("sigmoid_lut_i%d_o%d_m%d_s%d.bin", c.InputPrecision, c.OutputPrecision, c.MaxInput, c.steps())
+	}
+	return fmt.Sprintf("sigmoid_lut_i%d_o%d_m%d.bin", c.InputPrecision, c.OutputPrecision, c.MaxInput)
+}
+
+// ComputeSigmoidTable returns sigmoid(i / 2^InputPrecision) in the output
+// Q-format, truncated, for every table index i, a multiple of
+// InterpolationSteps.
+func ComputeSigmoidTable(cfg LUTConfig) []int64 {
+	table := make([]int64, cfg.Size())
+	for j := range table {
+		i := j * cfg.steps()
+		x := float64(i) / float64(int64(1)<<cfg.InputPrecision)
+		y := 1.0 / (1.0 + math.Exp(-x))
+		table[j] = int64(y * float64(int64(1)<<cfg.OutputPrecision))
+	}
+	return table
+}
+
+// InterpolatedSigmoid returns the sigmoid of the input-format index i in
+// [0, MaxInput << InputPrecision] as the circuits compute it from table, a
+// ComputeSigmoidTable(cfg): the entry itself, or with InterpolationSteps
+// the linear interpolation of its neighbouring entries, in the output
+// Q-format scaled by InterpolationSteps.
+func InterpolatedSigmoid(cfg LUTConfig, table []int64, i int) int64 {
+	s := cfg.steps()
+	j, frac := i/s, int64(i%s)
+	if frac == 0 {
+		return table[j] * int64(s)
+	}
+	return table[j]*int64(s) + (table[j+1]-table[j])*frac
+}
+
+// QuantizedSigmoid returns the sigmoid of a Q32 z exactly as the sigmoid
+// circuits compute it from table, a ComputeSigmoidTable(cfg): z floored to
+// the input Q-format, |z| clamped to MaxInput, InterpolatedSigmoid and the
+// symmetry sigmoid(-z) = 1 - sigmoid(z). Like InterpolatedSigmoid it is in
+// the output Q-format scaled by InterpolationSteps.
+func QuantizedSigmoid(cfg LUTConfig, table []int64, z *big.Int) int64 {
+	zIn := RescaleInt(z, Precision, uint(cfg.InputPrecision))
+	maxIndex := big.NewInt(int64(cfg.MaxInput) << cfg.InputPrecision)
+	abs := new(big.Int).Abs(zIn)
+	if abs.Cmp(maxIndex) > 0 {
+		abs = maxIndex
+	}
+
+	v := InterpolatedSigmoid(cfg, table, int(abs.Int64()))
+	if zIn.Sign() < 0 {
+		return int64(cfg.steps())<<cfg.OutputPrecision - v
+	}
+	return v
+}
+
+// SigmoidTableMaxError returns the largest |InterpolatedSigmoid - sigmoid|
+// over every input-format index of cfg, as a probability.
+func SigmoidTableMaxError(cfg LUTConfig) float64 {
+	table := ComputeSigmoidTable(cfg)
+	scale := float64(int64(cfg.steps()) << cfg.OutputPrecision)
+	worst := 0.0
+	for i := 0; i <= cfg.MaxInput<<cfg.InputPrecision; i++ {
+		x := float64(i) / float64(int64(1)<<cfg.InputPrecision)
+		err := math.Abs(float64(InterpolatedSigmoid(cfg, table, i))/scale - 1/(1+math.Exp(-x)))
+		worst = max(worst, err)
+	}
+	return worst
+}
+
+// lutMagic starts a sigmoid table file written by LoadSigmoidTable,
+// followed by lutFormatVersion, the SHA-256 of the table's LUTConfig, the
+// SHA-256 of the entries and the entries themselves, little-endian int64s.
+const (
+	lutMagic         = "ZKLRLUT"
+	lutFormatVersion = 1
+)
+
+// configSum identifies c in a table file's header; InterpolationSteps 0
+// and 1 are the same table.
+func (c LUTConfig) configSum() [sha256.Size]byte {
+	return sha256.Sum256(fmt.Appendf(nil, "i%d o%d m%d s%d", c.InputPrecision, c.OutputPrecision, c.MaxInput, c.steps()))
+}
+
+// LoadSigmoidTable returns the table for cfg from dir, computing it and
+// writing it there if dir does not hold it. A file is only trusted when its
+// header records cfg and its length and checksum match, so a truncated,
+// corrupted or stale file is recomputed and rewritten, atomically, rather
+// than read. The checksum catches accidents, not edits: a table changed on
+// purpose changes the ConfigHash of every circuit compiled with it, so
+// their caches are set up again for it rather than proving with a table
+// their keys were not made for. The returned table is valid even when the
+// error reports that the cache could not be written.
+func LoadSigmoidTable(dir string, cfg LUTConfig) ([]int64, error) {
+	file := filepath.Join(dir, cfg.fileName())
+	if data, err := os.ReadFile(file); err == nil {
+		if table, ok := decodeSigmoidTable(cfg, data); ok {
+			return table, nil
+		}
+	}
+
+	table := ComputeSigmoidTable(cfg)
+	err := writeFileAtomic(file, func(w io.Writer) error {
+		_, err := w.Write(encodeSigmoidTable(cfg, table))
+		return err
+	})
+	if err != nil {
+		return table, fmt.Errorf("writing sigmoid table cache %s: %w", file, err)
+	}
+	return table, nil
+}
+
+// encodeSigmoidTable returns table, computed for cfg, in the file format
+// described at lutMagic.
+func encodeSigmoidTable(cfg LUTConfig, table []int64) []byte {
+	entries := make([]byte, 8*len(table))
+	for i, v := range table {
+		binary.LittleEndian.PutUint64(entries[8*i:], uint64(v))
+	}
+	config, sum := cfg.configSum(), sha256.Sum256(entries)
+	data := append([]byte(lutMagic), lutFormatVersion)
+	data = append(append(data, config[:]...), sum[:]...)
+	return append(data, entries...)
+}
+
+// decodeSigmoidTable returns the table in data, or false unless data is a
+// complete, intact table file for cfg.
+func decodeSigmoidTable(cfg LUTConfig, data []byte) ([]int64, bool) {
+	header := len(lutMagic) + 1 + 2*sha256.Size
+	if len(data) != header+8*cfg.Size() || string(data[:len(lutMagic)]) != lutMagic || data[len(lutMagic)] != lutFormatVersion {
+		return nil, false
+	}
+	config, sum := cfg.configSum(), data[len(lutMagic)+1+sha256.Size:header]
+	entries := data[header:]
+	if !bytes.Equal(data[len(lutMagic)+1:len(lutMagic)+1+sha256.Size], config[:]) {
+		return nil, false
+	}
+	if got := sha256.Sum256(entries); !bytes.Equal(got[:], sum) {
+		return nil, false
+	}
+	table := make([]int64, cfg.Size())
+	for i := range table {
+		table[i] = int64(binary.LittleEndian.Uint64(entries[8*i:]))
+	}
+	return table, true
+}
+
+// SaturationCount returns how many marks give a |z| beyond cfg.MaxInput,
+// where the sigmoid circuits clamp the lookup to the last table entry. The
+// 0.5 decision is unaffected by clamping, but a high count means the table
+// does not cover the model's z range and probabilities near the edge are lost.
+func SaturationCount(cfg LUTConfig, w, b float64, marks []float64) int {
+	wScaled := NewScaled(w)
+	bScaled := NewScaled(b)
+	maxIndex := big.NewInt(int64(cfg.MaxInput) << cfg.InputPrecision)
+
+	n := 0
+	for _, x := range marks {
+		zIn := RescaleInt(LinearZ(wScaled, bScaled, x), Precision, uint(cfg.InputPrecision))
+		if zIn.CmpAbs(maxIndex) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// maxInputHeadroomPercent is how far RecommendMaxInput exceeds the largest
+// |z| seen, so samples slightly beyond the dataset's range still fall inside
+// the table.
+const maxInputHeadroomPercent = 25
+
+// RecommendMaxInput returns the smallest MaxInput, at least 1, whose table
+// covers the largest |z| of model (w, b) over samples, in the input Q-format
+// as the sigmoid circuits index the LUT, plus maxInputHeadroomPercent. A table that
+// large saturates none of the samples, while a larger one only adds entries
+// (MaxInput << InputPrecision + 1 of them) that the dataset never looks up.
+func RecommendMaxInput(w, b float64, samples []utils.Sample) int {
+	wScaled := NewScaled(w)
+	bScaled := NewScaled(b)
+	maxAbs := new(big.Int)
+	for _, s := range samples {
+		zIn := RescaleInt(LinearZ(wScaled, bScaled, s.Marks), Precision, inputPrecision)
+		if zIn.CmpAbs(maxAbs) > 0 {
+			maxAbs.Abs(zIn)
+		}
+	}
+
+	// ceil(maxAbs * (100 + headroom) / (100 << inputPrecision)): the
+	// covered |z| in whole units, rounded up
+	m := new(big.Int).Mul(maxAbs, big.NewInt(100+maxInputHeadroomPercent))
+	den := big.NewInt(100 << inputPrecision)
+	m.Add(m, den).Sub(m, big.NewInt(1)).Div(m, den)
+	if !m.IsInt64() || m.Int64() > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return max(1, int(m.Int64()))
+}
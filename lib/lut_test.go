@@ -0,0 +1,237 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestLoadSigmoidTable(t *testing.T) {
+	cfg := DefaultLUTConfig()
+	dir := t.TempDir()
+	want := ComputeSigmoidTable(cfg)
+	file := filepath.Join(dir, cfg.fileName())
+
+	if _, err := LoadSigmoidTable(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, encodeSigmoidTable(cfg, want)) {
+		t.Fatal("cache file is not the encoded table")
+	}
+
+	// a well-formed file is read, not recomputed: an entry changed with
+	// its checksum comes back as it is in the file
+	rewritten := slices.Clone(want)
+	rewritten[100]++
+	corrupted := bytes.Clone(written)
+	corrupted[len(corrupted)-8*(len(want)-100)]++
+	entries := written[len(written)-8*len(want):]
+	other := LUTConfig{InputPrecision: 10, OutputPrecision: 16, MaxInput: 8, InterpolationSteps: 2}
+	otherHeader := encodeSigmoidTable(other, want)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		table []int64
+	}{
+		{"intact", written, want},
+		{"rewritten with its checksum", encodeSigmoidTable(cfg, rewritten), rewritten},
+		{"entry corrupted", corrupted, want},
+		{"truncated", written[:len(written)-8], want},
+		{"trailing bytes", append(bytes.Clone(written), 0), want},
+		{"another config's header", otherHeader, want},
+		{"headerless entries", entries, want},
+		{"empty", nil, want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(file, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			table, err := LoadSigmoidTable(dir, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(table, tt.table) {
+				t.Error("unexpected table")
+			}
+			// a file that is not trusted is replaced by the computed table
+			wantFile := tt.data
+			if slices.Equal(tt.table, want) {
+				wantFile = written
+			}
+			if got, _ := os.ReadFile(file); !bytes.Equal(got, wantFile) {
+				t.Error("cache file not as expected")
+			}
+		})
+	}
+
+	// a cache that cannot be written still yields the table
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	table, err := LoadSigmoidTable(filepath.Join(blocker, "cache"), cfg)
+	if err == nil || !slices.Equal(table, want) {
+		t.Errorf("unwritable cache: error %v, table computed = %v", err, slices.Equal(table, want))
+	}
+}
+
+func TestSigmoidTableCacheKeyedByConfig(t *testing.T) {
+	dir := t.TempDir()
+	configs := []LUTConfig{
+		DefaultLUTConfig(),
+		{InputPrecision: 8, OutputPrecision: 16, MaxInput: 8},
+		{InputPrecision: 10, OutputPrecision: 12, MaxInput: 8},
+		{InputPrecision: 10, OutputPrecision: 16, MaxInput: 4},
+		{InputPrecision: 10, OutputPrecision: 16, MaxInput: 8, InterpolationSteps: 4},
+	}
+	files := map[string]bool{}
+	for _, cfg := range configs {
+		if _, err := LoadSigmoidTable(dir, cfg); err != nil {
+			t.Fatal(err)
+		}
+		files[cfg.fileName()] = true
+	}
+	if len(files) != len(configs) {
+		t.Fatalf("%d configs share %d cache files", len(configs), len(files))
+	}
+
+	// loading again, in any order, reads back each config's own table
+	for _, cfg := range slices.Backward(configs) {
+		data, err := os.ReadFile(filepath.Join(dir, cfg.fileName()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ComputeSigmoidTable(cfg)
+		if cached, ok := decodeSigmoidTable(cfg, data); !ok || !slices.Equal(cached, want) {
+			t.Fatalf("%+v: cache file does not hold ComputeSigmoidTable", cfg)
+		}
+		table, err := LoadSigmoidTable(dir, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(table, want) {
+			t.Errorf("%+v: loaded table differs from ComputeSigmoidTable", cfg)
+		}
+	}
+}
+
+func TestInterpolatedSigmoidAccuracy(t *testing.T) {
+	zs := []float64{-9, -3.3, -0.001, 0, 0.7, 2.123, 8.5}
+	full := DefaultLUTConfig()
+	fullConstraints := compiled(t, &SigmoidCircuit{}).GetNbConstraints()
+	fullError := SigmoidTableMaxError(full)
+	// truncating to Q16 alone is off by less than one output step
+	if fullError >= 1.0/(1<<outputPrecision) {
+		t.Errorf("full LUT max error %v, want below one Q16 step", fullError)
+	}
+
+	prevError := fullError
+	for _, steps := range []int{4, 16, 64, 256} {
+		t.Run(fmt.Sprint(steps), func(t *testing.T) {
+			cfg := full
+			cfg.InterpolationSteps = steps
+			if got, want := cfg.Size(), (full.Size()-1)/steps+1; got != want {
+				t.Errorf("Size() = %d, want %d", got, want)
+			}
+
+			// linear interpolation over h = steps Q10 indices is off by at
+			// most h^2/8 * max|sigmoid''| (about 0.0962), on top of the
+			// truncation
+			h := float64(steps) / (1 << inputPrecision)
+			maxErr := SigmoidTableMaxError(cfg)
+			if bound := h*h/8*0.0963 + fullError; maxErr > bound {
+				t.Errorf("max error %v exceeds the interpolation bound %v", maxErr, bound)
+			}
+			if maxErr < prevError {
+				t.Errorf("max error %v is below the finer table's %v", maxErr, prevError)
+			}
+			prevError = maxErr
+
+			ccs := compiled(t, &SigmoidCircuit{InterpolationSteps: steps, ExposeProbability: true, Probability: &SigmoidProbability{}})
+			if n := ccs.GetNbConstraints(); n >= fullConstraints {
+				t.Errorf("%d constraints, want fewer than the full LUT's %d", n, fullConstraints)
+			}
+			table := ComputeSigmoidTable(cfg)
+			for _, z := range zs {
+				p := QuantizedSigmoid(cfg, table, NewScaled(z))
+				// flooring z to Q10 moves it by under one step, at slope at most 1/4
+				if math.Abs(float64(p)/float64(int64(steps)<<outputPrecision)-1/(1+math.Exp(-z))) > maxErr+0.25/(1<<inputPrecision) {
+					t.Errorf("z %v: probability %d is off by more than the table error", z, p)
+				}
+				label := 0
+				if p >= DefaultThreshold*int64(steps) {
+					label = 1
+				}
+				assignment := func(p int64) *SigmoidCircuit {
+					return &SigmoidCircuit{Z: NewScaled(z), Label: label, Probability: &SigmoidProbability{Value: p}}
+				}
+				if err := solved(t, ccs, assignment(p)); err != nil {
+					t.Errorf("z %v: QuantizedSigmoid %d rejected: %v", z, p, err)
+				}
+				if err := solved(t, ccs, assignment(p+1)); err == nil {
+					t.Errorf("z %v: probability %d accepted", z, p+1)
+				}
+			}
+		})
+	}
+
+	if _, err := Compile(BackendPlonk, DefaultCurve, &SigmoidCircuit{InterpolationSteps: 3}); err == nil {
+		t.Error("3 interpolation steps compiled, want a power of two")
+	}
+}
+
+func TestRecommendMaxInput(t *testing.T) {
+	marks := make([]float64, MaxMarks+1)
+	for i := range marks {
+		marks[i] = float64(i)
+	}
+	tests := []struct {
+		name      string
+		w, b      float64
+		marks     []float64
+		want      int
+		saturated bool // whether the default MaxInput saturates any sample
+	}{
+		// |z| up to 0.5, and at least 1
+		{"narrow z range", 0.01, -0.5, marks, 1, false},
+		// |z| up to 10 exceeds the default 8: 12.5, rounded up
+		{"past the default", 0.2, -10, marks, 13, true},
+		// z from 10 down to -40: 50 with the headroom
+		{"test model", testModel.w, testModel.b, marks, 50, true},
+		{"bundled model", -0.85735312, 50.94705066, marks, 64, true},
+		{"single sample at z = 0", testModel.w, testModel.b, []float64{20}, 1, false},
+		{"no samples", testModel.w, testModel.b, nil, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := make([]utils.Sample, len(tt.marks))
+			for i, x := range tt.marks {
+				samples[i] = utils.Sample{Marks: x}
+			}
+			got := RecommendMaxInput(tt.w, tt.b, samples)
+			if got != tt.want {
+				t.Errorf("RecommendMaxInput = %d, want %d", got, tt.want)
+			}
+			cfg := DefaultLUTConfig()
+			if n := SaturationCount(cfg, tt.w, tt.b, tt.marks); (n > 0) != tt.saturated {
+				t.Errorf("%d samples saturate MaxInput %d, want saturated %v", n, cfg.MaxInput, tt.saturated)
+			}
+			cfg.MaxInput = got
+			if n := SaturationCount(cfg, tt.w, tt.b, tt.marks); n != 0 {
+				t.Errorf("%d samples saturate the recommended MaxInput %d", n, got)
+			}
+		})
+	}
+}
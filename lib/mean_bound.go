@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// ============================================================================
+// CIRCUIT 7: Mean Bound Circuit
+// Proves that the mean of len(X) private marks lies in the public [Lo, Hi],
+// for marks bound by a salted MiMC commitment, without revealing any mark.
+// ============================================================================
+
+// markBits bounds every mark below 2^markBits in Q32, i.e. below 256, so
+// the sum of the marks cannot wrap around the field.
+const markBits = Precision + 8
+
+type MeanBoundCircuit struct {
+	X []frontend.Variable
+	// Salt blinds Commitment; see PrivateLabelChunkCircuit.
+	Salt frontend.Variable
+
+	// Commitment is MarksCommitment(X, Salt).
+	Commitment frontend.Variable `gnark:",public"`
+	// Lo and Hi are the Q32 bounds on the mean, inclusive.
+	Lo frontend.Variable `gnark:",public"`
+	Hi frontend.Variable `gnark:",public"`
+}
+
+// NewMeanBoundCircuit returns the circuit to compile for n marks. It panics
+// if n < 1, as that is a programming error.
+func NewMeanBoundCircuit(n int) *MeanBoundCircuit {
+	if n < 1 {
+		panic(fmt.Sprintf("lib: mean bound needs at least one mark, got %d", n))
+	}
+	return &MeanBoundCircuit{X: make([]frontend.Variable, n)}
+}
+
+func (c *MeanBoundCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Salt)
+
+	sum := New(api, 0)
+	for _, x := range c.X {
+		// marks are in [0, 256), which also keeps the sum far from wrapping
+		api.ToBinary(x, markBits)
+		h.Write(x)
+		sum = sum.Add(New(api, x))
+	}
+	api.AssertIsEqual(h.Sum(), c.Commitment)
+
+	// Lo <= sum/n <= Hi without a division: n*Lo <= sum <= n*Hi. The bounds
+	// are range-checked like the marks, so both sides are below
+	// 2^sumBits and a difference is non-negative exactly when it fits in
+	// sumBits bits; a negative one is close to the field modulus. This is
+	// isLessBounded's decomposition, for a variable bound.
+	n := len(c.X)
+	sumBits := markBits + bits.Len(uint(n))
+	api.ToBinary(c.Lo, markBits)
+	api.ToBinary(c.Hi, markBits)
+	api.ToBinary(api.Sub(sum.Val, api.Mul(c.Lo, n)), sumBits)
+	api.ToBinary(api.Sub(api.Mul(c.Hi, n), sum.Val), sumBits)
+	return nil
+}
+
+// MarksCommitment hashes salt followed by the Q32 marks with MiMC, matching
+// MeanBoundCircuit compiled on DefaultCurve.
+func MarksCommitment(marks []float64, salt *big.Int) *big.Int {
+	return marksCommitment(DefaultCurve.ScalarField(), marks, salt)
+}
+
+func marksCommitment(field *big.Int, marks []float64, salt *big.Int) *big.Int {
+	values := make([]*big.Int, 0, 1+len(marks))
+	values = append(values, salt)
+	values = append(values, quantizeMarks(marks)...)
+	return mimcHashOn(curveOfField(field), values)
+}
+
+// MeanBoundWitness returns the full MeanBoundCircuit witness over field for
+// marks, whose count must match the compiled circuit, and the bounds lo and
+// hi on their mean. The bounds are scaled to Q32 like the marks, and the
+// mean compared exactly, so a mean within rounding of a bound may fall on
+// either side of it; no witness satisfies the circuit if the mean is out of
+// range or a mark or bound is outside [0, 256).
+func MeanBoundWitness(field *big.Int, marks []float64, salt *big.Int, lo, hi float64) (witness.Witness, error) {
+	if len(marks) == 0 {
+		return nil, fmt.Errorf("%w: mean bound needs at least one mark", ErrWitness)
+	}
+	assignment := NewMeanBoundCircuit(len(marks))
+	for i, x := range quantizeMarks(marks) {
+		assignment.X[i] = x
+	}
+	assignment.Salt = salt
+	assignment.Commitment = marksCommitment(field, marks, salt)
+	assignment.Lo = NewScaled(lo)
+	assignment.Hi = NewScaled(hi)
+
+	full, err := frontend.NewWitness(assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mean bound: %w", ErrWitness, err)
+	}
+	return full, nil
+}
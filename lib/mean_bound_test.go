@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMeanBoundCircuit(t *testing.T) {
+	field := DefaultCurve.ScalarField()
+	salt := big.NewInt(987654321)
+	marks := []float64{40, 50, 60, 70} // mean 55
+	ccs := compiled(t, NewMeanBoundCircuit(len(marks)))
+	lsb := math.Ldexp(1, -Precision)
+
+	tests := []struct {
+		name   string
+		marks  []float64
+		lo, hi float64
+		valid  bool
+	}{
+		{"inside", marks, 50, 60, true},
+		{"mean on both bounds", marks, 55, 55, true},
+		{"lo one LSB above the mean", marks, 55 + lsb, 60, false},
+		{"hi one LSB below the mean", marks, 50, 55 - lsb, false},
+		{"lo above hi", marks, 60, 50, false},
+		{"negative lo", marks, -1, 60, false},
+		{"full mark range", []float64{0, 0, 100, 100}, 0, 100, true},
+		{"fractional mean", []float64{0.25, 0.5, 0.75, 1}, 0.625, 0.625, true},
+		{"mark out of range", []float64{256, 0, 0, 0}, 0, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			full, err := MeanBoundWitness(field, tt.marks, salt, tt.lo, tt.hi)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ccs.IsSolved(full); (err == nil) != tt.valid {
+				t.Errorf("err = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+
+	// the same marks under another commitment or salt
+	assignment := func(commitment *big.Int, salt int64) *MeanBoundCircuit {
+		c := NewMeanBoundCircuit(len(marks))
+		for i, x := range quantizeMarks(marks) {
+			c.X[i] = x
+		}
+		c.Salt, c.Commitment, c.Lo, c.Hi = salt, commitment, NewScaled(50), NewScaled(60)
+		return c
+	}
+	if err := solved(t, ccs, assignment(MarksCommitment(marks, salt), salt.Int64())); err != nil {
+		t.Errorf("own commitment rejected: %v", err)
+	}
+	if err := solved(t, ccs, assignment(MarksCommitment([]float64{40, 50, 60, 71}, salt), salt.Int64())); err == nil {
+		t.Error("other marks' commitment accepted")
+	}
+	if err := solved(t, ccs, assignment(MarksCommitment(marks, salt), salt.Int64()+1)); err == nil {
+		t.Error("other salt accepted")
+	}
+
+	if _, err := MeanBoundWitness(field, nil, salt, 0, 100); !errors.Is(err, ErrWitness) {
+		t.Errorf("no marks: err = %v, want ErrWitness", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewMeanBoundCircuit(0) did not panic")
+		}
+	}()
+	NewMeanBoundCircuit(0)
+}
@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// ModelResult is the accuracy proof outcome for one candidate model.
+type ModelResult struct {
+	ModelPath string
+	W, B      float64
+	Result    PipelineResult
+	// Err is set when the model could not be loaded or its accuracy proof
+	// failed (including falling short of the policy); other models still run.
+	Err error
+}
+
+// RunModels proves the accuracy policy for each model file against the same
+// dataset. The chunk and aggregator circuits are set up once and their keys
+// reused for every model, since only the private W and B change.
+// cfg.ModelPath and cfg.Circuits are ignored.
+func RunModels(cfg PipelineConfig, modelPaths []string) ([]ModelResult, error) {
+	p, err := newPipeline(cfg)
+	if err != nil {
+		return nil, err
+	}
+	layout, minCorrect, err := p.accuracyPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	p.cfg.Logf("\n=== Proving Accuracy >= %d/%d for %d models ===\n", minCorrect, layout.Samples, len(modelPaths))
+	p.cfg.Logf("Chunk layout: %s\n", layout)
+	circuits, err := p.setupAccuracy(layout, minCorrect)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ModelResult, len(modelPaths))
+	for i, path := range modelPaths {
+		r := &results[i]
+		r.ModelPath = path
+		r.Result.TotalSamples = len(p.marks)
+
+		r.W, r.B, r.Err = utils.LoadModelParameters(path)
+		if r.Err != nil {
+			r.Err = fmt.Errorf("loading model: %w", r.Err)
+			p.cfg.Logf("%s: %v\n", path, r.Err)
+			continue
+		}
+
+		p.cfg.Logf("\n--- Model %s (W=%v, B=%v) ---\n", path, r.W, r.B)
+		p.modelRef = nil // each model's proofs commit to that model
+		r.Err = p.proveAccuracy(circuits, r.W, r.B, &r.Result)
+		if r.Err != nil {
+			p.cfg.Logf("%s: %v\n", path, r.Err)
+		}
+	}
+	return results, nil
+}
+
+// ExpandModelPaths turns a comma-separated list of model files and
+// directories into file paths; a directory contributes its *.txt and
+// *.json files, in name order.
+func ExpandModelPaths(spec string) ([]string, error) {
+	var paths []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		info, err := os.Stat(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, entry)
+			continue
+		}
+		var matches []string
+		for _, pattern := range []string{"*.txt", "*.json"} {
+			m, err := filepath.Glob(filepath.Join(entry, pattern))
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, m...)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no model files in %q", spec)
+	}
+	return paths, nil
+}
@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeModel writes w and b in the train_model.py format to dir/name.
+func writeModel(t *testing.T, dir, name string, w, b float64) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("Coefficient: [[%v]]\nIntercept: [%v]\n", w, b)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExpandModelPaths(t *testing.T) {
+	dir := t.TempDir()
+	b := writeModel(t, dir, "b.txt", 1, 0)
+	a := writeModel(t, dir, "a.json", 1, 0)
+	writeModel(t, dir, "notes.csv", 1, 0)
+	single := writeModel(t, t.TempDir(), "single.txt", 1, 0)
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"file", single, []string{single}, false},
+		{"directory in name order", dir, []string{a, b}, false},
+		{"mixed with spaces", single + " , " + dir, []string{single, a, b}, false},
+		{"missing file", filepath.Join(dir, "missing.txt"), nil, true},
+		{"empty", " , ", nil, true},
+		{"directory without models", t.TempDir(), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandModelPaths(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunModels(t *testing.T) {
+	if testing.Short() {
+		t.Skip("sets up the accuracy chunk circuit")
+	}
+	dir := t.TempDir()
+	dataset := writeDataset(t, dir, testChunk())
+
+	tests := []struct {
+		path    string
+		correct int
+		wantErr error
+	}{
+		// the model that labelled the dataset
+		{writeModel(t, dir, "good.txt", testModel.w, testModel.b), ChunkSize, nil},
+		// boundary at 60 instead of 20: marks 22 to 58 are misclassified
+		{writeModel(t, dir, "shifted.txt", testModel.w, 30), ChunkSize - 10, ErrProve},
+		{filepath.Join(dir, "missing.txt"), 0, os.ErrNotExist},
+	}
+	paths := make([]string, len(tests))
+	for i, tt := range tests {
+		paths[i] = tt.path
+	}
+
+	var log strings.Builder
+	results, err := RunModels(PipelineConfig{
+		DatasetPath: dataset,
+		CacheDir:    filepath.Join(dir, "cache"),
+		Backend:     BackendGroth16,
+		MinAccuracy: 0.8,
+		Logf:        func(format string, args ...any) { fmt.Fprintf(&log, format, args...) },
+	}, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("%d results for %d models", len(results), len(tests))
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.ModelPath != tt.path {
+			t.Errorf("result %d is for %s, want %s", i, r.ModelPath, tt.path)
+		}
+		if tt.wantErr == nil && r.Err != nil {
+			t.Errorf("%s: %v", tt.path, r.Err)
+		}
+		if tt.wantErr != nil && !errors.Is(r.Err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.path, r.Err, tt.wantErr)
+		}
+		if r.Result.TotalCorrect != tt.correct || r.Result.AccuracyVerified != (tt.wantErr == nil) {
+			t.Errorf("%s: %d correct, verified %v; want %d", tt.path, r.Result.TotalCorrect, r.Result.AccuracyVerified, tt.correct)
+		}
+	}
+	if n := strings.Count(log.String(), "Compiling and setting up"); n != 2 {
+		t.Errorf("%d setups, want one chunk and one aggregator setup for all models; log:\n%s", n, log.String())
+	}
+}
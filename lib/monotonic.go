@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// ============================================================================
+// CIRCUIT 4: Monotonicity Circuit
+// Proves, without revealing W and B, that the model's prediction moves in the
+// claimed direction between two public marks X1 < X2.
+// ============================================================================
+
+type MonotonicityCircuit struct {
+	W  frontend.Variable
+	B  frontend.Variable
+	X1 frontend.Variable `gnark:",public"`
+	X2 frontend.Variable `gnark:",public"`
+
+	// Decreasing is 1 to prove prediction(X1) >= prediction(X2) (W < 0: higher
+	// marks never increase the "fail" prediction) and 0 to prove
+	// prediction(X1) <= prediction(X2) (W >= 0). The sign of W is bound to it,
+	// so since sigmoid is monotone the claim holds for every pair of marks.
+	Decreasing frontend.Variable `gnark:",public"`
+	// ModelCommitment binds the proof to a committed model, as in
+	// LinearCircuit; without it any W of the right sign would satisfy it.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// Threshold is the compiled-in Q16 decision threshold; see SigmoidCircuit.
+	Threshold int64 `gnark:"-"`
+	// LUT optionally supplies precomputed table values; see SigmoidCircuit.
+	LUT []int64 `gnark:"-"`
+
+	table *sigmoidTable
+}
+
+// revision 2 binds the model commitment.
+func (c *MonotonicityCircuit) revision() int { return 2 }
+
+func (c *MonotonicityCircuit) Define(api frontend.API) error {
+	if c.table == nil {
+		table, err := newSigmoidTable(api, c.LUT)
+		if err != nil {
+			return err
+		}
+		c.table = table
+	}
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+	api.AssertIsBoolean(c.Decreasing)
+
+	// X1 < X2 (marks are non-negative Q32)
+	isLess := api.IsZero(api.Add(api.Cmp(c.X1, c.X2), 1))
+	api.AssertIsEqual(isLess, 1)
+
+	// Direction matches sign(W)
+	wNeg := isNegative(api, c.W)
+	api.AssertIsEqual(wNeg, c.Decreasing)
+
+	w := New(api, c.W)
+	b := New(api, c.B)
+	threshold := thresholdOrDefault(c.Threshold)
+	p1, _ := sigmoidPredict(api, c.table, w.Mul(New(api, c.X1)).Add(b).Val, threshold)
+	p2, _ := sigmoidPredict(api, c.table, w.Mul(New(api, c.X2)).Add(b).Val, threshold)
+
+	// Predictions are bits, so the only violation of p1 >= p2 is (0, 1) and
+	// of p1 <= p2 is (1, 0).
+	rising := api.Mul(api.Sub(1, p1), p2)
+	falling := api.Mul(p1, api.Sub(1, p2))
+	api.AssertIsEqual(api.Select(c.Decreasing, rising, falling), 0)
+	return nil
+}
@@ -0,0 +1,38 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMonotonicityCircuit(t *testing.T) {
+	ccs := compiled(t, &MonotonicityCircuit{})
+	w, b := NewScaled(testModel.w), NewScaled(testModel.b)
+	other := ModelCommitment(0.5, -10)
+
+	tests := []struct {
+		name       string
+		x1, x2     float64
+		decreasing int
+		commitment *big.Int
+		ok         bool
+	}{
+		{"decreasing across the boundary", 10, 30, 1, testCommitment(), true},
+		{"decreasing on one side", 50, 90, 1, testCommitment(), true},
+		{"wrong direction for W < 0", 10, 30, 0, testCommitment(), false},
+		{"marks not increasing", 30, 10, 1, testCommitment(), false},
+		{"equal marks", 30, 30, 1, testCommitment(), false},
+		{"other model's commitment", 10, 30, 1, other, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := solved(t, ccs, &MonotonicityCircuit{
+				W: w, B: b, X1: NewScaled(tt.x1), X2: NewScaled(tt.x2),
+				Decreasing: tt.decreasing, ModelCommitment: tt.commitment,
+			})
+			if (err == nil) != tt.ok {
+				t.Errorf("solved = %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,723 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// CircuitSet selects which stages RunPipeline executes.
+type CircuitSet uint
+
+const (
+	// CircuitsPerSample proves every sample with the linear and sigmoid circuits.
+	CircuitsPerSample CircuitSet = 1 << iota
+	// CircuitsInference proves every sample with the combined inference circuit.
+	CircuitsInference
+	// CircuitsAccuracy proves accuracy with the chunk and aggregator circuits.
+	CircuitsAccuracy
+
+	CircuitsAll = CircuitsPerSample | CircuitsInference | CircuitsAccuracy
+)
+
+var circuitSetNames = map[string]CircuitSet{
+	"samples":   CircuitsPerSample,
+	"inference": CircuitsInference,
+	"accuracy":  CircuitsAccuracy,
+	"all":       CircuitsAll,
+}
+
+// ParseCircuitSet parses a comma-separated list of stage names
+// ("samples", "inference", "accuracy" or "all").
+func ParseCircuitSet(s string) (CircuitSet, error) {
+	var set CircuitSet
+	for _, name := range strings.Split(s, ",") {
+		c, ok := circuitSetNames[strings.TrimSpace(name)]
+		if !ok {
+			return 0, fmt.Errorf("unknown circuit set %q (want samples, inference, accuracy or all)", name)
+		}
+		set |= c
+	}
+	return set, nil
+}
+
+// Has reports whether every stage in c is selected.
+func (s CircuitSet) Has(c CircuitSet) bool {
+	return s&c == c
+}
+
+// PipelineConfig configures RunPipeline.
+type PipelineConfig struct {
+	DatasetPath string
+	ModelPath   string
+	CacheDir    string
+	// Labels maps the dataset's labels to the model's classes as it is
+	// loaded; nil means utils.DefaultLabelMapping (1 = Fail, 0 = Pass).
+	Labels *utils.LabelMapping
+	// Concurrency is the number of samples proved in parallel; <= 1 is sequential.
+	Concurrency int
+	// Circuits selects the stages to run; zero means CircuitsAll.
+	Circuits CircuitSet
+	// Progress is called while proving samples; nil disables it.
+	Progress ProgressFunc
+	// Logf receives human-readable progress messages; nil discards them.
+	Logf func(format string, args ...any)
+	// Verbosity selects which messages reach Logf and whether Progress is
+	// called; zero means VerbosityNormal.
+	Verbosity Verbosity
+	// ChunkCache holds accuracy chunk proofs; nil uses CacheDir/chunks.
+	ChunkCache *ChunkCache
+	// IncludeBorderline counts samples near the decision boundary in the
+	// accuracy proof instead of skipping them.
+	IncludeBorderline bool
+	// MinAccuracy is the accuracy policy in (0, 1] the aggregator proves;
+	// zero means DefaultMinCorrect out of 100 (0.97).
+	MinAccuracy float64
+	// Curve is the curve circuits are compiled and proved on; zero means
+	// DefaultCurve. Keys for other curves are cached in CacheDir/<curve>.
+	Curve ecc.ID
+	// Backend is the proof system every stage uses; zero means BackendPlonk.
+	// Groth16 keys and chunk proofs are cached in <key dir>/groth16.
+	Backend Backend
+	// SRS, if set, is the canonical KZG SRS every PLONK setup uses instead
+	// of unsafekzg's development SRS (see LoadSRS). It must be for Curve and
+	// hold at least SRSSize points for every circuit set up; a cache set up
+	// against another SRS is set up again. Groth16 does not use one.
+	SRS kzg.SRS
+	// Shuffle permutes the dataset with utils.ShuffleDataset(ShuffleSeed)
+	// after loading, so every stage, and in particular the accuracy chunks,
+	// sees the same reproducible order instead of the file's.
+	Shuffle     bool
+	ShuffleSeed int64
+	// TestFraction, if non-zero, restricts every stage to the test split of
+	// utils.TrainTestSplit(TestFraction, SplitSeed), taken after any
+	// shuffle, so the accuracy proof covers only held-out samples.
+	TestFraction float64
+	SplitSeed    int64
+	// CheckpointDir, if set, receives each sample's proofs as they are
+	// generated. With Resume, samples already checkpointed for the same model
+	// and circuits are not proved again.
+	CheckpointDir string
+	Resume        bool
+	// Context stops the per-sample stage from starting new proofs once it is
+	// done; nil never cancels. Proofs finished by then stay checkpointed.
+	Context context.Context
+	// MaxDuration, if positive, bounds the per-sample stage's proving: once
+	// it has passed no new proofs are started, and the proofs finished by
+	// then are verified and reported as a partial run.
+	MaxDuration time.Duration
+	// ProveBudget pads every per-sample and inference proof to at least this
+	// long, so proving time does not leak the private inputs; see
+	// CircuitKeys.ProveBudget. Zero does not pad.
+	ProveBudget time.Duration
+	// SkipLinear proves every sample with the sigmoid circuit alone, on z
+	// computed off-chain (the z of utils.ComputeZ), halving the per-sample
+	// proofs. The sigmoid proof then shows only that its public Z classifies
+	// as the label: nothing proves that Z is W*X + B for the committed
+	// model, so use it only when the verifier trusts that step or it is
+	// proven elsewhere (e.g. by the inference or accuracy stages).
+	SkipLinear bool
+}
+
+// Verbosity is how much a pipeline run reports while it works. The final
+// PipelineResult is the same at every level.
+type Verbosity int
+
+const (
+	// VerbosityQuiet reports nothing; callers print only the result.
+	VerbosityQuiet Verbosity = iota - 1
+	// VerbosityNormal reports stage headers, setup, progress, per-chunk
+	// counts, warnings and totals.
+	VerbosityNormal
+	// VerbosityVerbose also reports every sample: its verified proofs,
+	// failures and float/circuit disagreements.
+	VerbosityVerbose
+)
+
+// PipelineResult summarises a pipeline run. Fields of stages that did not
+// run are left zero.
+type PipelineResult struct {
+	TotalSamples int `json:"total_samples"`
+	// Saturated counts samples whose z lies outside the sigmoid LUT's range
+	// (see SaturationCount).
+	Saturated int `json:"saturated"`
+	// RecommendedMaxInput is RecommendMaxInput for the model and dataset.
+	RecommendedMaxInput int `json:"recommended_max_input"`
+	// Disagreements lists the samples whose float64 and quantized
+	// predictions differ (see DisagreementReport).
+	Disagreements []Disagreement `json:"disagreements"`
+
+	// Per-sample linear + sigmoid proofs
+	ProofsGenerated int `json:"proofs_generated"`
+	// Resumed counts the proofs among ProofsGenerated that were read from the
+	// checkpoint instead of proved; Timings covers only the others.
+	Resumed  int           `json:"resumed"`
+	Verified int           `json:"verified"`
+	Timings  SampleTimings `json:"timings"`
+	// VerifyTime is the wall-clock time spent verifying the sample proofs,
+	// Concurrency at a time.
+	VerifyTime time.Duration `json:"verify_time"`
+	// Samples is the outcome of every sample attempted, in sample order.
+	Samples []SampleOutcome `json:"samples"`
+	// Partial is set when MaxDuration passed before every sample was
+	// attempted; ProofsGenerated then covers only the attempted ones.
+	Partial bool `json:"partial"`
+
+	// Combined inference proofs
+	InferenceVerified int `json:"inference_verified"`
+
+	// Chunked accuracy proof
+	ChunkCounts      []int          `json:"chunk_counts"`
+	ChunksReused     int            `json:"chunks_reused"`
+	AccuracySamples  int            `json:"accuracy_samples"`
+	Layout           ChunkLayout    `json:"layout"`
+	MinCorrect       int            `json:"min_correct"`
+	TotalCorrect     int            `json:"total_correct"`
+	Margin           AccuracyMargin `json:"margin"`
+	AccuracyVerified bool           `json:"accuracy_verified"`
+}
+
+// SampleOutcome is how far one sample got through the per-sample stage.
+type SampleOutcome struct {
+	SampleNum int     `json:"sample"` // 1-based position in the samples
+	Mark      float64 `json:"mark"`
+	Label     int     `json:"label"` // as in the dataset, before Labels
+	Proved    bool    `json:"proved"`
+	Verified  bool    `json:"verified"`
+	// Error is why the sample was not proved or not verified.
+	Error string `json:"error,omitempty"`
+}
+
+// AccuracyMargin reports how far the proven total is from the aggregator
+// threshold; Margin is negative when the accuracy policy is not met.
+type AccuracyMargin struct {
+	Total     int `json:"total"`
+	Threshold int `json:"threshold"`
+	Margin    int `json:"margin"`
+}
+
+type pipeline struct {
+	cfg    PipelineConfig
+	marks  []float64
+	labels []int // model classes, mapped from the dataset's by cfg.Labels
+	w, b   float64
+	lut    []int64
+
+	// noCacheWrites is set when the cache directory could not be created
+	// or written to; existing caches are still read.
+	noCacheWrites bool
+
+	// detailf is cfg.Logf for per-sample messages, which only
+	// VerbosityVerbose reports; cfg.Logf itself is silenced when quiet.
+	detailf func(format string, args ...any)
+
+	// modelRef is the first proof's public witness with a ModelCommitment;
+	// every later one must match it.
+	modelRef witness.Witness
+}
+
+// checkModel checks that public commits to the same W and B as the proofs
+// already checked for the current model.
+func (p *pipeline) checkModel(public witness.Witness) error {
+	if p.modelRef == nil {
+		p.modelRef = public
+		return nil
+	}
+	return CheckSameModel(p.modelRef, public)
+}
+
+// RunPipeline loads the dataset and model, sets up (or loads from cache) the
+// selected circuits, and generates and verifies their proofs.
+func RunPipeline(cfg PipelineConfig) (PipelineResult, error) {
+	p, err := newPipeline(cfg)
+	if err != nil {
+		return PipelineResult{}, err
+	}
+	p.w, p.b, err = utils.LoadModelParameters(cfg.ModelPath)
+	if err != nil {
+		return PipelineResult{}, fmt.Errorf("loading model: %w", err)
+	}
+	cfg = p.cfg
+
+	cfg.Logf("Loaded %d test samples\n", len(p.marks))
+	result := PipelineResult{TotalSamples: len(p.marks)}
+
+	result.Saturated = SaturationCount(DefaultLUTConfig(), p.w, p.b, p.marks)
+	if len(p.marks) > 0 && float64(result.Saturated) > saturationWarnFraction*float64(len(p.marks)) {
+		cfg.Logf("Warning: %d/%d samples have |z| > %d and saturate the sigmoid LUT; consider a larger MaxInput\n",
+			result.Saturated, len(p.marks), DefaultLUTConfig().MaxInput)
+	}
+	result.RecommendedMaxInput = RecommendMaxInput(p.w, p.b, p.samples())
+	cfg.Logf("Recommended MaxInput for this model and dataset: %d (compiled with %d)\n", result.RecommendedMaxInput, DefaultLUTConfig().MaxInput)
+	if inverted, rate := utils.DetectLabelInversion(p.w, p.b, p.samples()); inverted {
+		cfg.Logf("WARNING: %.0f%% of labels disagree with the model's predictions under the label mapping %d = Fail;\n"+
+			"if this dataset uses %d = Fail, set PipelineConfig.Labels.PositiveClass to %d, or almost every proof will fail.\n",
+			rate*100, cfg.Labels.PositiveClass, 1-cfg.Labels.PositiveClass, 1-cfg.Labels.PositiveClass)
+	}
+	result.Disagreements = DisagreementReport(p.w, p.b, p.samples())
+	for _, d := range result.Disagreements {
+		p.detailf("Sample %d (marks=%v): float z=%.3g predicts %d but circuit z=%.3g predicts %d\n",
+			d.SampleNum, d.Marks, d.Z, d.FloatPrediction, d.QuantizedZ, d.CircuitPrediction)
+	}
+	cfg.Logf("\n")
+
+	if cfg.Circuits.Has(CircuitsPerSample) {
+		if err := p.runSamples(&result); err != nil {
+			return result, err
+		}
+	}
+	if cfg.Circuits.Has(CircuitsInference) {
+		if err := p.runInference(&result); err != nil {
+			return result, err
+		}
+	}
+	if cfg.Circuits.Has(CircuitsAccuracy) {
+		if err := p.runAccuracy(&result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// newPipeline fills in cfg's defaults and loads the dataset and sigmoid LUT.
+func newPipeline(cfg PipelineConfig) (*pipeline, error) {
+	if cfg.Circuits == 0 {
+		cfg.Circuits = CircuitsAll
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(string, ...any) {}
+	}
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
+	if cfg.Curve == ecc.UNKNOWN {
+		cfg.Curve = DefaultCurve
+	}
+	if cfg.SRS != nil {
+		if cfg.Backend != BackendPlonk {
+			return nil, fmt.Errorf("an SRS only applies to the %s backend", BackendPlonk)
+		}
+		if _, err := srsPoints(cfg.Curve, cfg.SRS); err != nil {
+			return nil, err
+		}
+	}
+	discard := func(string, ...any) {}
+	p := &pipeline{cfg: cfg, detailf: discard}
+	if cfg.Verbosity >= VerbosityVerbose {
+		p.detailf = cfg.Logf
+	}
+	if cfg.Verbosity < VerbosityNormal {
+		p.cfg.Logf = discard
+		p.cfg.Progress = nil
+	}
+	cfg = p.cfg
+
+	// the run keeps its own copy, so neither a later write through the
+	// caller's pointer nor another run can change the mapping under it
+	labels := utils.DefaultLabelMapping()
+	if cfg.Labels != nil {
+		labels = *cfg.Labels
+	}
+	p.cfg.Labels = &labels
+	cfg = p.cfg
+	if err := cfg.Labels.Check(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	p.marks, p.labels, err = loadTestData(cfg.DatasetPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading test data: %w", err)
+	}
+	for i, label := range p.labels {
+		p.labels[i] = cfg.Labels.ToClass(label)
+	}
+	if cfg.Shuffle || cfg.TestFraction != 0 {
+		if err := p.reorder(); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkCacheDir(p.keyDir()); err != nil {
+		p.noCacheWrites = true
+		cfg.Logf("Warning: cache directory %s is not writable, so nothing will be cached this run: %v\n", p.keyDir(), err)
+	}
+	p.lut, err = LoadSigmoidTable(cfg.CacheDir, DefaultLUTConfig())
+	if err != nil && !p.noCacheWrites {
+		cfg.Logf("Warning: %v\n", err)
+	}
+	return p, nil
+}
+
+// samples returns the loaded marks and labels as utils.Samples.
+func (p *pipeline) samples() []utils.Sample {
+	samples := make([]utils.Sample, len(p.marks))
+	for i := range samples {
+		samples[i] = utils.Sample{Marks: p.marks[i], Label: p.labels[i]}
+	}
+	return samples
+}
+
+// reorder applies cfg's shuffle and train/test split to the loaded samples.
+func (p *pipeline) reorder() error {
+	samples := p.samples()
+	if p.cfg.Shuffle {
+		utils.ShuffleDataset(samples, p.cfg.ShuffleSeed)
+	}
+	if p.cfg.TestFraction != 0 {
+		var err error
+		if _, samples, err = utils.TrainTestSplit(samples, p.cfg.TestFraction, p.cfg.SplitSeed); err != nil {
+			return fmt.Errorf("splitting test data: %w", err)
+		}
+	}
+	p.marks, p.labels = make([]float64, len(samples)), make([]int, len(samples))
+	for i, s := range samples {
+		p.marks[i], p.labels[i] = s.Marks, s.Label
+	}
+	return nil
+}
+
+// keyDir is where circuit caches and chunk proofs for the configured curve
+// and backend live. The sigmoid LUT depends on neither and stays in CacheDir.
+func (p *pipeline) keyDir() string {
+	dir := p.cfg.CacheDir
+	if p.cfg.Curve != DefaultCurve {
+		dir = filepath.Join(dir, p.cfg.Curve.String())
+	}
+	if p.cfg.Backend != BackendPlonk {
+		dir = filepath.Join(dir, p.cfg.Backend.String())
+	}
+	return dir
+}
+
+// circuitOptions returns the options registered circuits are built from for
+// this run, with the given aggregator policy.
+func (p *pipeline) circuitOptions(minCorrect int) CircuitOptions {
+	return CircuitOptions{LUT: p.lut, IncludeBorderline: p.cfg.IncludeBorderline, MinCorrect: minCorrect}
+}
+
+// setupCircuit is LoadOrSetup for the registered circuit name and its file in
+// the cache directory, logging what it does.
+func (p *pipeline) setupCircuit(name string, opts CircuitOptions) (*CircuitKeys, error) {
+	spec, ok := lookupCircuit(name)
+	if !ok {
+		return nil, fmt.Errorf("%s circuit: not registered", name)
+	}
+	keys, err := loadOrSetupKeys(p.cfg.Backend, p.cfg.Curve, filepath.Join(p.keyDir(), spec.CacheFile(opts)), spec.New(opts), p.cfg.SRS, !p.noCacheWrites, p.cfg.Logf)
+	if err != nil {
+		return nil, fmt.Errorf("%s circuit: %w", name, err)
+	}
+	if p.cfg.Backend == BackendPlonk {
+		p.cfg.Logf("%s circuit: %d constraints, SRS %d points, vk %s\n", name, keys.CCS.GetNbConstraints(), SRSSize(keys.CCS), keys.VKFingerprint())
+	} else {
+		p.cfg.Logf("%s circuit: %d constraints, vk %s\n", name, keys.CCS.GetNbConstraints(), keys.VKFingerprint())
+	}
+	return keys, nil
+}
+
+func (p *pipeline) runSamples(result *PipelineResult) error {
+	// linear stays nil with SkipLinear, which proveSamples and
+	// verifySamples take to mean sigmoid proofs only
+	var linear *CircuitKeys
+	var err error
+	salt := "sigmoid-only:"
+	if p.cfg.SkipLinear {
+		p.cfg.Logf("--- Setting up Sigmoid LUT Circuit (linear proofs skipped, z computed off-chain) ---\n")
+	} else {
+		p.cfg.Logf("--- Setting up Linear and Sigmoid LUT Circuits ---\n")
+		if linear, err = p.setupCircuit("linear", p.circuitOptions(0)); err != nil {
+			return err
+		}
+		linear.ProveBudget = p.cfg.ProveBudget
+		salt = linear.VKFingerprint()
+	}
+	sigmoid, err := p.setupCircuit("sigmoid", p.circuitOptions(0))
+	if err != nil {
+		return err
+	}
+	sigmoid.ProveBudget = p.cfg.ProveBudget
+
+	var checkpoint *Checkpoint
+	if p.cfg.CheckpointDir != "" {
+		checkpoint = NewCheckpoint(p.cfg.CheckpointDir, salt+sigmoid.VKFingerprint(), p.cfg.Resume)
+	}
+
+	p.cfg.Logf("\n=== Generating Proofs for All Samples ===\n")
+	ctx := p.cfg.Context
+	if p.cfg.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.MaxDuration)
+		defer cancel()
+	}
+	validProofs, failures, timings, resumed := proveSamples(ctx, checkpoint,
+		linear, sigmoid,
+		p.w, p.b, p.marks, p.labels, p.cfg.Concurrency, p.cfg.Progress)
+	outcomes := make(map[int]*SampleOutcome, len(failures)+len(validProofs))
+	for _, f := range failures {
+		p.detailf("Sample %d (marks=%v): %v\n", f.SampleNum, f.Mark, f.Err)
+		outcomes[f.SampleNum] = &SampleOutcome{SampleNum: f.SampleNum, Mark: f.Mark, Label: p.cfg.Labels.ToLabel(p.labels[f.SampleNum-1]), Error: f.Err.Error()}
+	}
+	for _, pd := range validProofs {
+		outcomes[pd.SampleNum] = &SampleOutcome{SampleNum: pd.SampleNum, Mark: pd.Mark, Label: p.cfg.Labels.ToLabel(pd.ExpectedLabel), Proved: true}
+	}
+	defer func() {
+		for i := range p.marks {
+			if o, ok := outcomes[i+1]; ok {
+				result.Samples = append(result.Samples, *o)
+			}
+		}
+	}()
+	if len(failures) > 0 {
+		p.cfg.Logf("%d/%d samples could not be proved, usually because the prediction does not match the label\n", len(failures), len(p.marks))
+	}
+	result.ProofsGenerated = len(validProofs)
+	result.Resumed = resumed
+	result.Timings = timings
+	if resumed > 0 {
+		p.cfg.Logf("Resumed %d sample proofs from %s\n", resumed, p.cfg.CheckpointDir)
+	}
+	if err := p.cfg.Context.Err(); err != nil {
+		return fmt.Errorf("proving interrupted after %d/%d samples: %w", len(validProofs)+len(failures), len(p.marks), err)
+	}
+	if attempted := len(validProofs) + len(failures); attempted < len(p.marks) {
+		result.Partial = true
+		p.cfg.Logf("Stopped after %v: %d/%d samples attempted, verifying those\n", p.cfg.MaxDuration, attempted, len(p.marks))
+	}
+
+	// Verify each proof (gnark's Verify already does internal batching of KZG checks)
+	p.cfg.Logf("\n=== Verifying All Proofs ===\n")
+	start := time.Now()
+	verifyFailures := verifySamples(linear, sigmoid, validProofs, p.cfg.Concurrency)
+	result.VerifyTime = time.Since(start)
+	failed := make(map[int]bool, len(verifyFailures))
+	for _, f := range verifyFailures {
+		failed[f.SampleNum] = true
+		outcomes[f.SampleNum].Error = f.Err.Error()
+		p.cfg.Logf("Sample %d (marks=%v): verification FAILED: %v\n", f.SampleNum, f.Mark, f.Err)
+	}
+
+	for _, pd := range validProofs {
+		if failed[pd.SampleNum] {
+			continue
+		}
+		// without a linear proof there is no model commitment to check
+		if linear != nil {
+			if err := p.checkModel(pd.LinearPublic); err != nil {
+				p.cfg.Logf("Sample %d (marks=%v): model commitment check FAILED: %v\n", pd.SampleNum, pd.Mark, err)
+				outcomes[pd.SampleNum].Error = err.Error()
+				continue
+			}
+		}
+
+		result.Verified++
+		outcomes[pd.SampleNum].Verified = true
+		labelStr := "Pass"
+		if pd.ExpectedLabel == 1 {
+			labelStr = "Fail"
+		}
+		verified := "Both proofs verified!"
+		if linear == nil {
+			verified = "Sigmoid proof verified!"
+		}
+		p.detailf("✓ Sample %d: Marks=%v, Label=%s - %s\n", pd.SampleNum, pd.Mark, labelStr, verified)
+	}
+	return nil
+}
+
+func (p *pipeline) runInference(result *PipelineResult) error {
+	p.cfg.Logf("\n--- Setting up Combined Inference Circuit ---\n")
+	inference, err := p.setupCircuit("inference", p.circuitOptions(0))
+	if err != nil {
+		return err
+	}
+	inference.ProveBudget = p.cfg.ProveBudget
+
+	wScaled, bScaled := NewScaled(p.w), NewScaled(p.b)
+	commitment := modelCommitment(p.cfg.Curve.ScalarField(), wScaled, bScaled)
+	xs := QuantizeDataset(p.samples())
+	for i := 0; i < len(p.marks); i++ {
+		var inferenceWitness InferenceCircuit
+		inferenceWitness.W = wScaled
+		inferenceWitness.B = bScaled
+		inferenceWitness.X = xs[i]
+		inferenceWitness.Label = big.NewInt(int64(p.labels[i]))
+		inferenceWitness.ModelCommitment = commitment
+
+		inferenceFull, err := frontend.NewWitness(&inferenceWitness, p.cfg.Curve.ScalarField())
+		if err != nil {
+			p.cfg.Logf("Sample %d (marks=%v): Inference witness error: %v\n", i+1, p.marks[i], err)
+			continue
+		}
+		inferencePublic, err := inferenceFull.Public()
+		if err != nil {
+			p.cfg.Logf("Sample %d (marks=%v): Inference public witness error: %v\n", i+1, p.marks[i], err)
+			continue
+		}
+		inferenceProof, err := inference.Prove(inferenceFull)
+		if err != nil {
+			p.detailf("Sample %d (marks=%v): Inference proof error: %v\n", i+1, p.marks[i], err)
+			continue
+		}
+		if err := inference.Verify(inferenceProof, inferencePublic); err != nil {
+			p.cfg.Logf("Sample %d (marks=%v): Inference verification FAILED: %v\n", i+1, p.marks[i], err)
+			continue
+		}
+		if err := p.checkModel(inferencePublic); err != nil {
+			p.cfg.Logf("Sample %d (marks=%v): model commitment check FAILED: %v\n", i+1, p.marks[i], err)
+			continue
+		}
+		result.InferenceVerified++
+	}
+	p.cfg.Logf("Combined inference: %d/%d samples proved and verified (1 proof each vs 2)\n", result.InferenceVerified, len(p.marks))
+	return nil
+}
+
+// saturationWarnFraction is the fraction of saturating samples above which
+// RunPipeline warns about the LUT range.
+const saturationWarnFraction = 0.05
+
+// numChunks is the number of ChunkSize chunks the aggregator combines.
+const numChunks = 4
+
+func (p *pipeline) runAccuracy(result *PipelineResult) error {
+	layout, minCorrect, err := p.accuracyPolicy()
+	if err != nil {
+		return err
+	}
+	p.cfg.Logf("\n=== Proving Accuracy >= %d/%d over dataset (chunked) ===\n", minCorrect, layout.Samples)
+	p.cfg.Logf("Chunk layout: %s\n", layout)
+
+	circuits, err := p.setupAccuracy(layout, minCorrect)
+	if err != nil {
+		return err
+	}
+	return p.proveAccuracy(circuits, p.w, p.b, result)
+}
+
+// accuracyPolicy returns the chunk layout for the dataset and the
+// aggregator threshold for the configured policy over the samples it
+// covers; the default policy is DefaultMinCorrect out of 100.
+func (p *pipeline) accuracyPolicy() (ChunkLayout, int, error) {
+	layout, err := PlanChunks(len(p.marks))
+	if err != nil {
+		return ChunkLayout{}, 0, err
+	}
+	minAccuracy := p.cfg.MinAccuracy
+	if minAccuracy == 0 {
+		minAccuracy = float64(DefaultMinCorrect) / 100
+	}
+	minCorrect, err := MinCorrectFor(minAccuracy, layout.Samples)
+	return layout, minCorrect, err
+}
+
+// accuracyCircuits holds the set-up chunk and aggregator circuits, which
+// depend only on the layout and policy, not on the model being proved.
+type accuracyCircuits struct {
+	layout     ChunkLayout
+	minCorrect int
+	cache      *ChunkCache
+
+	chunk *CircuitKeys
+	agg   *CircuitKeys
+}
+
+func (p *pipeline) setupAccuracy(layout ChunkLayout, minCorrect int) (*accuracyCircuits, error) {
+	c := &accuracyCircuits{layout: layout, minCorrect: minCorrect, cache: p.cfg.ChunkCache}
+	if c.cache == nil {
+		c.cache = NewChunkCache(filepath.Join(p.keyDir(), "chunks"))
+	}
+	c.cache.IncludeBorderline = p.cfg.IncludeBorderline
+
+	opts := p.circuitOptions(minCorrect)
+	var err error
+	c.chunk, err = p.setupCircuit("chunk", opts)
+	if err != nil {
+		return nil, err
+	}
+	c.agg, err = p.setupCircuit("aggregator", opts)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// proveAccuracy proves the accuracy policy for model (w, b) with circuits
+// that are already set up, recording the outcome in result.
+func (p *pipeline) proveAccuracy(c *accuracyCircuits, w, b float64, result *PipelineResult) error {
+	layout := c.layout
+	result.Layout = layout
+	result.AccuracySamples = layout.Samples
+	result.MinCorrect = c.minCorrect
+
+	result.ChunkCounts = make([]int, layout.Chunks)
+	chunkPublics := make([]witness.Witness, layout.Chunks)
+	for chunkIdx := 0; chunkIdx < layout.Chunks; chunkIdx++ {
+		startIdx := layout.First + chunkIdx*ChunkSize
+		endIdx := min(startIdx+ChunkSize, layout.First+layout.Samples)
+
+		marks, labels := layout.chunk(chunkIdx, b, p.marks, p.labels)
+		chunk, cached, err := c.cache.prove(c.chunk, w, b, marks, labels)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", chunkIdx+1, err)
+		}
+		if err := p.checkModel(chunk.Public); err != nil {
+			return fmt.Errorf("chunk %d: %w", chunkIdx+1, err)
+		}
+		result.ChunkCounts[chunkIdx] = chunk.Count
+		chunkPublics[chunkIdx] = chunk.Public
+
+		if cached {
+			result.ChunksReused++
+			p.cfg.Logf("Chunk %d (samples %d-%d): reused cached proof, %d/%d correct\n", chunkIdx+1, startIdx+1, endIdx, chunk.Count, ChunkSize)
+		} else {
+			p.cfg.Logf("Chunk %d (samples %d-%d): proved %d/%d correct\n", chunkIdx+1, startIdx+1, endIdx, chunk.Count, ChunkSize)
+		}
+	}
+
+	for _, count := range result.ChunkCounts {
+		result.TotalCorrect += count
+	}
+	result.Margin = AccuracyMargin{
+		Total:     result.TotalCorrect,
+		Threshold: result.MinCorrect,
+		Margin:    result.TotalCorrect - result.MinCorrect,
+	}
+	p.cfg.Logf("Total correct %d, threshold %d, margin %+d\n", result.Margin.Total, result.Margin.Threshold, result.Margin.Margin)
+	if result.Margin.Margin < 0 {
+		// the aggregator witness could not satisfy the circuit
+		return fmt.Errorf("aggregator: %w: total correct %d is below threshold %d", ErrProve, result.TotalCorrect, result.MinCorrect)
+	}
+
+	aggWitness, err := BuildAggregatorWitness(chunkPublics)
+	if err != nil {
+		return fmt.Errorf("aggregator: %w", err)
+	}
+	aggFull, err := frontend.NewWitness(&aggWitness, p.cfg.Curve.ScalarField())
+	if err != nil {
+		return fmt.Errorf("aggregator: %w: %w", ErrWitness, err)
+	}
+	aggPublic, err := aggFull.Public()
+	if err != nil {
+		return fmt.Errorf("aggregator public: %w: %w", ErrWitness, err)
+	}
+
+	aggProof, err := c.agg.Prove(aggFull)
+	if err != nil {
+		return fmt.Errorf("aggregator: %w", err)
+	}
+	if err := c.agg.Verify(aggProof, aggPublic); err != nil {
+		return fmt.Errorf("aggregator: %w", err)
+	}
+
+	result.AccuracyVerified = true
+	return nil
+}
@@ -0,0 +1,213 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// writeDataset writes samples as a marks,failed CSV in dir.
+func writeDataset(t *testing.T, dir string, samples []utils.Sample) string {
+	t.Helper()
+	var csv strings.Builder
+	csv.WriteString("marks,failed\n")
+	for _, s := range samples {
+		fmt.Fprintf(&csv, "%v,%d\n", s.Marks, s.Label)
+	}
+	path := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(path, []byte(csv.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPipelineMaxDuration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("sets up the sigmoid circuit")
+	}
+	dir := t.TempDir()
+	dataset := writeDataset(t, dir, testChunk())
+	model := writeModel(t, dir, "model.txt", testModel.w, testModel.b)
+
+	tests := []struct {
+		name        string
+		maxDuration time.Duration
+		proveBudget time.Duration
+		partial     bool
+	}{
+		{"unbounded", 0, 0, false},
+		// every proof takes at least 200ms, so only the first few start
+		// before the deadline
+		{"short deadline", 300 * time.Millisecond, 200 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log strings.Builder
+			result, err := RunPipeline(PipelineConfig{
+				DatasetPath: dataset,
+				ModelPath:   model,
+				CacheDir:    filepath.Join(dir, "cache"),
+				Backend:     BackendGroth16,
+				Circuits:    CircuitsPerSample,
+				SkipLinear:  true,
+				MaxDuration: tt.maxDuration,
+				ProveBudget: tt.proveBudget,
+				Logf:        func(format string, args ...any) { fmt.Fprintf(&log, format, args...) },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Partial != tt.partial {
+				t.Errorf("Partial = %v, want %v", result.Partial, tt.partial)
+			}
+			if stopped := strings.Contains(log.String(), "samples attempted"); stopped != tt.partial {
+				t.Errorf("partial run logged = %v, want %v:\n%s", stopped, tt.partial, log.String())
+			}
+			if tt.partial && (result.ProofsGenerated == 0 || result.ProofsGenerated >= result.TotalSamples) {
+				t.Errorf("%d of %d samples proved, want some but not all", result.ProofsGenerated, result.TotalSamples)
+			}
+			if !tt.partial && result.ProofsGenerated != result.TotalSamples {
+				t.Errorf("%d of %d samples proved, want all", result.ProofsGenerated, result.TotalSamples)
+			}
+			if result.Verified != result.ProofsGenerated || len(result.Samples) != result.ProofsGenerated {
+				t.Errorf("%d verified, %d outcomes for %d proofs", result.Verified, len(result.Samples), result.ProofsGenerated)
+			}
+			for i, o := range result.Samples {
+				if o.SampleNum != i+1 || !o.Verified {
+					t.Errorf("outcome %d: %+v, want sample %d verified", i, o, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPipelineLabelMapping(t *testing.T) {
+	if testing.Short() {
+		t.Skip("sets up the sigmoid circuit")
+	}
+	dir := t.TempDir()
+	// a dataset labelled 1 = Pass, 0 = Fail
+	samples := testChunk()[:6]
+	for i := range samples {
+		samples[i].Label = 1 - samples[i].Label
+	}
+	dataset := writeDataset(t, dir, samples)
+	model := writeModel(t, dir, "model.txt", testModel.w, testModel.b)
+
+	tests := []struct {
+		name     string
+		labels   *utils.LabelMapping
+		verified int
+		warned   bool
+	}{
+		{"default mapping", nil, 0, true},
+		{"1 = Pass", &utils.LabelMapping{PositiveClass: 0}, len(samples), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log strings.Builder
+			result, err := RunPipeline(PipelineConfig{
+				DatasetPath: dataset,
+				ModelPath:   model,
+				CacheDir:    filepath.Join(dir, "cache"),
+				Labels:      tt.labels,
+				Backend:     BackendGroth16,
+				Circuits:    CircuitsPerSample,
+				SkipLinear:  true,
+				Logf:        func(format string, args ...any) { fmt.Fprintf(&log, format, args...) },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Verified != tt.verified {
+				t.Errorf("%d of %d samples verified, want %d", result.Verified, len(samples), tt.verified)
+			}
+			if warned := strings.Contains(log.String(), "labels disagree"); warned != tt.warned {
+				t.Errorf("inversion warning = %v, want %v:\n%s", warned, tt.warned, log.String())
+			}
+			// outcomes report the labels as the dataset has them
+			for i, o := range result.Samples {
+				if o.Label != samples[i].Label {
+					t.Errorf("sample %d: label %d, want the dataset's %d", o.SampleNum, o.Label, samples[i].Label)
+				}
+			}
+		})
+	}
+
+	if _, err := newPipeline(PipelineConfig{DatasetPath: dataset, Labels: &utils.LabelMapping{PositiveClass: 2}}); err == nil {
+		t.Error("positive class 2 accepted")
+	}
+}
+
+func TestPipelineCopiesLabels(t *testing.T) {
+	dataset := writeDataset(t, t.TempDir(), testChunk())
+	inverse := utils.LabelMapping{PositiveClass: 0}
+	tests := []struct {
+		name   string
+		labels *utils.LabelMapping
+		want   utils.LabelMapping
+	}{
+		{"default", nil, utils.DefaultLabelMapping()},
+		{"given", &inverse, utils.LabelMapping{PositiveClass: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := PipelineConfig{DatasetPath: dataset, CacheDir: t.TempDir(), Labels: tt.labels}
+			p, err := newPipeline(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.labels != nil {
+				tt.labels.PositiveClass = 1 - tt.labels.PositiveClass
+			}
+			if *p.cfg.Labels != tt.want {
+				t.Errorf("run's mapping changed to %+v", *p.cfg.Labels)
+			}
+			// and the run's copy is its own
+			if p.cfg.Labels == tt.labels {
+				t.Error("run shares the caller's mapping")
+			}
+			p.cfg.Labels.PositiveClass = 1 - p.cfg.Labels.PositiveClass
+			if utils.DefaultLabelMapping() != (utils.LabelMapping{PositiveClass: 1}) {
+				t.Error("writing the run's mapping changed the default")
+			}
+		})
+	}
+}
+
+func TestPipelineUnwritableCache(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	p, err := newPipeline(PipelineConfig{
+		DatasetPath: writeDataset(t, dir, testChunk()),
+		CacheDir:    filepath.Join(blocker, "cache"),
+		Backend:     BackendGroth16,
+		Logf:        func(format string, args ...any) { fmt.Fprintf(&log, format, args...) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.noCacheWrites {
+		t.Fatal("cache writes still enabled")
+	}
+
+	// setting up works without the cache and adds no further warnings
+	if _, err := p.setupCircuit("linear", p.circuitOptions(0)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.setupCircuit("linear", p.circuitOptions(0)); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(log.String(), "Warning"); n != 1 || !strings.Contains(log.String(), "nothing will be cached") {
+		t.Errorf("%d warnings, want the single caching one:\n%s", n, log.String())
+	}
+}
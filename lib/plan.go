@@ -0,0 +1,68 @@
+package lib
+
+import "fmt"
+
+// ProofPlan is how many proofs of each kind a pipeline run generates.
+type ProofPlan struct {
+	// Linear, Sigmoid and Inference are per sample: one of each for every
+	// sample in the dataset.
+	Linear    int `json:"linear"`
+	Sigmoid   int `json:"sigmoid"`
+	Inference int `json:"inference"`
+	// Chunk is the number of chunks the accuracy proof covers (see
+	// PlanChunks), and Aggregator the single proof combining them. Chunks
+	// found in a ChunkCache are reused rather than proved again.
+	Chunk      int `json:"chunk"`
+	Aggregator int `json:"aggregator"`
+	Total      int `json:"total"`
+}
+
+// PlanProofs returns the proofs a run over datasetSize samples generates
+// with every stage selected, for accuracy chunks of chunkSize samples; the
+// pipeline's chunks are ChunkSize. As in PlanChunks the aggregator covers
+// at most numChunks chunks, so 100 samples in chunks of 25 need
+// 100 + 100 + 100 + 4 + 1 = 305 proofs. Use Only for a subset of stages.
+// It panics if chunkSize < 1, as that is a programming error.
+func PlanProofs(datasetSize, chunkSize int) ProofPlan {
+	if chunkSize < 1 {
+		panic(fmt.Sprintf("lib: chunk size must be positive, got %d", chunkSize))
+	}
+	if datasetSize < 1 {
+		return ProofPlan{}
+	}
+	p := ProofPlan{
+		Linear:     datasetSize,
+		Sigmoid:    datasetSize,
+		Inference:  datasetSize,
+		Chunk:      chunksFor(datasetSize, chunkSize),
+		Aggregator: 1,
+	}
+	p.Total = p.Linear + p.Sigmoid + p.Inference + p.Chunk + p.Aggregator
+	return p
+}
+
+// Only returns the plan for the stages in set, with the others zeroed.
+func (p ProofPlan) Only(set CircuitSet) ProofPlan {
+	if !set.Has(CircuitsPerSample) {
+		p.Linear, p.Sigmoid = 0, 0
+	}
+	if !set.Has(CircuitsInference) {
+		p.Inference = 0
+	}
+	if !set.Has(CircuitsAccuracy) {
+		p.Chunk, p.Aggregator = 0, 0
+	}
+	p.Total = p.Linear + p.Sigmoid + p.Inference + p.Chunk + p.Aggregator
+	return p
+}
+
+func (p ProofPlan) String() string {
+	return fmt.Sprintf("%d proofs: %d linear, %d sigmoid, %d inference, %d chunk, %d aggregator",
+		p.Total, p.Linear, p.Sigmoid, p.Inference, p.Chunk, p.Aggregator)
+}
+
+// chunksFor is the number of chunks of chunkSize the accuracy proof covers
+// for n samples: all of them, the last one padded, up to numChunks.
+func chunksFor(n, chunkSize int) int {
+	return min(numChunks, (n+chunkSize-1)/chunkSize)
+}
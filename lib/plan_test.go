@@ -0,0 +1,38 @@
+package lib
+
+import "testing"
+
+func TestPlanProofs(t *testing.T) {
+	tests := []struct {
+		name        string
+		size, chunk int
+		set         CircuitSet
+		want        ProofPlan
+	}{
+		{"100 samples in chunks of 25", 100, 25, CircuitsAll, ProofPlan{100, 100, 100, 4, 1, 305}},
+		{"padded last chunk", 90, 25, CircuitsAll, ProofPlan{90, 90, 90, 4, 1, 275}},
+		{"capped at numChunks", 200, 25, CircuitsAll, ProofPlan{200, 200, 200, numChunks, 1, 605}},
+		{"one sample", 1, 25, CircuitsAll, ProofPlan{1, 1, 1, 1, 1, 5}},
+		{"empty dataset", 0, 25, CircuitsAll, ProofPlan{}},
+		{"per sample only", 100, 25, CircuitsPerSample, ProofPlan{Linear: 100, Sigmoid: 100, Total: 200}},
+		{"accuracy only", 100, 25, CircuitsAccuracy, ProofPlan{Chunk: 4, Aggregator: 1, Total: 5}},
+		{"inference and accuracy", 60, 25, CircuitsInference | CircuitsAccuracy, ProofPlan{Inference: 60, Chunk: 3, Aggregator: 1, Total: 64}},
+	}
+	for _, tt := range tests {
+		if got := PlanProofs(tt.size, tt.chunk).Only(tt.set); got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	const want = "305 proofs: 100 linear, 100 sigmoid, 100 inference, 4 chunk, 1 aggregator"
+	if got := PlanProofs(100, ChunkSize).String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("chunk size 0 did not panic")
+		}
+	}()
+	PlanProofs(100, 0)
+}
@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// maxSweepPrecision bounds PrecisionSweep so that the rescaled product stays
+// far inside divFloorPow2's quotient range.
+const maxSweepPrecision = 64
+
+// PrecisionResult reports the cost and fidelity of the linear circuit at one
+// fixed-point precision.
+type PrecisionResult struct {
+	Precision     uint
+	NbConstraints int
+	// ProveTime covers witness construction and one proof.
+	ProveTime time.Duration
+	// Samples is the number of sweep samples; Mismatches counts those whose
+	// quantized sign of z differs from the float64 reference.
+	Samples    int
+	Mismatches int
+	// Agreement is the fraction of samples that match the reference.
+	Agreement float64
+}
+
+// PrecisionSweep compiles and sets up the linear circuit at each precision
+// (fractional bits of the Q-format for W, B, X and z), times one proof and
+// compares the quantized prediction, z >= 0, with the float64 reference over
+// the sweep samples: the KnownAnswers models at marks 0 to 100 in steps of
+// 0.1, which unlike the models are not exact in binary. The pipeline itself
+// always uses Precision.
+func PrecisionSweep(precisions []uint) ([]PrecisionResult, error) {
+	var results []PrecisionResult
+	for _, p := range precisions {
+		if p == 0 || p > maxSweepPrecision {
+			return results, fmt.Errorf("precision %d out of range [1, %d]", p, maxSweepPrecision)
+		}
+		r, err := sweepPrecision(p)
+		if err != nil {
+			return results, fmt.Errorf("precision %d: %w", p, err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func sweepPrecision(p uint) (PrecisionResult, error) {
+	r := PrecisionResult{Precision: p}
+	ccs, pk, _, err := Setup(&linearPrecisionCircuit{precision: p})
+	if err != nil {
+		return r, err
+	}
+	r.NbConstraints = ccs.GetNbConstraints()
+
+	ka := KnownAnswers[0]
+	w, b := scaledAt(ka.W, p), scaledAt(ka.B, p)
+	start := time.Now()
+	full, err := frontend.NewWitness(&linearPrecisionCircuit{
+		W:               w,
+		B:               b,
+		X:               scaledAt(ka.X, p),
+		Z:               linearZAt(w, b, ka.X, p),
+		ModelCommitment: modelCommitment(ccs.Field(), w, b),
+	}, ccs.Field())
+	if err != nil {
+		return r, fmt.Errorf("%w: %w", ErrWitness, err)
+	}
+	if _, err := plonkKeys(ccs, pk, nil).Prove(full); err != nil {
+		return r, err
+	}
+	r.ProveTime = time.Since(start)
+
+	for _, ka := range KnownAnswers {
+		w, b := scaledAt(ka.W, p), scaledAt(ka.B, p)
+		for i := 0; i <= 1000; i++ {
+			x := float64(i) / 10
+			quantized := linearZAt(w, b, x, p).Sign() >= 0
+			reference := ka.W*x+ka.B >= 0
+			r.Samples++
+			if quantized != reference {
+				r.Mismatches++
+			}
+		}
+	}
+	r.Agreement = float64(r.Samples-r.Mismatches) / float64(r.Samples)
+	return r, nil
+}
+
+// linearPrecisionCircuit is LinearCircuit with the fixed-point precision as
+// a compile-time parameter instead of Precision.
+type linearPrecisionCircuit struct {
+	W               frontend.Variable
+	B               frontend.Variable
+	X               frontend.Variable `gnark:",public"`
+	Z               frontend.Variable `gnark:",public"`
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	precision uint
+}
+
+func (c *linearPrecisionCircuit) Define(api frontend.API) error {
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+	wx := TruncatingQ{Precision: int(c.precision)}.Mul(api, c.W, c.X)
+	api.AssertIsEqual(api.Add(wx, c.B), c.Z)
+	return nil
+}
+
+// scaledAt is NewScaled with p fractional bits.
+func scaledAt(v float64, p uint) *big.Int {
+	f := new(big.Float).SetFloat64(v)
+	f.Mul(f, new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), p)))
+	res, _ := f.Int(nil)
+	return res
+}
+
+// linearZAt is LinearZ with p fractional bits.
+func linearZAt(wScaled, bScaled *big.Int, x float64, p uint) *big.Int {
+	z := new(big.Int).Mul(wScaled, scaledAt(x, p))
+	z = RescaleInt(z, 2*p, p)
+	return z.Add(z, bScaled)
+}
@@ -0,0 +1,56 @@
+package lib
+
+import "testing"
+
+func TestPrecisionSweep(t *testing.T) {
+	results, err := PrecisionSweep([]uint{2, Precision})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Precision != 2 || results[1].Precision != Precision {
+		t.Fatalf("results %+v, want one per precision in order", results)
+	}
+	coarse, fine := results[0], results[1]
+	for _, r := range results {
+		if r.NbConstraints <= 0 || r.ProveTime <= 0 || r.Samples == 0 {
+			t.Errorf("precision %d: %+v", r.Precision, r)
+		}
+		if want := float64(r.Samples-r.Mismatches) / float64(r.Samples); r.Agreement != want {
+			t.Errorf("precision %d: agreement %v, want %v", r.Precision, r.Agreement, want)
+		}
+	}
+	if coarse.Samples != fine.Samples {
+		t.Errorf("%d and %d samples, want the same sweep", coarse.Samples, fine.Samples)
+	}
+	// two fractional bits truncate marks such as 0.1 far enough to flip
+	// the sign of z next to the models' decision boundaries
+	if coarse.Mismatches == 0 || fine.Mismatches >= coarse.Mismatches {
+		t.Errorf("mismatches %d at precision 2 and %d at %d, want fewer at the higher precision", coarse.Mismatches, fine.Mismatches, Precision)
+	}
+	if coarse.NbConstraints >= fine.NbConstraints {
+		t.Errorf("%d constraints at precision 2 and %d at %d, want fewer at the lower precision", coarse.NbConstraints, fine.NbConstraints, Precision)
+	}
+	if n := compiled(t, &LinearCircuit{}).GetNbConstraints(); fine.NbConstraints != n {
+		t.Errorf("%d constraints at Precision, LinearCircuit has %d", fine.NbConstraints, n)
+	}
+}
+
+func TestPrecisionSweepRange(t *testing.T) {
+	for _, p := range []uint{0, maxSweepPrecision + 1} {
+		if _, err := PrecisionSweep([]uint{p}); err == nil {
+			t.Errorf("precision %d accepted", p)
+		}
+	}
+}
+
+func TestScaledAtPrecision(t *testing.T) {
+	for _, v := range []float64{0, 1, -0.5, testModel.w, 59.4239, -1e6} {
+		if got, want := scaledAt(v, Precision), NewScaled(v); got.Cmp(want) != 0 {
+			t.Errorf("scaledAt(%v, Precision) = %v, NewScaled = %v", v, got, want)
+		}
+		x := NewScaled(30)
+		if got, want := linearZAt(NewScaled(v), NewScaled(testModel.b), 30, Precision), linearZScaled(NewScaled(v), NewScaled(testModel.b), x); got.Cmp(want) != 0 {
+			t.Errorf("linearZAt(W = %v) = %v, linearZScaled = %v", v, got, want)
+		}
+	}
+}
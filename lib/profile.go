@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/profile"
+)
+
+// ProfileResult locates the constraint profile of one compiled circuit.
+type ProfileResult struct {
+	Name          string
+	NbConstraints int
+	// Path is the pprof file; inspect it with `go tool pprof -list <func> <path>`.
+	Path string
+	// Top is the top-10 report of constraints per function.
+	Top string
+}
+
+// ProfileCircuits compiles every registered circuit under gnark's constraint
+// profiler and writes one <name>.pprof file per circuit into dir.
+func ProfileCircuits(dir string) ([]ProfileResult, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var results []ProfileResult
+	for _, c := range Circuits(CircuitsAll) {
+		path := filepath.Join(dir, c.Name+".pprof")
+		p := profile.Start(profile.WithPath(path))
+		_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, c.New(CircuitOptions{}))
+		p.Stop()
+		if err != nil {
+			return results, fmt.Errorf("%s circuit: %w: %w", c.Name, ErrCircuitCompile, err)
+		}
+
+		results = append(results, ProfileResult{
+			Name:          c.Name,
+			NbConstraints: p.NbConstraints(),
+			Path:          path,
+			Top:           p.Top(),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,389 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// ProgressFunc is called after each sample is processed, successful or not.
+// A nil ProgressFunc disables progress reporting.
+type ProgressFunc func(done, total int)
+
+// ProofData bundles the linear and sigmoid proofs for one sample along with
+// the public witnesses needed to verify them. LinearProof and LinearPublic
+// are nil for a run with PipelineConfig.SkipLinear.
+type ProofData struct {
+	LinearProof   Proof
+	LinearPublic  witness.Witness
+	SigmoidProof  Proof
+	SigmoidPublic witness.Witness
+	Mark          float64
+	ExpectedLabel int
+	SampleNum     int
+}
+
+// SampleError records why proof generation failed for a single sample.
+type SampleError struct {
+	SampleNum int
+	Mark      float64
+	Err       error
+}
+
+func (e *SampleError) Error() string {
+	return fmt.Sprintf("sample %d (marks=%v): %v", e.SampleNum, e.Mark, e.Err)
+}
+
+func (e *SampleError) Unwrap() error {
+	return e.Err
+}
+
+// SampleTimings accumulates time spent per phase of the per-sample loop,
+// over the Count samples whose proofs were generated successfully.
+type SampleTimings struct {
+	Count          int           `json:"count"`
+	LinearWitness  time.Duration `json:"linear_witness"`
+	LinearProve    time.Duration `json:"linear_prove"`
+	SigmoidWitness time.Duration `json:"sigmoid_witness"`
+	SigmoidProve   time.Duration `json:"sigmoid_prove"`
+}
+
+func (t *SampleTimings) add(o SampleTimings) {
+	t.Count += o.Count
+	t.LinearWitness += o.LinearWitness
+	t.LinearProve += o.LinearProve
+	t.SigmoidWitness += o.SigmoidWitness
+	t.SigmoidProve += o.SigmoidProve
+}
+
+// Average returns the per-sample mean of each phase.
+func (t SampleTimings) Average() SampleTimings {
+	if t.Count == 0 {
+		return t
+	}
+	n := time.Duration(t.Count)
+	return SampleTimings{
+		Count:          t.Count,
+		LinearWitness:  t.LinearWitness / n,
+		LinearProve:    t.LinearProve / n,
+		SigmoidWitness: t.SigmoidWitness / n,
+		SigmoidProve:   t.SigmoidProve / n,
+	}
+}
+
+// ProveSamples generates a linear and a sigmoid proof for every sample.
+// Samples whose proofs cannot be generated are reported in the returned
+// errors and skipped; they do not abort the batch.
+func ProveSamples(
+	linearSCS constraint.ConstraintSystem, linearPK plonk.ProvingKey,
+	sigmoidSCS constraint.ConstraintSystem, sigmoidPK plonk.ProvingKey,
+	w, b float64, marks []float64, labels []int, progress ProgressFunc,
+) ([]ProofData, []*SampleError, SampleTimings) {
+	return ProveSamplesConcurrent(linearSCS, linearPK, sigmoidSCS, sigmoidPK, w, b, marks, labels, 1, progress)
+}
+
+// ProveSamplesConcurrent is ProveSamples with up to workers samples proved in
+// parallel. Results are returned in sample order and progress calls are
+// serialised, so callers need no locking.
+func ProveSamplesConcurrent(
+	linearSCS constraint.ConstraintSystem, linearPK plonk.ProvingKey,
+	sigmoidSCS constraint.ConstraintSystem, sigmoidPK plonk.ProvingKey,
+	w, b float64, marks []float64, labels []int, workers int, progress ProgressFunc,
+) ([]ProofData, []*SampleError, SampleTimings) {
+	proofs, failures, timings, _ := proveSamples(context.Background(), nil,
+		plonkKeys(linearSCS, linearPK, nil), plonkKeys(sigmoidSCS, sigmoidPK, nil), w, b, marks, labels, workers, progress)
+	return proofs, failures, timings
+}
+
+// proveSamples is ProveSamplesConcurrent that reuses and records proofs in
+// checkpoint (if non-nil) and stops starting new samples once ctx is done.
+// Samples never started are neither proofs nor failures. resumed counts the
+// proofs taken from the checkpoint; they are not included in the timings.
+func proveSamples(
+	ctx context.Context, checkpoint *Checkpoint,
+	linear, sigmoid *CircuitKeys,
+	w, b float64, marks []float64, labels []int, workers int, progress ProgressFunc,
+) (proofs []ProofData, failures []*SampleError, timings SampleTimings, resumed int) {
+	if progress == nil {
+		progress = func(done, total int) {}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	wScaled := NewScaled(w)
+	bScaled := NewScaled(b)
+	xs := quantizeMarks(marks)
+
+	outcomes := make([]sampleOutcome, len(marks))
+
+	var mu sync.Mutex
+	done := 0
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				outcomes[i] = proveCheckpointed(checkpoint, linear, sigmoid, w, b, wScaled, bScaled, i, marks[i], xs[i], labels[i])
+
+				mu.Lock()
+				done++
+				progress(done, len(marks))
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for i := range marks {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	for i, o := range outcomes {
+		switch {
+		case !o.started:
+			continue
+		case o.err != nil:
+			failures = append(failures, &SampleError{SampleNum: i + 1, Mark: marks[i], Err: o.err})
+			continue
+		case o.resumed:
+			resumed++
+		default:
+			timings.add(o.t)
+		}
+		o.pd.SampleNum = i + 1
+		proofs = append(proofs, o.pd)
+	}
+
+	return proofs, failures, timings, resumed
+}
+
+// sampleOutcome is the result of one sample of a batch.
+type sampleOutcome struct {
+	pd      ProofData
+	t       SampleTimings
+	err     error
+	started bool
+	resumed bool
+}
+
+// proveCheckpointed returns sample i's proofs from checkpoint if it has
+// them, and otherwise proves the sample and, best effort, checkpoints it; a
+// failed write only costs a re-prove on resume.
+func proveCheckpointed(
+	checkpoint *Checkpoint,
+	linear, sigmoid *CircuitKeys,
+	w, b float64, wScaled, bScaled *big.Int, i int, mark float64, xScaled *big.Int, label int,
+) sampleOutcome {
+	var key string
+	if checkpoint != nil {
+		key = checkpoint.key(w, b, i, mark, label)
+		if pd, ok := checkpoint.load(key, i); ok {
+			return sampleOutcome{pd: pd, started: true, resumed: true}
+		}
+	}
+
+	pd, t, err := proveSampleRecover(linear, sigmoid, wScaled, bScaled, mark, xScaled, label)
+	if err == nil && checkpoint != nil {
+		pd.SampleNum = i + 1
+		checkpoint.save(key, i, sigmoid.Backend, pd)
+	}
+	return sampleOutcome{pd: pd, t: t, err: err, started: true}
+}
+
+// proveSampleRecover runs proveSample, turning a panic (e.g. from a
+// pathological witness) into an error so the rest of the batch continues.
+func proveSampleRecover(
+	linear, sigmoid *CircuitKeys,
+	wScaled, bScaled *big.Int, mark float64, xScaled *big.Int, expectedLabel int,
+) (pd ProofData, t SampleTimings, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: panic: %v", ErrProve, r)
+		}
+	}()
+	return proveSample(linear, sigmoid, wScaled, bScaled, mark, xScaled, expectedLabel)
+}
+
+func proveSample(
+	linear, sigmoid *CircuitKeys,
+	wScaled, bScaled *big.Int, mark float64, xScaled *big.Int, expectedLabel int,
+) (ProofData, SampleTimings, error) {
+	var t SampleTimings
+	zScaled := linearZScaled(wScaled, bScaled, xScaled)
+
+	// ====================================================================
+	// Generate Linear Circuit Proof
+	// ====================================================================
+	// Without linear keys (PipelineConfig.SkipLinear) only the sigmoid
+	// circuit is proved, on the z computed above.
+	var linearProof Proof
+	var linearWitnessPublic witness.Witness
+	if linear != nil {
+		var err error
+		if linearProof, linearWitnessPublic, err = proveLinear(linear, wScaled, bScaled, xScaled, zScaled, &t); err != nil {
+			return ProofData{}, t, err
+		}
+	}
+
+	// ====================================================================
+	// Generate Threshold (Sign) Circuit Proof
+	// ====================================================================
+	start := time.Now()
+	sigmoidWitnessFull, err := sigmoidWitness(sigmoid.CCS.Field(), zScaled, expectedLabel)
+	if err != nil {
+		return ProofData{}, t, err
+	}
+
+	sigmoidWitnessPublic, err := sigmoidWitnessFull.Public()
+	if err != nil {
+		return ProofData{}, t, fmt.Errorf("%w: sigmoid public: %w", ErrWitness, err)
+	}
+
+	t.SigmoidWitness = time.Since(start)
+
+	start = time.Now()
+	sigmoidProof, err := sigmoid.Prove(sigmoidWitnessFull)
+	if err != nil {
+		return ProofData{}, t, fmt.Errorf("sigmoid: %w", err)
+	}
+
+	t.SigmoidProve = time.Since(start)
+	t.Count = 1
+
+	return ProofData{
+		LinearProof:   linearProof,
+		LinearPublic:  linearWitnessPublic,
+		SigmoidProof:  sigmoidProof,
+		SigmoidPublic: sigmoidWitnessPublic,
+		Mark:          mark,
+		ExpectedLabel: expectedLabel,
+	}, t, nil
+}
+
+// proveLinear proves z = W*X + B for one sample, adding its witness and
+// proving times to t.
+func proveLinear(linear *CircuitKeys, wScaled, bScaled, xScaled, zScaled *big.Int, t *SampleTimings) (Proof, witness.Witness, error) {
+	start := time.Now()
+	linearWitnessFull, err := linearWitness(linear.CCS.Field(), wScaled, bScaled, xScaled, zScaled)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	linearWitnessPublic, err := linearWitnessFull.Public()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: linear public: %w", ErrWitness, err)
+	}
+
+	t.LinearWitness = time.Since(start)
+
+	start = time.Now()
+	linearProof, err := linear.Prove(linearWitnessFull)
+	if err != nil {
+		return nil, nil, fmt.Errorf("linear: %w", err)
+	}
+
+	t.LinearProve = time.Since(start)
+	return linearProof, linearWitnessPublic, nil
+}
+
+// BuildSigmoidWitnesses builds a full SigmoidCircuit witness for each (z,
+// label) pair, with z in Q32 as computed by the linear circuit, over the
+// DefaultCurve scalar field.
+func BuildSigmoidWitnesses(zs []*big.Int, labels []int) ([]witness.Witness, error) {
+	if len(zs) != len(labels) {
+		return nil, fmt.Errorf("%w: %d z values but %d labels", ErrWitness, len(zs), len(labels))
+	}
+	witnesses := make([]witness.Witness, len(zs))
+	for i := range zs {
+		w, err := sigmoidWitness(DefaultCurve.ScalarField(), zs[i], labels[i])
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i+1, err)
+		}
+		witnesses[i] = w
+	}
+	return witnesses, nil
+}
+
+// SigmoidProbabilityWitness builds a full witness for a compiled
+// NewProbabilitySigmoidCircuit with DefaultLUTConfig, filling in
+// Probability as QuantizedSigmoid(DefaultLUTConfig, table, z).
+func SigmoidProbabilityWitness(field *big.Int, table []int64, z *big.Int, label int) (witness.Witness, error) {
+	assignment := SigmoidCircuit{
+		Z:           z,
+		Label:       big.NewInt(int64(label)),
+		Probability: &SigmoidProbability{Value: QuantizedSigmoid(DefaultLUTConfig(), table, z)},
+	}
+	w, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sigmoid: %w", ErrWitness, err)
+	}
+	return w, nil
+}
+
+// PublicProbability extracts Probability from the public witness of a
+// SigmoidCircuit with ExposeProbability and DefaultLUTConfig, laid out as
+// [Z, Label, Probability].
+func PublicProbability(public witness.Witness) (int64, error) {
+	v, err := publicElement(public, 2)
+	if err != nil {
+		return 0, fmt.Errorf("%w: probability: %w", ErrWitness, err)
+	}
+	if !v.IsInt64() || v.Int64() > 1<<outputPrecision {
+		return 0, fmt.Errorf("%w: probability %s out of range", ErrWitness, v.String())
+	}
+	return v.Int64(), nil
+}
+
+// LinearWitness returns the full LinearCircuit witness over field for
+// model (w, b) at marks x, with the z the circuit computes, e.g. for a
+// client of a proving server.
+func LinearWitness(field *big.Int, w, b, x float64) (witness.Witness, error) {
+	wScaled, bScaled, xScaled := NewScaled(w), NewScaled(b), NewScaled(x)
+	return linearWitness(field, wScaled, bScaled, xScaled, linearZScaled(wScaled, bScaled, xScaled))
+}
+
+func linearWitness(field *big.Int, wScaled, bScaled, xScaled, zScaled *big.Int) (witness.Witness, error) {
+	assignment := LinearCircuit{
+		W:               wScaled,
+		B:               bScaled,
+		X:               xScaled,
+		Z:               zScaled,
+		ModelCommitment: modelCommitment(field, wScaled, bScaled),
+	}
+	w, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: linear: %w", ErrWitness, err)
+	}
+	return w, nil
+}
+
+func sigmoidWitness(field *big.Int, z *big.Int, label int) (witness.Witness, error) {
+	var assignment SigmoidCircuit
+
+	assignment.Z = z
+	// Use client-provided dataset label as the asserted ground truth.
+	// The circuit will recompute prediction = (z>=0) and assert equality to this label.
+	// label is the model class, already mapped by utils.LabelMapping (1 = Fail by default).
+	assignment.Label = big.NewInt(int64(label))
+
+	w, err := frontend.NewWitness(&assignment, field)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sigmoid: %w", ErrWitness, err)
+	}
+	return w, nil
+}
@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestProveSamplesSurvivesPanic(t *testing.T) {
+	linearCCS, linearPK, _, err := Setup(&LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a coarse table keeps the setup quick; the witness is the same
+	sigmoidCCS, sigmoidPK, _, err := Setup(&SigmoidCircuit{InterpolationSteps: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NewScaled(+Inf) is nil, so computing sample 2's z panics
+	marks := []float64{30, math.Inf(1), 10}
+	labels := []int{utils.PredictQuantized(testModel.w, testModel.b, 30), 0, utils.PredictQuantized(testModel.w, testModel.b, 10)}
+	proofs, failures, _ := ProveSamples(linearCCS, linearPK, sigmoidCCS, sigmoidPK, testModel.w, testModel.b, marks, labels, nil)
+
+	if len(failures) != 1 || failures[0].SampleNum != 2 {
+		t.Fatalf("failures %v, want only sample 2", failures)
+	}
+	if err := failures[0].Err; !errors.Is(err, ErrProve) || !strings.Contains(err.Error(), "panic") {
+		t.Errorf("sample 2: %v, want a recovered ErrProve panic", err)
+	}
+	if len(proofs) != 2 || proofs[0].SampleNum != 1 || proofs[1].SampleNum != 3 {
+		t.Errorf("proved samples %v, want 1 and 3", proofs)
+	}
+}
+
+func TestBuildSigmoidWitnesses(t *testing.T) {
+	ccs := compiled(t, &SigmoidCircuit{})
+	wScaled, bScaled := NewScaled(testModel.w), NewScaled(testModel.b)
+	tests := []struct {
+		name    string
+		marks   []float64
+		labels  []int
+		wantErr bool
+	}{
+		{"none", nil, nil, false},
+		{"one", []float64{30}, []int{0}, false},
+		{"both classes", []float64{0, 19, 20, 21, 100}, []int{1, 1, 1, 0, 0}, false},
+		{"more labels", []float64{30}, []int{0, 1}, true},
+		{"fewer labels", []float64{30, 40}, []int{0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zs := make([]*big.Int, len(tt.marks))
+			for i, x := range tt.marks {
+				zs[i] = LinearZ(wScaled, bScaled, x)
+			}
+			witnesses, err := BuildSigmoidWitnesses(zs, tt.labels)
+			if tt.wantErr {
+				if !errors.Is(err, ErrWitness) {
+					t.Fatalf("err = %v, want ErrWitness", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(witnesses) != len(zs) {
+				t.Fatalf("%d witnesses for %d samples", len(witnesses), len(zs))
+			}
+			flipped := make([]int, len(tt.labels))
+			for i, l := range tt.labels {
+				flipped[i] = 1 - l
+			}
+			wrong, err := BuildSigmoidWitnesses(zs, flipped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, w := range witnesses {
+				if err := ccs.IsSolved(w); err != nil {
+					t.Errorf("sample %d (marks %v): %v", i+1, tt.marks[i], err)
+				}
+				if ccs.IsSolved(wrong[i]) == nil {
+					t.Errorf("sample %d (marks %v): label %d solves", i+1, tt.marks[i], flipped[i])
+				}
+			}
+		})
+	}
+}
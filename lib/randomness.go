@@ -0,0 +1,17 @@
+package lib
+
+import "sync"
+
+// randomnessMu guards crypto/rand.Reader against deterministicProve, which
+// only exists under the zklr_insecure_seed build tag. Every lib function
+// that draws randomness (Prove, setup, NewLabelSalt) holds it for reading;
+// a deterministic proof holds it exclusively while the reader is swapped,
+// so nothing else lib does can read the seeded stream.
+var randomnessMu sync.RWMutex
+
+// drawingRandomness holds randomnessMu for reading until the returned
+// function is called.
+func drawingRandomness() (done func()) {
+	randomnessMu.RLock()
+	return randomnessMu.RUnlock
+}
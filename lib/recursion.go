@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	"github.com/consensys/gnark/std/math/emulated"
+	recursion "github.com/consensys/gnark/std/recursion/plonk"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// Recursive aggregation verifies chunk proofs inside the aggregator instead
+// of trusting their counts. Pairing-based in-circuit verification is only
+// affordable over a 2-chain, where the outer circuit's field is the inner
+// curve's base field, so the chunks are proved on RecursionInnerCurve and
+// the aggregator on RecursionOuterCurve rather than on the pipeline's curves.
+const (
+	RecursionInnerCurve = ecc.BLS12_377
+	RecursionOuterCurve = ecc.BW6_761
+)
+
+type (
+	recursionProof   = recursion.Proof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine]
+	recursionVK      = recursion.VerifyingKey[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine]
+	recursionWitness = recursion.Witness[sw_bls12377.ScalarField]
+)
+
+// ============================================================================
+// CIRCUIT 4B: Recursive Aggregator Circuit
+// Verifies the chunk proofs in-circuit and proves that the sum of their
+// public counts meets MinCorrect, as a single proof of the accuracy claim.
+// ============================================================================
+type RecursiveAggregatorCircuit struct {
+	// Proofs are the chunk proofs, made with ProveChunkForRecursion.
+	Proofs []recursionProof
+	// Publics are the chunks' public witnesses, [X..., Label..., Count,
+	// ModelCommitment] each, and are the aggregator's public inputs.
+	Publics []recursionWitness `gnark:",public"`
+
+	// VerifyingKey is the chunk circuit's, fixed at compile time so a proof
+	// of any other circuit is rejected.
+	VerifyingKey recursionVK `gnark:"-"`
+	MinCorrect   int         `gnark:"-"`
+}
+
+// NewRecursiveAggregatorCircuit returns the circuit to compile on
+// RecursionOuterCurve for nbChunks proofs of chunkCCS, an
+// AccuracyChunkCircuit compiled on RecursionInnerCurve with verifying key
+// chunkVK.
+func NewRecursiveAggregatorCircuit(chunkCCS constraint.ConstraintSystem, chunkVK plonk.VerifyingKey, nbChunks, minCorrect int) (*RecursiveAggregatorCircuit, error) {
+	if nbChunks < 1 {
+		return nil, fmt.Errorf("%w: recursive aggregator needs at least one chunk", ErrCircuitCompile)
+	}
+	vk, err := recursion.ValueOfVerifyingKey[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](chunkVK)
+	if err != nil {
+		return nil, fmt.Errorf("%w: chunk verifying key: %w", ErrCircuitCompile, err)
+	}
+	c := &RecursiveAggregatorCircuit{
+		Proofs:       make([]recursionProof, nbChunks),
+		Publics:      make([]recursionWitness, nbChunks),
+		VerifyingKey: vk,
+		MinCorrect:   minCorrect,
+	}
+	for i := range c.Proofs {
+		c.Proofs[i] = recursion.PlaceholderProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](chunkCCS)
+		c.Publics[i] = recursion.PlaceholderWitness[sw_bls12377.ScalarField](chunkCCS)
+	}
+	return c, nil
+}
+
+// BuildRecursiveAggregatorWitness assembles the RecursiveAggregatorCircuit
+// assignment from chunk proofs and their public witnesses, in order.
+func BuildRecursiveAggregatorWitness(proofs []plonk.Proof, publics []witness.Witness) (*RecursiveAggregatorCircuit, error) {
+	if len(proofs) != len(publics) {
+		return nil, fmt.Errorf("%w: %d chunk proofs but %d public witnesses", ErrWitness, len(proofs), len(publics))
+	}
+	c := &RecursiveAggregatorCircuit{
+		Proofs:  make([]recursionProof, len(proofs)),
+		Publics: make([]recursionWitness, len(publics)),
+	}
+	for i := range proofs {
+		var err error
+		c.Proofs[i], err = recursion.ValueOfProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](proofs[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: chunk %d proof: %w", ErrWitness, i+1, err)
+		}
+		c.Publics[i], err = recursion.ValueOfWitness[sw_bls12377.ScalarField](publics[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: chunk %d public: %w", ErrWitness, i+1, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *RecursiveAggregatorCircuit) Define(api frontend.API) error {
+	verifier, err := recursion.NewVerifier[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT](api)
+	if err != nil {
+		return fmt.Errorf("new verifier: %w", err)
+	}
+	if err := verifier.AssertSameProofs(c.VerifyingKey, c.Proofs, c.Publics); err != nil {
+		return err
+	}
+
+	fr, err := emulated.NewField[sw_bls12377.ScalarField](api)
+	if err != nil {
+		return err
+	}
+	commitment := &c.Publics[0].Public[2*ChunkSize+1]
+	total := frontend.Variable(0)
+	for i := range c.Publics {
+		// every chunk must be for the same model
+		fr.AssertIsEqual(&c.Publics[i].Public[2*ChunkSize+1], commitment)
+		total = api.Add(total, api.FromBinary(fr.ToBitsCanonical(&c.Publics[i].Public[2*ChunkSize])...))
+	}
+	api.AssertIsLessOrEqual(c.MinCorrect, total)
+	return nil
+}
+
+// ProveChunkForRecursion is ProveChunkCount for a chunk circuit compiled on
+// RecursionInnerCurve, with the transcript hash RecursiveAggregatorCircuit
+// verifies against.
+func ProveChunkForRecursion(pk plonk.ProvingKey, ccs constraint.ConstraintSystem, w, b float64, samples []utils.Sample) (plonk.Proof, int, witness.Witness, error) {
+	if len(samples) != ChunkSize {
+		return nil, 0, nil, fmt.Errorf("%w: chunk needs %d samples, got %d", ErrWitness, ChunkSize, len(samples))
+	}
+	marks := make([]float64, len(samples))
+	labels := make([]int, len(samples))
+	for i, s := range samples {
+		marks[i], labels[i] = s.Marks, s.Label
+	}
+
+	full, err := chunkWitness(ccs.Field(), w, b, marks, labels, false)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	public, err := full.Public()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: chunk public: %w", ErrWitness, err)
+	}
+	proof, err := plonk.Prove(ccs, pk, full,
+		recursion.GetNativeProverOptions(RecursionOuterCurve.ScalarField(), RecursionInnerCurve.ScalarField()))
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: chunk: %w", ErrProve, err)
+	}
+	count, err := PublicChunkCount(public)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return proof, count, public, nil
+}
+
+// VerifyChunkForRecursion checks a ProveChunkForRecursion proof natively.
+func VerifyChunkForRecursion(proof plonk.Proof, vk plonk.VerifyingKey, public witness.Witness) error {
+	err := plonk.Verify(proof, vk, public,
+		recursion.GetNativeVerifierOptions(RecursionOuterCurve.ScalarField(), RecursionInnerCurve.ScalarField()))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVerify, err)
+	}
+	return nil
+}
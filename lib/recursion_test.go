@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+func TestRecursiveAggregatorArguments(t *testing.T) {
+	if _, err := NewRecursiveAggregatorCircuit(nil, nil, 0, 1); !errors.Is(err, ErrCircuitCompile) {
+		t.Errorf("no chunks: %v, want ErrCircuitCompile", err)
+	}
+	if _, err := BuildRecursiveAggregatorWitness(make([]plonk.Proof, 2), make([]witness.Witness, 1)); !errors.Is(err, ErrWitness) {
+		t.Errorf("2 proofs with 1 public witness: %v, want ErrWitness", err)
+	}
+}
+
+// TestRecursiveAggregator proves two chunks on RecursionInnerCurve and
+// checks the aggregator accepts them only with their own public witnesses
+// and only when their counts meet MinCorrect. It solves the aggregator
+// rather than proving it, which would take far longer than the chunks.
+func TestRecursiveAggregator(t *testing.T) {
+	heavy(t, "proves two chunks and compiles a recursive verifier")
+	chunkCCS, pk, vk, err := SetupCurve(RecursionInnerCurve, &AccuracyChunkCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	marks, labels := testDataset()
+	samples := func(c int, labels []int) []utils.Sample {
+		s := make([]utils.Sample, ChunkSize)
+		for i := range s {
+			s[i] = utils.Sample{Marks: marks[c][i], Label: labels[i]}
+		}
+		return s
+	}
+
+	// chunk 1 has the borderline marks 20, so the counts are 24 and 25
+	var proofs []plonk.Proof
+	var publics []witness.Witness
+	for c, want := range []int{24, 25} {
+		proof, count, public, err := ProveChunkForRecursion(pk, chunkCCS, testModel.w, testModel.b, samples(c, labels[c]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Fatalf("chunk %d: count %d, want %d", c+1, count, want)
+		}
+		if err := VerifyChunkForRecursion(proof, vk, public); err != nil {
+			t.Fatalf("chunk %d: %v", c+1, err)
+		}
+		proofs, publics = append(proofs, proof), append(publics, public)
+	}
+	// chunk 2 with one label flipped claims a count of 24 its proof was not
+	// made for
+	flipped := append([]int(nil), labels[1]...)
+	flipped[0] = 1 - flipped[0]
+	full, err := chunkWitness(chunkCCS.Field(), testModel.w, testModel.b, marks[1], flipped, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := full.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		minCorrect int
+		publics    []witness.Witness
+		ok         bool
+	}{
+		{"counts meet MinCorrect", 49, publics, true},
+		{"counts below MinCorrect", 50, publics, false},
+		{"forged count", 48, []witness.Witness{publics[0], forged}, false},
+		{"swapped public witnesses", 49, []witness.Witness{publics[1], publics[0]}, false},
+	}
+	aggregators := map[int]constraint.ConstraintSystem{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ccs, ok := aggregators[tt.minCorrect]
+			if !ok {
+				circuit, err := NewRecursiveAggregatorCircuit(chunkCCS, vk, 2, tt.minCorrect)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ccs, err = Compile(BackendPlonk, RecursionOuterCurve, circuit); err != nil {
+					t.Fatal(err)
+				}
+				aggregators[tt.minCorrect] = ccs
+			}
+			assignment, err := BuildRecursiveAggregatorWitness(proofs, tt.publics)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := solved(t, ccs, assignment); tt.ok != (err == nil) {
+				t.Errorf("%v, want satisfied = %v", err, tt.ok)
+			}
+		})
+	}
+}
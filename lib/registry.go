@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// CircuitOptions are the pipeline settings a registered circuit is built
+// from. Circuits ignore the options that do not apply to them.
+type CircuitOptions struct {
+	// LUT is the sigmoid table (see LoadSigmoidTable); nil computes it.
+	LUT []int64
+	// IncludeBorderline selects the chunk circuit that counts every sample.
+	IncludeBorderline bool
+	// MinCorrect is the aggregator policy; zero means DefaultMinCorrect.
+	MinCorrect int
+}
+
+// CircuitSpec registers a circuit with the pipeline: how to build it and
+// which cache file its compiled form and keys live in.
+type CircuitSpec struct {
+	Name string
+	// Stage is the pipeline stage that proves with the circuit.
+	Stage CircuitSet
+	// New returns the circuit to compile for opts.
+	New func(opts CircuitOptions) frontend.Circuit
+	// CacheFile names the cache file for opts, relative to the cache
+	// directory. Options that change the compiled circuit must change it.
+	CacheFile func(opts CircuitOptions) string
+}
+
+var registry []CircuitSpec
+
+func init() {
+	RegisterCircuit(CircuitSpec{
+		Name:      "linear",
+		Stage:     CircuitsPerSample,
+		New:       func(CircuitOptions) frontend.Circuit { return &LinearCircuit{} },
+		CacheFile: func(CircuitOptions) string { return LinearCacheFile },
+	})
+	RegisterCircuit(CircuitSpec{
+		Name:      "sigmoid",
+		Stage:     CircuitsPerSample,
+		New:       func(o CircuitOptions) frontend.Circuit { return &SigmoidCircuit{LUT: o.LUT} },
+		CacheFile: func(CircuitOptions) string { return SigmoidCacheFile },
+	})
+	RegisterCircuit(CircuitSpec{
+		Name:      "inference",
+		Stage:     CircuitsInference,
+		New:       func(o CircuitOptions) frontend.Circuit { return &InferenceCircuit{LUT: o.LUT} },
+		CacheFile: func(CircuitOptions) string { return InferenceCacheFile },
+	})
+	RegisterCircuit(CircuitSpec{
+		Name:      "chunk",
+		Stage:     CircuitsAccuracy,
+		New:       func(o CircuitOptions) frontend.Circuit { return NewAccuracyChunkCircuit(!o.IncludeBorderline) },
+		CacheFile: func(o CircuitOptions) string { return chunkCacheFile(o.IncludeBorderline) },
+	})
+	RegisterCircuit(CircuitSpec{
+		Name:      "aggregator",
+		Stage:     CircuitsAccuracy,
+		New:       func(o CircuitOptions) frontend.Circuit { return NewAggregatorCircuit(o.MinCorrect) },
+		CacheFile: func(o CircuitOptions) string { return aggregatorCacheFile(o.MinCorrect) },
+	})
+}
+
+// RegisterCircuit adds a circuit to those warmed, dry-run and profiled, in
+// registration order. It panics if the name is empty or already taken, as
+// that is a programming error.
+func RegisterCircuit(spec CircuitSpec) {
+	if spec.Name == "" || spec.New == nil || spec.CacheFile == nil {
+		panic("lib: RegisterCircuit needs a name, New and CacheFile")
+	}
+	if _, ok := lookupCircuit(spec.Name); ok {
+		panic(fmt.Sprintf("lib: circuit %q registered twice", spec.Name))
+	}
+	registry = append(registry, spec)
+}
+
+// Circuits returns the registered circuits of the given stages, in
+// registration order.
+func Circuits(stages CircuitSet) []CircuitSpec {
+	var specs []CircuitSpec
+	for _, spec := range registry {
+		if stages&spec.Stage != 0 {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+func lookupCircuit(name string) (CircuitSpec, bool) {
+	for _, spec := range registry {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return CircuitSpec{}, false
+}
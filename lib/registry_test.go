@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestRegisteredCircuitsCompile(t *testing.T) {
+	options := []CircuitOptions{
+		{},
+		{LUT: ComputeSigmoidTable(DefaultLUTConfig()), IncludeBorderline: true, MinCorrect: 90},
+	}
+	files := map[string]string{}
+	for _, spec := range Circuits(CircuitsAll) {
+		for _, opts := range options {
+			t.Run(spec.Name, func(t *testing.T) {
+				ccs := compiled(t, spec.New(opts))
+				if ccs.GetNbConstraints() == 0 {
+					t.Error("no constraints")
+				}
+				file := spec.CacheFile(opts)
+				if other, ok := files[file]; ok && other != ConfigHash(spec.New(opts)) {
+					t.Errorf("cache file %s shared by differently compiled circuits", file)
+				}
+				files[file] = ConfigHash(spec.New(opts))
+			})
+		}
+	}
+}
+
+func TestCircuitsByStage(t *testing.T) {
+	tests := []struct {
+		stages CircuitSet
+		want   []string
+	}{
+		{CircuitsPerSample, []string{"linear", "sigmoid"}},
+		{CircuitsInference, []string{"inference"}},
+		{CircuitsAccuracy, []string{"chunk", "aggregator"}},
+		{CircuitsPerSample | CircuitsAccuracy, []string{"linear", "sigmoid", "chunk", "aggregator"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, spec := range Circuits(tt.stages) {
+			got = append(got, spec.Name)
+		}
+		if len(got) < len(tt.want) {
+			t.Errorf("stages %v: %v, want at least %v", tt.stages, got, tt.want)
+			continue
+		}
+		for i, name := range tt.want {
+			if got[i] != name {
+				t.Errorf("stages %v: %v, want %v first", tt.stages, got, tt.want)
+				break
+			}
+		}
+		for _, spec := range Circuits(tt.stages) {
+			if tt.stages&spec.Stage == 0 {
+				t.Errorf("stages %v include %s of stage %v", tt.stages, spec.Name, spec.Stage)
+			}
+		}
+	}
+}
+
+func TestRegisterCircuitRejectsDuplicates(t *testing.T) {
+	linear := func(CircuitOptions) frontend.Circuit { return &LinearCircuit{} }
+	file := func(CircuitOptions) string { return "x.cache" }
+	tests := []struct {
+		name string
+		spec CircuitSpec
+	}{
+		{"taken name", CircuitSpec{Name: "linear", New: linear, CacheFile: file}},
+		{"no name", CircuitSpec{New: linear, CacheFile: file}},
+		{"no New", CircuitSpec{Name: "new", CacheFile: file}},
+		{"no CacheFile", CircuitSpec{Name: "new", New: linear}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("registered")
+				}
+			}()
+			RegisterCircuit(tt.spec)
+		})
+	}
+}
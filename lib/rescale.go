@@ -0,0 +1,32 @@
+package lib
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
+)
+
+// RescaleInt converts a signed fixed-point value from fromPrec to toPrec
+// fractional bits off-chain, flooring when the precision drops; see
+// fixedpoint.RescaleInt. It matches RescaleVar for every v.
+func RescaleInt(v *big.Int, fromPrec, toPrec uint) *big.Int {
+	return fixedpoint.RescaleInt(v, fromPrec, toPrec)
+}
+
+// RescaleVar converts a signed fixed-point variable from fromPrec to toPrec
+// fractional bits in-circuit: a multiplication by 2^(toPrec-fromPrec) when
+// the precision rises, and the range-checked floor division of
+// divFloorPow2 by 2^(fromPrec-toPrec) when it drops, so the result is the
+// unique RescaleInt of v. A drop of more than 64 bits panics, as DivChecked
+// does.
+func RescaleVar(api frontend.API, v frontend.Variable, fromPrec, toPrec uint) frontend.Variable {
+	switch {
+	case toPrec > fromPrec:
+		return api.Mul(v, new(big.Int).Lsh(big.NewInt(1), toPrec-fromPrec))
+	case toPrec < fromPrec:
+		return divFloorPow2(api, v, int(fromPrec-toPrec))
+	}
+	return v
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
+)
+
+// rescaleCircuit asserts Want = RescaleVar(V, from, to).
+type rescaleCircuit struct {
+	V    frontend.Variable
+	Want frontend.Variable `gnark:",public"`
+
+	from, to uint
+}
+
+func (c *rescaleCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(RescaleVar(api, c.V, c.from, c.to), c.Want)
+	return nil
+}
+
+func TestRescaleVarMatchesRescaleInt(t *testing.T) {
+	conversions := []struct {
+		name     string
+		from, to uint
+	}{
+		{"Q10 to Q32", fixedpoint.InputPrecision, fixedpoint.Precision},
+		{"Q32 to Q10", fixedpoint.Precision, fixedpoint.InputPrecision},
+		{"Q32 to Q16", fixedpoint.Precision, fixedpoint.OutputPrecision},
+		{"Q64 to Q32", 2 * fixedpoint.Precision, fixedpoint.Precision},
+		{"Q16 to Q16", fixedpoint.OutputPrecision, fixedpoint.OutputPrecision},
+	}
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(-1),
+		NewScaled(2.75),
+		NewScaled(-2.75),
+		new(big.Int).Sub(NewScaled(-7), big.NewInt(1)),
+	}
+	for _, conv := range conversions {
+		ccs := compiled(t, &rescaleCircuit{from: conv.from, to: conv.to})
+		for _, v := range values {
+			t.Run(fmt.Sprintf("%s/%s", conv.name, v), func(t *testing.T) {
+				want := RescaleInt(v, conv.from, conv.to)
+				if err := solved(t, ccs, &rescaleCircuit{V: v, Want: want}); err != nil {
+					t.Errorf("RescaleVar != RescaleInt = %s: %v", want, err)
+				}
+				// the result is unique: one more is rejected
+				if err := solved(t, ccs, &rescaleCircuit{V: v, Want: new(big.Int).Add(want, big.NewInt(1))}); err == nil {
+					t.Errorf("RescaleVar also accepted %s + 1", want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Save writes r to filename as JSON, so runs can be archived as evidence of
+// an accuracy claim or compared over time. The proofs themselves are not
+// included; see ProofEnvelope for those.
+func (r PipelineResult) Save(filename string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("saving result: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("saving result: %w", err)
+	}
+	return nil
+}
+
+// LoadResult reads a PipelineResult written by Save.
+func LoadResult(filename string) (PipelineResult, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return PipelineResult{}, fmt.Errorf("loading result: %w", err)
+	}
+	var r PipelineResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return PipelineResult{}, fmt.Errorf("loading result %s: %w", filename, err)
+	}
+	return r, nil
+}
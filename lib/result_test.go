@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fullResult returns a PipelineResult with every field set.
+func fullResult() PipelineResult {
+	return PipelineResult{
+		TotalSamples:        100,
+		Saturated:           3,
+		RecommendedMaxInput: 16,
+		Disagreements: []Disagreement{
+			{SampleNum: 7, Marks: 59.42, Label: 1, Z: 1e-9, QuantizedZ: -2.3e-10, FloatPrediction: 1, CircuitPrediction: 0},
+		},
+		ProofsGenerated: 98,
+		Resumed:         10,
+		Verified:        97,
+		Timings:         SampleTimings{Count: 88, LinearWitness: time.Millisecond, LinearProve: 2 * time.Second, SigmoidWitness: 3 * time.Microsecond, SigmoidProve: 4 * time.Minute},
+		VerifyTime:      1500 * time.Millisecond,
+		Samples: []SampleOutcome{
+			{SampleNum: 1, Mark: 12.5, Label: 1, Proved: true, Verified: true},
+			{SampleNum: 2, Mark: 69, Label: 0, Proved: true, Verified: false, Error: "sigmoid: verification failed"},
+		},
+		Partial:           true,
+		InferenceVerified: 96,
+		ChunkCounts:       []int{24, 25, 23, 22},
+		ChunksReused:      2,
+		AccuracySamples:   100,
+		Layout:            ChunkLayout{TotalSamples: 110, First: 10, Samples: 100, Chunks: 4, Padding: 1},
+		MinCorrect:        97,
+		TotalCorrect:      94,
+		Margin:            AccuracyMargin{Total: 94, Threshold: 97, Margin: -3},
+		AccuracyVerified:  true,
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	// every field must be set, so one Save drops is caught
+	full := fullResult()
+	v := reflect.ValueOf(full)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			t.Fatalf("fullResult leaves %s unset", v.Type().Field(i).Name)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		result PipelineResult
+	}{
+		{"every field", full},
+		{"empty", PipelineResult{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "result.json")
+			if err := tt.result.Save(filename); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadResult(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.result) {
+				t.Errorf("loaded %+v\nwant %+v", got, tt.result)
+			}
+		})
+	}
+}
+
+func TestLoadResultErrors(t *testing.T) {
+	dir := t.TempDir()
+	malformed := filepath.Join(dir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"total_samples": "many"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadResult(malformed); err == nil {
+		t.Error("malformed result loaded")
+	}
+	if _, err := LoadResult(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: %v, want os.ErrNotExist", err)
+	}
+	if err := fullResult().Save(filepath.Join(dir, "no", "such", "dir.json")); err == nil {
+		t.Error("saved into a missing directory")
+	}
+}
@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// ============================================================================
+// CIRCUIT 6: Robustness Circuit
+// Proves, without revealing W and B, that the model's prediction for a public
+// mark X does not change when X is perturbed by a public Epsilon either way.
+// ============================================================================
+
+type RobustnessCircuit struct {
+	W       frontend.Variable
+	B       frontend.Variable
+	X       frontend.Variable `gnark:",public"`
+	Epsilon frontend.Variable `gnark:",public"` // Q32, >= 0
+	// ModelCommitment binds the proof to a committed model, as in
+	// LinearCircuit.
+	ModelCommitment frontend.Variable `gnark:",public"`
+
+	// Threshold is the compiled-in Q16 decision threshold; see SigmoidCircuit.
+	Threshold int64 `gnark:"-"`
+	// LUT optionally supplies precomputed table values; see SigmoidCircuit.
+	LUT []int64 `gnark:"-"`
+
+	table *sigmoidTable
+}
+
+func (c *RobustnessCircuit) Define(api frontend.API) error {
+	if c.table == nil {
+		table, err := newSigmoidTable(api, c.LUT)
+		if err != nil {
+			return err
+		}
+		c.table = table
+	}
+	if err := assertModelCommitment(api, c.W, c.B, c.ModelCommitment); err != nil {
+		return err
+	}
+
+	// Epsilon >= 0, so X-Epsilon <= X <= X+Epsilon; z is linear in the mark,
+	// so the prediction is then constant on the whole interval.
+	epsNeg := isNegative(api, c.Epsilon)
+	api.AssertIsEqual(epsNeg, 0)
+
+	w := New(api, c.W)
+	b := New(api, c.B)
+	x := New(api, c.X)
+	eps := New(api, c.Epsilon)
+	threshold := thresholdOrDefault(c.Threshold)
+	pLow, _ := sigmoidPredict(api, c.table, w.Mul(x.Sub(eps)).Add(b).Val, threshold)
+	p, _ := sigmoidPredict(api, c.table, w.Mul(x).Add(b).Val, threshold)
+	pHigh, _ := sigmoidPredict(api, c.table, w.Mul(x.Add(eps)).Add(b).Val, threshold)
+
+	api.AssertIsEqual(pLow, p)
+	api.AssertIsEqual(pHigh, p)
+	return nil
+}
@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRobustnessCircuit(t *testing.T) {
+	ccs := compiled(t, &RobustnessCircuit{})
+	tests := []struct {
+		name       string
+		x, eps     float64
+		commitment *big.Int
+		robust     bool
+	}{
+		{"far from the boundary", 50, 1, nil, true},
+		{"far below the boundary", 5, 10, nil, true},
+		{"no perturbation at the boundary", 20, 0, nil, true},
+		{"stays below the boundary", 19.5, 0.4, nil, true},
+		{"reaches the boundary", 19.5, 0.5, nil, true}, // z = 0 still predicts 1
+		{"crosses the boundary upward", 19.5, 0.6, nil, false},
+		{"crosses the boundary downward", 20.5, 1, nil, false},
+		{"negative epsilon", 50, -1, nil, false},
+		{"other model's commitment", 50, 1, ModelCommitment(testModel.w, testModel.b+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commitment := tt.commitment
+			if commitment == nil {
+				commitment = testCommitment()
+			}
+			err := solved(t, ccs, &RobustnessCircuit{
+				W:               NewScaled(testModel.w),
+				B:               NewScaled(testModel.b),
+				X:               NewScaled(tt.x),
+				Epsilon:         NewScaled(tt.eps),
+				ModelCommitment: commitment,
+			})
+			if tt.robust && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.robust && err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
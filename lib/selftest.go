@@ -0,0 +1,138 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// KnownAnswer is a hardcoded test vector: model parameters W and B, a mark X,
+// the z = W*X + B the linear circuit must compute, and the prediction the
+// sigmoid circuits must make (1 when z >= 0).
+type KnownAnswer struct {
+	W, B, X    float64
+	Z          float64
+	Prediction int
+}
+
+// KnownAnswers are the vectors SelfTest proves. The values are exact in
+// binary, so the expected Z is exact in Q32.
+var KnownAnswers = []KnownAnswer{
+	{W: -0.5, B: 20, X: 30, Z: 5, Prediction: 1},
+	{W: -0.5, B: 20, X: 50, Z: -5, Prediction: 0},
+	{W: 0.25, B: -2, X: 8, Z: 0, Prediction: 1},        // decision boundary
+	{W: -0.125, B: 10, X: 100, Z: -2.5, Prediction: 0}, // inside the LUT
+	{W: 1.5, B: -100, X: 40, Z: -40, Prediction: 0},    // saturates low
+	{W: -2, B: 150, X: 70, Z: 10, Prediction: 1},       // saturates high
+}
+
+// SelfTestResult is the outcome of one known-answer check.
+type SelfTestResult struct {
+	Circuit string
+	Vector  int
+	Err     error
+}
+
+// SelfTest sets up the linear, sigmoid and inference circuits from scratch
+// (no caches, no dataset) and, for every KnownAnswer, checks the off-chain
+// Q32 mirror, proves and verifies the expected values and confirms that the
+// wrong Z or prediction cannot be proved. The error wraps ErrSelfTest if any
+// check failed; setup failures are returned as they are.
+func SelfTest() ([]SelfTestResult, error) {
+	linearSCS, linearPK, linearVK, err := Setup(&LinearCircuit{})
+	if err != nil {
+		return nil, fmt.Errorf("linear circuit: %w", err)
+	}
+	sigmoidSCS, sigmoidPK, sigmoidVK, err := Setup(&SigmoidCircuit{})
+	if err != nil {
+		return nil, fmt.Errorf("sigmoid circuit: %w", err)
+	}
+	inferenceSCS, inferencePK, inferenceVK, err := Setup(&InferenceCircuit{})
+	if err != nil {
+		return nil, fmt.Errorf("inference circuit: %w", err)
+	}
+
+	var results []SelfTestResult
+	failed := 0
+	record := func(circuit string, i int, err error) {
+		if err != nil {
+			failed++
+		}
+		results = append(results, SelfTestResult{Circuit: circuit, Vector: i + 1, Err: err})
+	}
+
+	for i, v := range KnownAnswers {
+		w, b, x := NewScaled(v.W), NewScaled(v.B), NewScaled(v.X)
+		z := NewScaled(v.Z)
+		m := modelCommitment(DefaultCurve.ScalarField(), w, b)
+		wrongZ := new(big.Int).Add(z, big.NewInt(1))
+		label, wrongLabel := big.NewInt(int64(v.Prediction)), big.NewInt(int64(1-v.Prediction))
+
+		record("quantized", i, checkQuantized(v))
+		record("linear", i, checkKnownAnswer(linearSCS, linearPK, linearVK,
+			&LinearCircuit{W: w, B: b, X: x, Z: z, ModelCommitment: m},
+			&LinearCircuit{W: w, B: b, X: x, Z: wrongZ, ModelCommitment: m}))
+		record("sigmoid", i, checkKnownAnswer(sigmoidSCS, sigmoidPK, sigmoidVK,
+			&SigmoidCircuit{Z: z, Label: label},
+			&SigmoidCircuit{Z: z, Label: wrongLabel}))
+		record("inference", i, checkKnownAnswer(inferenceSCS, inferencePK, inferenceVK,
+			&InferenceCircuit{W: w, B: b, X: x, Label: label, ModelCommitment: m},
+			&InferenceCircuit{W: w, B: b, X: x, Label: wrongLabel, ModelCommitment: m}))
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%w: %d of %d checks failed", ErrSelfTest, failed, len(results))
+	}
+	return results, nil
+}
+
+// checkQuantized checks the off-chain mirrors the pipeline fills witnesses
+// from against v.
+func checkQuantized(v KnownAnswer) error {
+	if got := LinearZ(NewScaled(v.W), NewScaled(v.B), v.X); got.Cmp(NewScaled(v.Z)) != 0 {
+		return fmt.Errorf("LinearZ = %s, want %s", got, NewScaled(v.Z))
+	}
+	if got := utils.PredictQuantized(v.W, v.B, v.X); got != v.Prediction {
+		return fmt.Errorf("PredictQuantized = %d, want %d", got, v.Prediction)
+	}
+	return nil
+}
+
+// checkKnownAnswer proves and verifies good, and checks that bad, which
+// differs from it in one public value, cannot be proved.
+func checkKnownAnswer(ccs constraint.ConstraintSystem, pk plonk.ProvingKey, vk plonk.VerifyingKey, good, bad frontend.Circuit) error {
+	full, err := frontend.NewWitness(good, ccs.Field())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWitness, err)
+	}
+	public, err := full.Public()
+	if err != nil {
+		return fmt.Errorf("%w: public: %w", ErrWitness, err)
+	}
+	proof, err := plonk.Prove(ccs, pk, full)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProve, err)
+	}
+	if err := Verify(proof, vk, public); err != nil {
+		return err
+	}
+
+	badFull, err := frontend.NewWitness(bad, ccs.Field())
+	if err != nil {
+		return fmt.Errorf("%w: wrong answer: %w", ErrWitness, err)
+	}
+	// the solver logs the unsatisfied constraint; that is the expected outcome
+	saved := logger.Logger()
+	logger.Disable()
+	_, err = plonk.Prove(ccs, pk, badFull)
+	logger.Set(saved)
+	if err == nil {
+		return fmt.Errorf("a proof with the wrong answer was generated")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// TestKnownAnswers checks every KnownAnswer against the off-chain mirrors
+// and the linear, sigmoid and inference constraint systems: the expected
+// answer satisfies each circuit and the wrong one does not. SelfTest proves
+// the same vectors; this runs them without a setup.
+func TestKnownAnswers(t *testing.T) {
+	linear := compiled(t, &LinearCircuit{})
+	sigmoid := compiled(t, &SigmoidCircuit{})
+	inference := compiled(t, &InferenceCircuit{})
+
+	for i, v := range KnownAnswers {
+		w, b, x, z := NewScaled(v.W), NewScaled(v.B), NewScaled(v.X), NewScaled(v.Z)
+		m := ModelCommitment(v.W, v.B)
+		wrongZ := new(big.Int).Add(z, big.NewInt(1))
+
+		if err := checkQuantized(v); err != nil {
+			t.Errorf("vector %d: %v", i+1, err)
+		}
+		for _, c := range []struct {
+			name         string
+			solve        func(frontend.Circuit) error
+			right, wrong frontend.Circuit
+		}{
+			{"linear", func(a frontend.Circuit) error { return solved(t, linear, a) },
+				&LinearCircuit{W: w, B: b, X: x, Z: z, ModelCommitment: m},
+				&LinearCircuit{W: w, B: b, X: x, Z: wrongZ, ModelCommitment: m}},
+			{"sigmoid", func(a frontend.Circuit) error { return solved(t, sigmoid, a) },
+				&SigmoidCircuit{Z: z, Label: v.Prediction},
+				&SigmoidCircuit{Z: z, Label: 1 - v.Prediction}},
+			{"inference", func(a frontend.Circuit) error { return solved(t, inference, a) },
+				&InferenceCircuit{W: w, B: b, X: x, Label: v.Prediction, ModelCommitment: m},
+				&InferenceCircuit{W: w, B: b, X: x, Label: 1 - v.Prediction, ModelCommitment: m}},
+		} {
+			if err := c.solve(c.right); err != nil {
+				t.Errorf("vector %d %s: expected answer rejected: %v", i+1, c.name, err)
+			}
+			if err := c.solve(c.wrong); err == nil {
+				t.Errorf("vector %d %s: wrong answer accepted", i+1, c.name)
+			}
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	heavy(t, "sets up the linear, sigmoid and inference circuits")
+	results, err := SelfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 4 * len(KnownAnswers); len(results) != want {
+		t.Errorf("%d results, want %d", len(results), want)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("vector %d %s: %v", r.Vector, r.Circuit, r.Err)
+		}
+	}
+}
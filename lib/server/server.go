@@ -0,0 +1,304 @@
+// Package server proves and verifies the registered ZKLR circuits over
+// HTTP, so a client without the proving keys can use them as a service.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/santhoshcheemala/ZKLR/lib"
+)
+
+// maxRequestBytes bounds a request body; witnesses and proofs of the
+// registered circuits take a few kilobytes at most.
+const maxRequestBytes = 1 << 20
+
+// ProveRequest asks for a proof of Circuit for a full witness in
+// lib.MarshalWitness's encoding.
+type ProveRequest struct {
+	Circuit string `json:"circuit"`
+	Witness []byte `json:"witness"`
+}
+
+// ProveResponse holds the proof, as written by its WriteTo.
+type ProveResponse struct {
+	Proof []byte `json:"proof"`
+}
+
+// VerifyRequest asks whether Proof verifies for Circuit against a public
+// witness in lib.MarshalPublicWitness's encoding.
+type VerifyRequest struct {
+	Circuit       string `json:"circuit"`
+	Proof         []byte `json:"proof"`
+	PublicWitness []byte `json:"public_witness"`
+}
+
+// VerifyResponse reports the outcome; Error says why a proof was rejected.
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// errorResponse is the body of every non-200 response.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Options pick the server's curve and backend and bound its proving load. Proving is
+// CPU- and memory-heavy, so beyond the bounds a request is turned away with
+// HTTP 429 instead of adding to the load.
+type Options struct {
+	// Curve is the curve the circuits are set up, proved and verified on;
+	// zero means lib.DefaultCurve. Keys for other curves are cached in
+	// cacheDir/<curve>, as the pipeline caches them.
+	Curve ecc.ID
+	// Backend is the proof system the circuits are set up and proved with.
+	// Keys for Groth16 are cached in a groth16 directory after the curve's,
+	// as the pipeline caches them.
+	Backend lib.Backend
+	// ProveBudget, if positive, pads every proof to take at least that long;
+	// see lib.CircuitKeys.ProveBudget.
+	ProveBudget time.Duration
+	// MaxConcurrentProofs is how many proofs run at once; zero means 1.
+	MaxConcurrentProofs int
+	// MaxQueuedProofs is how many more prove requests wait for a free slot.
+	// Zero turns away every request that arrives while all slots are busy.
+	MaxQueuedProofs int
+	// Logf receives warnings, such as a cache directory that cannot be
+	// written; nil means log.Printf.
+	Logf func(format string, args ...any)
+}
+
+// Metrics is a snapshot of the proving load, served by GET /metrics.
+type Metrics struct {
+	// Proving is the number of proofs running and Queued the number of
+	// prove requests waiting for a slot, i.e. the queue depth.
+	Proving int64 `json:"proving"`
+	Queued  int64 `json:"queued"`
+	// Rejected counts the prove requests turned away with HTTP 429 since
+	// the server started.
+	Rejected int64 `json:"rejected"`
+}
+
+// Server proves and verifies proofs on its curve and backend for the
+// circuits whose keys it loaded when it was created.
+type Server struct {
+	curve    ecc.ID
+	backend  lib.Backend
+	circuits map[string]*lib.CircuitKeys
+
+	// admitted holds a token for every prove request running or queued,
+	// slots one for every proof running.
+	admitted chan struct{}
+	slots    chan struct{}
+
+	proving, queued, rejected atomic.Int64
+}
+
+// New loads the keys of the named registered circuits from their caches in
+// cacheDir, as lib.LoadOrSetupBackend does, setting up and caching any that
+// are missing. Circuits are built with default options, as warmup builds
+// them.
+func New(cacheDir string, names []string, opts Options) (*Server, error) {
+	if opts.Curve == ecc.UNKNOWN {
+		opts.Curve = lib.DefaultCurve
+	}
+	if opts.MaxConcurrentProofs == 0 {
+		opts.MaxConcurrentProofs = 1
+	}
+	if opts.MaxConcurrentProofs < 0 || opts.MaxQueuedProofs < 0 {
+		return nil, fmt.Errorf("proof limits must not be negative, got %d running and %d queued", opts.MaxConcurrentProofs, opts.MaxQueuedProofs)
+	}
+
+	if opts.Logf == nil {
+		opts.Logf = log.Printf
+	}
+
+	// the table is valid even when it could not be cached, as in the
+	// pipeline
+	lut, err := lib.LoadSigmoidTable(cacheDir, lib.DefaultLUTConfig())
+	if err != nil {
+		opts.Logf("Warning: %v\n", err)
+	}
+	circuitOpts := lib.CircuitOptions{LUT: lut}
+	specs := make(map[string]lib.CircuitSpec)
+	for _, spec := range lib.Circuits(lib.CircuitsAll) {
+		specs[spec.Name] = spec
+	}
+
+	keyDir := cacheDir
+	if opts.Curve != lib.DefaultCurve {
+		keyDir = filepath.Join(keyDir, opts.Curve.String())
+	}
+	if opts.Backend != lib.BackendPlonk {
+		keyDir = filepath.Join(keyDir, opts.Backend.String())
+	}
+
+	s := &Server{
+		curve:    opts.Curve,
+		backend:  opts.Backend,
+		circuits: make(map[string]*lib.CircuitKeys),
+		admitted: make(chan struct{}, opts.MaxConcurrentProofs+opts.MaxQueuedProofs),
+		slots:    make(chan struct{}, opts.MaxConcurrentProofs),
+	}
+	for _, name := range names {
+		spec, ok := specs[name]
+		if !ok {
+			return nil, fmt.Errorf("%s circuit: not registered", name)
+		}
+		keys, err := lib.LoadOrSetupBackend(opts.Backend, opts.Curve, filepath.Join(keyDir, spec.CacheFile(circuitOpts)), spec.New(circuitOpts))
+		if err != nil {
+			return nil, fmt.Errorf("%s circuit: %w", name, err)
+		}
+		keys.ProveBudget = opts.ProveBudget
+		s.circuits[name] = keys
+	}
+	return s, nil
+}
+
+// Handler returns the server's routes: POST /prove and POST /verify, each
+// taking and returning JSON, and GET /metrics.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /prove", s.prove)
+	mux.HandleFunc("POST /verify", s.verify)
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Metrics())
+	})
+	return mux
+}
+
+// Metrics returns the current proving load.
+func (s *Server) Metrics() Metrics {
+	return Metrics{Proving: s.proving.Load(), Queued: s.queued.Load(), Rejected: s.rejected.Load()}
+}
+
+// acquire waits for a proving slot, returning the function that releases
+// it. It returns nil, having written the response, if the queue is full or
+// the client gives up while queued.
+func (s *Server) acquire(w http.ResponseWriter, r *http.Request) func() {
+	select {
+	case s.admitted <- struct{}{}:
+	default:
+		s.rejected.Add(1)
+		w.Header().Set("Retry-After", "1")
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{"proving queue is full"})
+		return nil
+	}
+
+	s.queued.Add(1)
+	select {
+	case s.slots <- struct{}{}:
+		s.queued.Add(-1)
+	case <-r.Context().Done():
+		// the client is gone, so there is no one to respond to
+		s.queued.Add(-1)
+		<-s.admitted
+		return nil
+	}
+	s.proving.Add(1)
+	return func() {
+		s.proving.Add(-1)
+		<-s.slots
+		<-s.admitted
+	}
+}
+
+func (s *Server) prove(w http.ResponseWriter, r *http.Request) {
+	var req ProveRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	keys, ok := s.keys(w, req.Circuit)
+	if !ok {
+		return
+	}
+	full, err := lib.UnmarshalWitnessCurve(s.curve, req.Witness)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+	release := s.acquire(w, r)
+	if release == nil {
+		return
+	}
+	defer release()
+	proof, err := keys.Prove(full)
+	if err != nil {
+		// e.g. a witness that does not satisfy the circuit
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{err.Error()})
+		return
+	}
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, ProveResponse{Proof: buf.Bytes()})
+}
+
+func (s *Server) verify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	keys, ok := s.keys(w, req.Circuit)
+	if !ok {
+		return
+	}
+	proof, err := lib.ReadProof(s.backend, s.curve, req.Proof)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("decoding proof: %v", err)})
+		return
+	}
+	public, err := lib.UnmarshalPublicWitnessCurve(s.curve, req.PublicWitness)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+	if err := keys.Verify(proof, public); err != nil {
+		writeJSON(w, http.StatusOK, VerifyResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, VerifyResponse{Valid: true})
+}
+
+// decodeRequest reads the JSON request body into req, writing the error
+// response itself if it cannot.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, status, errorResponse{fmt.Sprintf("decoding request: %v", err)})
+		return false
+	}
+	return true
+}
+
+// keys returns the keys of the named circuit, writing a 404 if it is not
+// served.
+func (s *Server) keys(w http.ResponseWriter, circuit string) (*lib.CircuitKeys, bool) {
+	keys, ok := s.circuits[circuit]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{fmt.Sprintf("circuit %q is not served", circuit)})
+	}
+	return keys, ok
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
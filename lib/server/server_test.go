@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/santhoshcheemala/ZKLR/lib"
+)
+
+// newTestServer serves the linear circuit on curve from a fresh cache
+// directory on an ephemeral port.
+func newTestServer(t *testing.T, curve ecc.ID, opts Options) (*Server, *httptest.Server) {
+	t.Helper()
+	opts.Curve = curve
+	s, err := New(t.TempDir(), []string{"linear"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// post sends req as JSON to url and decodes the response into resp,
+// returning the status code.
+func post(t *testing.T, url string, req, resp any) int {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+		t.Fatalf("decoding %s response: %v", url, err)
+	}
+	return r.StatusCode
+}
+
+// linearRequest returns a prove request for the linear circuit on curve
+// and the matching public witness.
+func linearRequest(t *testing.T, curve ecc.ID, x float64) (ProveRequest, []byte) {
+	t.Helper()
+	full, err := lib.LinearWitness(curve.ScalarField(), -0.5, 10, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := lib.MarshalWitness(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := lib.MarshalPublicWitness(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ProveRequest{Circuit: "linear", Witness: data}, public
+}
+
+func TestProveVerifyRoundTrip(t *testing.T) {
+	configs := []struct {
+		curve   ecc.ID
+		backend lib.Backend
+	}{
+		{ecc.BN254, lib.BackendPlonk},
+		{ecc.BLS12_381, lib.BackendPlonk},
+		{ecc.BN254, lib.BackendGroth16},
+	}
+	for _, cfg := range configs {
+		curve := cfg.curve
+		t.Run(curve.String()+"/"+cfg.backend.String(), func(t *testing.T) {
+			s, ts := newTestServer(t, curve, Options{Backend: cfg.backend})
+			req, public := linearRequest(t, curve, 30)
+			_, otherPublic := linearRequest(t, curve, 31)
+
+			var proved ProveResponse
+			if status := post(t, ts.URL+"/prove", req, &proved); status != http.StatusOK {
+				t.Fatalf("prove: status %d", status)
+			}
+			if m := s.Metrics(); m.Proving != 0 || m.Queued != 0 {
+				t.Errorf("after the proof: %+v, want no load", m)
+			}
+
+			tests := []struct {
+				name   string
+				public []byte
+				valid  bool
+			}{
+				{"own public witness", public, true},
+				{"other sample's public witness", otherPublic, false},
+			}
+			for _, tt := range tests {
+				var verified VerifyResponse
+				status := post(t, ts.URL+"/verify", VerifyRequest{Circuit: "linear", Proof: proved.Proof, PublicWitness: tt.public}, &verified)
+				if status != http.StatusOK || verified.Valid != tt.valid {
+					t.Errorf("%s: status %d, %+v, want valid = %v", tt.name, status, verified, tt.valid)
+				}
+			}
+		})
+	}
+}
+
+func TestNewUnwritableCache(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var warnings []string
+	s, err := New(filepath.Join(blocker, "cache"), []string{"linear"}, Options{
+		Logf: func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "sigmoid table cache") {
+		t.Errorf("warnings %q, want the sigmoid table one", warnings)
+	}
+
+	// the keys were set up without the cache and still prove
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	req, _ := linearRequest(t, ecc.BN254, 30)
+	var proved ProveResponse
+	if status := post(t, ts.URL+"/prove", req, &proved); status != http.StatusOK {
+		t.Errorf("prove: status %d", status)
+	}
+}
+
+func TestProveBudget(t *testing.T) {
+	const budget = 500 * time.Millisecond
+	_, ts := newTestServer(t, ecc.BN254, Options{Backend: lib.BackendGroth16, ProveBudget: budget})
+	req, _ := linearRequest(t, ecc.BN254, 30)
+	start := time.Now()
+	var proved ProveResponse
+	if status := post(t, ts.URL+"/prove", req, &proved); status != http.StatusOK {
+		t.Fatalf("prove: status %d", status)
+	}
+	if elapsed := time.Since(start); elapsed < budget {
+		t.Errorf("proof took %v, want at least the %v budget", elapsed, budget)
+	}
+}
+
+func TestProveErrors(t *testing.T) {
+	_, ts := newTestServer(t, ecc.BN254, Options{})
+	req, _ := linearRequest(t, ecc.BN254, 30)
+
+	tests := []struct {
+		name   string
+		req    ProveRequest
+		status int
+	}{
+		{"unknown circuit", ProveRequest{Circuit: "nope", Witness: req.Witness}, http.StatusNotFound},
+		{"malformed witness", ProveRequest{Circuit: "linear", Witness: []byte{1, 2, 3}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		var resp errorResponse
+		if status := post(t, ts.URL+"/prove", tt.req, &resp); status != tt.status || resp.Error == "" {
+			t.Errorf("%s: status %d, error %q, want %d", tt.name, status, resp.Error, tt.status)
+		}
+	}
+}
+
+// waitFor polls s until cond holds for its Metrics, failing after a while.
+func waitFor(t *testing.T, s *Server, cond func(Metrics) bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond(s.Metrics()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics %+v never reached the expected state", s.Metrics())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestProveQueueFull(t *testing.T) {
+	s, ts := newTestServer(t, ecc.BN254, Options{MaxConcurrentProofs: 1, MaxQueuedProofs: 1})
+	req, _ := linearRequest(t, ecc.BN254, 30)
+
+	// hold the only slot, then queue a second request behind it
+	running := s.acquire(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/prove", nil))
+	if running == nil {
+		t.Fatal("first request was not admitted")
+	}
+	queued := make(chan func())
+	go func() {
+		queued <- s.acquire(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/prove", nil))
+	}()
+	waitFor(t, s, func(m Metrics) bool { return m.Queued == 1 })
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.Post(ts.URL+"/prove", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	if r.StatusCode != http.StatusTooManyRequests || r.Header.Get("Retry-After") != "1" {
+		t.Errorf("status %d, Retry-After %q, want %d and 1", r.StatusCode, r.Header.Get("Retry-After"), http.StatusTooManyRequests)
+	}
+	if m := s.Metrics(); m != (Metrics{Proving: 1, Queued: 1, Rejected: 1}) {
+		t.Errorf("metrics %+v, want 1 proving, 1 queued, 1 rejected", m)
+	}
+
+	running()
+	next := <-queued
+	if next == nil {
+		t.Fatal("queued request was not admitted after the slot freed")
+	}
+	next()
+	if m := s.Metrics(); m != (Metrics{Rejected: 1}) {
+		t.Errorf("after releasing both: %+v, want only the rejection", m)
+	}
+}
+
+func TestProveQueueCancelled(t *testing.T) {
+	s, _ := newTestServer(t, ecc.BN254, Options{MaxConcurrentProofs: 1, MaxQueuedProofs: 1})
+	running := s.acquire(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/prove", nil))
+	if running == nil {
+		t.Fatal("first request was not admitted")
+	}
+	defer running()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan func())
+	go func() {
+		r := httptest.NewRequest(http.MethodPost, "/prove", nil).WithContext(ctx)
+		queued <- s.acquire(httptest.NewRecorder(), r)
+	}()
+	waitFor(t, s, func(m Metrics) bool { return m.Queued == 1 })
+	cancel()
+	if release := <-queued; release != nil {
+		t.Fatal("cancelled request got a slot")
+	}
+	if m := s.Metrics(); m != (Metrics{Proving: 1}) {
+		t.Errorf("metrics %+v, want only the running proof", m)
+	}
+}
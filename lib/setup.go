@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test/unsafekzg"
+)
+
+// Setup compiles the circuit and runs the PLONK setup against an unsafe
+// (development-only) KZG SRS sized for it, on DefaultCurve.
+func Setup(circuit frontend.Circuit) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	return SetupCurve(DefaultCurve, circuit)
+}
+
+// SetupCurve is Setup on the given curve.
+func SetupCurve(curve ecc.ID, circuit frontend.Circuit) (constraint.ConstraintSystem, plonk.ProvingKey, plonk.VerifyingKey, error) {
+	ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, circuit)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %w", ErrCircuitCompile, err)
+	}
+	pk, vk, err := setupCompiled(ccs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ccs, pk, vk, nil
+}
+
+// setupCompiled runs the PLONK setup of an already compiled SparseR1CS
+// against unsafekzg's SRS. That SRS is cached in memory by size, so within
+// one process equal constraint systems get equal verifying keys.
+func setupCompiled(ccs constraint.ConstraintSystem) (plonk.ProvingKey, plonk.VerifyingKey, error) {
+	defer drawingRandomness()()
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: srs: %w", ErrSetup, err)
+	}
+
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrSetup, err)
+	}
+	return pk, vk, nil
+}
+
+// Verify checks a proof against its verifying key and public witness.
+func Verify(proof plonk.Proof, vk plonk.VerifyingKey, publicWitness witness.Witness) error {
+	if err := plonk.Verify(proof, vk, publicWitness); err != nil {
+		return fmt.Errorf("%w: %w", ErrVerify, err)
+	}
+	return nil
+}
+
+// VerifyBytes is Verify for a BN254 PLONK proof, verifying key and public
+// witness received as bytes, e.g. by a verifier service: the proof and key as
+// written by their WriteTo methods and the witness by MarshalPublicWitness.
+func VerifyBytes(proofBytes, vkBytes, publicWitnessBytes []byte) error {
+	proof := plonk.NewProof(ecc.BN254)
+	if err := readAll(proof, proofBytes); err != nil {
+		return fmt.Errorf("%w: reading proof: %w", ErrVerify, err)
+	}
+	vk := plonk.NewVerifyingKey(ecc.BN254)
+	if err := readAll(vk, vkBytes); err != nil {
+		return fmt.Errorf("%w: reading verifying key: %w", ErrVerify, err)
+	}
+	public, err := UnmarshalPublicWitness(publicWitnessBytes)
+	if err != nil {
+		return fmt.Errorf("reading public witness: %w", err)
+	}
+	return Verify(proof, vk, public)
+}
+
+// readAll decodes data into r and rejects trailing bytes. gnark's decoders
+// can panic on malformed input, so a panic is reported as an error.
+func readAll(r io.ReaderFrom, data []byte) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("malformed data: %v", p)
+		}
+	}()
+	n, err := r.ReadFrom(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if n != int64(len(data)) {
+		return fmt.Errorf("%d trailing bytes", int64(len(data))-n)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writtenBytes returns v's WriteTo encoding.
+func writtenBytes(t *testing.T, v io.WriterTo) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := v.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestVerifyBytes(t *testing.T) {
+	ccs, pk, vk, err := Setup(&LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, otherVK, err := Setup(&AggregatorCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := LinearWitness(ccs.Field(), testModel.w, testModel.b, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := plonkKeys(ccs, pk, vk).Prove(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := LinearWitness(ccs.Field(), testModel.w, testModel.b, 31)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofBytes, vkBytes := writtenBytes(t, proof), writtenBytes(t, vk)
+	public, err := MarshalPublicWitness(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, err := MarshalPublicWitness(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fullBytes, err := MarshalWitness(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := slices.Clone(proofBytes)
+	flipped[len(flipped)/2] ^= 1
+
+	tests := []struct {
+		name              string
+		proof, vk, public []byte
+		sentinel          error // nil if valid
+		wantMsg           string
+	}{
+		{"valid", proofBytes, vkBytes, public, nil, ""},
+		{"truncated proof", proofBytes[:len(proofBytes)/2], vkBytes, public, ErrVerify, "reading proof"},
+		{"proof with trailing bytes", append(slices.Clone(proofBytes), 0), vkBytes, public, ErrVerify, "trailing bytes"},
+		{"proof with a flipped bit", flipped, vkBytes, public, ErrVerify, ""},
+		{"empty proof", nil, vkBytes, public, ErrVerify, "reading proof"},
+		{"truncated verifying key", proofBytes, vkBytes[:len(vkBytes)/2], public, ErrVerify, "reading verifying key"},
+		{"other circuit's verifying key", proofBytes, writtenBytes(t, otherVK), public, ErrVerify, ""},
+		{"other sample's public witness", proofBytes, vkBytes, otherPublic, ErrVerify, ""},
+		{"truncated public witness", proofBytes, vkBytes, public[:len(public)-1], ErrWitness, "reading public witness"},
+		{"full witness", proofBytes, vkBytes, fullBytes, ErrWitness, "secret values"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyBytes(tt.proof, tt.vk, tt.public)
+			if tt.sentinel == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.sentinel) || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %v, want %v containing %q", err, tt.sentinel, tt.wantMsg)
+			}
+		})
+	}
+}
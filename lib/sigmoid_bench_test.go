@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// BenchmarkSigmoid compares the LUT sigmoid (SigmoidCircuit with
+// DefaultLUTConfig) with the polynomial sigmoid (SigmoidPolyCircuit), both
+// with PLONK on DefaultCurve, over the bundled test set and model. Each
+// sub-benchmark times one proof per iteration and reports the constraint
+// count, setup time, proof size, and the largest error and number of
+// flipped predictions against the float64 sigmoid. The LUT is measured
+// exactly as the circuit computes it (QuantizedSigmoid); the polynomial
+// with SigmoidPoly at the quantized z, which ignores its Q32 rounding of
+// about 2^-32. Run it with
+//
+//	go test -run '^$' -bench BenchmarkSigmoid ./lib
+func BenchmarkSigmoid(b *testing.B) {
+	samples, err := utils.LoadDataset("../data/student_dataset_test.csv")
+	if err != nil {
+		b.Fatal(err)
+	}
+	w, bias, err := utils.LoadModelParameters("../data/best_model_parameters.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	wScaled, bScaled := NewScaled(w), NewScaled(bias)
+	zs := make([]*big.Int, len(samples))
+	for i, s := range samples {
+		zs[i] = LinearZ(wScaled, bScaled, s.Marks)
+	}
+	label := utils.PredictQuantized(w, bias, samples[0].Marks)
+
+	table := ComputeSigmoidTable(DefaultLUTConfig())
+	lutScale := float64(int64(1) << DefaultLUTConfig().OutputPrecision)
+	benches := []struct {
+		name    string
+		circuit frontend.Circuit
+		witness func(field *big.Int) (witness.Witness, error)
+		sigmoid func(z *big.Int) float64
+	}{
+		{
+			name:    "lut",
+			circuit: &SigmoidCircuit{},
+			witness: func(field *big.Int) (witness.Witness, error) { return sigmoidWitness(field, zs[0], label) },
+			sigmoid: func(z *big.Int) float64 { return float64(QuantizedSigmoid(DefaultLUTConfig(), table, z)) / lutScale },
+		},
+		{
+			name:    "poly",
+			circuit: &SigmoidPolyCircuit{},
+			witness: func(field *big.Int) (witness.Witness, error) {
+				full, err := frontend.NewWitness(&SigmoidPolyCircuit{Z: zs[0], Label: label}, field)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %w", ErrWitness, err)
+				}
+				return full, nil
+			},
+			sigmoid: func(z *big.Int) float64 { return SigmoidPoly(scaledToFloat(z)) },
+		},
+	}
+
+	for _, bench := range benches {
+		b.Run(bench.name, func(b *testing.B) {
+			start := time.Now()
+			k, err := SetupBackend(BackendPlonk, DefaultCurve, bench.circuit)
+			if err != nil {
+				b.Fatal(err)
+			}
+			setup := time.Since(start)
+
+			maxError, mismatches := 0.0, 0
+			for i, s := range samples {
+				reference := 1 / (1 + math.Exp(-(w*s.Marks + bias)))
+				approx := bench.sigmoid(zs[i])
+				maxError = max(maxError, math.Abs(approx-reference))
+				if (approx >= 0.5) != (reference >= 0.5) {
+					mismatches++
+				}
+			}
+
+			var proofBytes int64
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				full, err := bench.witness(k.CCS.Field())
+				if err != nil {
+					b.Fatal(err)
+				}
+				proof, err := k.Prove(full)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if proofBytes, err = proof.WriteTo(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(k.CCS.GetNbConstraints()), "constraints")
+			b.ReportMetric(setup.Seconds(), "setup-s")
+			b.ReportMetric(float64(proofBytes), "proof-bytes")
+			b.ReportMetric(maxError, "max-error")
+			b.ReportMetric(float64(mismatches), "mismatches")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// ============================================================================
+// CIRCUIT 2C: Polynomial Sigmoid Classification Circuit
+// Same statement as SigmoidCircuit but sigmoid is approximated by a piecewise
+// cubic instead of an 8193-entry lookup table.
+// ============================================================================
+
+// SigmoidPolyMaxError bounds |SigmoidPoly(z) - sigmoid(z)| over [-8, 8]
+// (measured 2.25e-3; saturation beyond |z| = 8 adds < 3.4e-4).
+const SigmoidPolyMaxError = 2.5e-3
+
+// Piecewise cubic on |z|, lowest-order coefficient first. The first segment
+// is 0.5 + t*q(t) so that sigmoid(0) is exactly 0.5 and the 0.5 threshold
+// still agrees with sign(z).
+var (
+	sigmoidPolyQ    = []float64{0.251038654011, -0.009078178488, -0.010901896893}                // [0, 2)
+	sigmoidPolyMid  = []float64{0.479822327693, 0.318946489661, -0.070078185806, 0.005433570046} // [2, 4)
+	sigmoidPolyTail = []float64{0.818267701026, 0.074894131079, -0.010412160046, 0.000485902895} // [4, 8]
+)
+
+type SigmoidPolyCircuit struct {
+	Z     frontend.Variable `gnark:",public"`
+	Label frontend.Variable `gnark:",public"`
+}
+
+func (circuit *SigmoidPolyCircuit) Define(api frontend.API) error {
+	sigmoid := sigmoidPolyEval(api, circuit.Z)
+
+	// Threshold at 0.5 (Q32)
+	half := new(big.Int).Lsh(big.NewInt(1), Precision-1)
+	cmpThresh := api.Cmp(sigmoid, half)
+	isLess := api.IsZero(api.Add(cmpThresh, 1)) // 1 if <
+	prediction := api.Sub(1, isLess)
+
+	api.AssertIsEqual(prediction, circuit.Label)
+	return nil
+}
+
+// sigmoidPolyEval returns the piecewise-cubic sigmoid of a Q32 z, in Q32.
+func sigmoidPolyEval(api frontend.API, z frontend.Variable) frontend.Variable {
+	isNeg := isNegative(api, z)
+	absZ := api.Select(isNeg, api.Neg(z), z)
+
+	// Saturate at |z| = 8
+	maxZ := NewScaled(8)
+	isSat := api.IsZero(api.Sub(1, api.Cmp(absZ, maxZ)))
+	t := New(api, api.Select(isSat, maxZ, absZ))
+
+	// Segment selection
+	inFirst := api.IsZero(api.Add(api.Cmp(t.Val, NewScaled(2)), 1)) // t < 2
+	inMid := api.IsZero(api.Add(api.Cmp(t.Val, NewScaled(4)), 1))   // t < 4
+
+	first := horner(api, sigmoidPolyQ, t).Mul(t).Add(New(api, NewScaled(0.5)))
+	mid := horner(api, sigmoidPolyMid, t)
+	tail := horner(api, sigmoidPolyTail, t)
+
+	p := api.Select(inFirst, first.Val, api.Select(inMid, mid.Val, tail.Val))
+
+	// Symmetry sigmoid(-x) = 1 - sigmoid(x)
+	return api.Select(isNeg, api.Sub(scalingFactor, p), p)
+}
+
+func horner(api frontend.API, coeffs []float64, t FixedPoint) FixedPoint {
+	acc := New(api, NewScaled(coeffs[len(coeffs)-1]))
+	for j := len(coeffs) - 2; j >= 0; j-- {
+		acc = acc.Mul(t).Add(New(api, NewScaled(coeffs[j])))
+	}
+	return acc
+}
+
+// SigmoidPoly evaluates the same piecewise cubic as SigmoidPolyCircuit in
+// float64, for comparing against the exact sigmoid.
+func SigmoidPoly(z float64) float64 {
+	t := z
+	if t < 0 {
+		t = -t
+	}
+	if t > 8 {
+		t = 8
+	}
+
+	var p float64
+	switch {
+	case t < 2:
+		p = 0.5 + t*hornerFloat(sigmoidPolyQ, t)
+	case t < 4:
+		p = hornerFloat(sigmoidPolyMid, t)
+	default:
+		p = hornerFloat(sigmoidPolyTail, t)
+	}
+
+	if z < 0 {
+		return 1 - p
+	}
+	return p
+}
+
+func hornerFloat(coeffs []float64, t float64) float64 {
+	acc := coeffs[len(coeffs)-1]
+	for j := len(coeffs) - 2; j >= 0; j-- {
+		acc = acc*t + coeffs[j]
+	}
+	return acc
+}
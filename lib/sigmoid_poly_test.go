@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"math"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestSigmoidPolyMaxError(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi float64
+		bound  float64
+	}{
+		{"first segment", -2, 2, SigmoidPolyMaxError},
+		{"middle segment", 2, 4, SigmoidPolyMaxError},
+		{"tail segment", 4, 8, SigmoidPolyMaxError},
+		{"negative tail", -8, -4, SigmoidPolyMaxError},
+		{"saturated", 8, 16, SigmoidPolyMaxError + 3.4e-4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worst := 0.0
+			for z := tt.lo; z <= tt.hi; z += 1.0 / 1024 {
+				worst = max(worst, math.Abs(SigmoidPoly(z)-1/(1+math.Exp(-z))))
+			}
+			if worst > tt.bound {
+				t.Errorf("max error %.3g over [%v, %v], want at most %.3g", worst, tt.lo, tt.hi, tt.bound)
+			}
+		})
+	}
+	if SigmoidPoly(0) != 0.5 {
+		t.Errorf("SigmoidPoly(0) = %v, want 0.5", SigmoidPoly(0))
+	}
+}
+
+// sigmoidPolyValueCircuit asserts sigmoidPolyEval(Z) is within 2^-20 of
+// Want, both Q32.
+type sigmoidPolyValueCircuit struct {
+	Z    frontend.Variable
+	Want frontend.Variable
+}
+
+func (c *sigmoidPolyValueCircuit) Define(api frontend.API) error {
+	const tolerance = 1 << (Precision - 20)
+	diff := api.Add(api.Sub(sigmoidPolyEval(api, c.Z), c.Want), tolerance)
+	api.ToBinary(diff, Precision-20+2) // 0 <= diff <= 2*tolerance
+	return nil
+}
+
+func TestSigmoidPolyCircuit(t *testing.T) {
+	values := compiled(t, &sigmoidPolyValueCircuit{})
+	classify := compiled(t, &SigmoidPolyCircuit{})
+	for _, z := range []float64{-12, -8, -5, -3.5, -2, -0.75, -0.001, 0, 0.001, 0.75, 2, 3.5, 5, 8, 12} {
+		if err := solved(t, values, &sigmoidPolyValueCircuit{Z: NewScaled(z), Want: NewScaled(SigmoidPoly(z))}); err != nil {
+			t.Errorf("z = %v: circuit sigmoid is not SigmoidPoly = %v: %v", z, SigmoidPoly(z), err)
+		}
+		if err := solved(t, values, &sigmoidPolyValueCircuit{Z: NewScaled(z), Want: NewScaled(SigmoidPoly(z) + 1.0/1024)}); err == nil {
+			t.Errorf("z = %v: circuit sigmoid matched SigmoidPoly + 2^-10", z)
+		}
+		want := 0
+		if SigmoidPoly(z) >= 0.5 {
+			want = 1
+		}
+		if err := solved(t, classify, &SigmoidPolyCircuit{Z: NewScaled(z), Label: want}); err != nil {
+			t.Errorf("z = %v: label %d rejected: %v", z, want, err)
+		}
+		if err := solved(t, classify, &SigmoidPolyCircuit{Z: NewScaled(z), Label: 1 - want}); err == nil {
+			t.Errorf("z = %v: label %d accepted", z, 1-want)
+		}
+	}
+
+	poly, lut := classify.GetNbConstraints(), compiled(t, &SigmoidCircuit{}).GetNbConstraints()
+	t.Logf("SigmoidPolyCircuit: %d constraints, SigmoidCircuit: %d", poly, lut)
+	if poly >= lut {
+		t.Errorf("SigmoidPolyCircuit has %d constraints, no fewer than the LUT's %d", poly, lut)
+	}
+}
@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Signed fixed-point values live in the scalar field as their residue mod p:
+// v >= 0 is v itself and v < 0 is p + v. The field is split at its midpoint,
+// so elements in [0, FieldMidpoint] are non-negative and those above it are
+// negative. The circuits (through isNegative) and the off-chain code
+// (through IsNegativeField) both read signs this way, so a value can never be
+// negative on one side and positive on the other.
+
+// FieldMidpoint returns floor(p/2) for the scalar field p of a circuit;
+// elements above it are negative.
+func FieldMidpoint(field *big.Int) *big.Int {
+	return new(big.Int).Rsh(field, 1)
+}
+
+// IsNegativeField reports whether v, reduced mod field, is a negative value:
+// its residue is above FieldMidpoint(field). Zero and the midpoint itself are
+// non-negative.
+func IsNegativeField(field, v *big.Int) bool {
+	r := new(big.Int).Mod(v, field)
+	return r.Cmp(FieldMidpoint(field)) > 0
+}
+
+// fieldToSigned returns v mod field as a signed integer in
+// (-FieldMidpoint(field), FieldMidpoint(field)].
+func fieldToSigned(field, v *big.Int) *big.Int {
+	r := new(big.Int).Mod(v, field)
+	if r.Cmp(FieldMidpoint(field)) > 0 {
+		r.Sub(r, field)
+	}
+	return r
+}
+
+// isNegative returns 1 if v is negative and 0 otherwise, the in-circuit
+// IsNegativeField.
+func isNegative(api frontend.API, v frontend.Variable) frontend.Variable {
+	mid := FieldMidpoint(api.Compiler().Field())
+	return api.IsZero(api.Sub(1, api.Cmp(v, mid)))
+}
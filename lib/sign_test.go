@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// signCircuit asserts that isNegative(V) is Negative.
+type signCircuit struct {
+	V        frontend.Variable
+	Negative frontend.Variable `gnark:",public"`
+}
+
+func (c *signCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(isNegative(api, c.V), c.Negative)
+	return nil
+}
+
+func TestFieldSign(t *testing.T) {
+	for _, curve := range SupportedCurves {
+		t.Run(curve.String(), func(t *testing.T) {
+			field := curve.ScalarField()
+			mid := FieldMidpoint(field)
+			if want := new(big.Int).Rsh(new(big.Int).Sub(field, big.NewInt(1)), 1); mid.Cmp(want) != 0 {
+				t.Fatalf("FieldMidpoint = %v, want (p-1)/2 = %v", mid, want)
+			}
+			ccs, err := Compile(BackendPlonk, curve, &signCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			add := func(a *big.Int, d int64) *big.Int { return new(big.Int).Add(a, big.NewInt(d)) }
+			neg := func(a *big.Int) *big.Int { return new(big.Int).Neg(a) }
+			tests := []struct {
+				name     string
+				v        *big.Int
+				negative bool
+				signed   *big.Int // fieldToSigned(v)
+			}{
+				{"zero", big.NewInt(0), false, big.NewInt(0)},
+				{"one", big.NewInt(1), false, big.NewInt(1)},
+				{"below the midpoint", add(mid, -1), false, add(mid, -1)},
+				{"midpoint", mid, false, mid},
+				{"above the midpoint", add(mid, 1), true, neg(mid)},
+				{"p - 1", add(field, -1), true, big.NewInt(-1)},
+				{"-1", big.NewInt(-1), true, big.NewInt(-1)},
+				{"p", field, false, big.NewInt(0)},
+				{"-midpoint", neg(mid), true, neg(mid)},
+			}
+			for _, tt := range tests {
+				if got := IsNegativeField(field, tt.v); got != tt.negative {
+					t.Errorf("%s: IsNegativeField = %v, want %v", tt.name, got, tt.negative)
+				}
+				if got := fieldToSigned(field, tt.v); got.Cmp(tt.signed) != 0 {
+					t.Errorf("%s: fieldToSigned = %v, want %v", tt.name, got, tt.signed)
+				}
+				negative := 0
+				if tt.negative {
+					negative = 1
+				}
+				for _, claimed := range []int{negative, 1 - negative} {
+					full, err := frontend.NewWitness(&signCircuit{V: new(big.Int).Mod(tt.v, field), Negative: claimed}, field)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := ccs.IsSolved(full); (err == nil) != (claimed == negative) {
+						t.Errorf("%s: isNegative = %d: err = %v", tt.name, claimed, err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"math/bits"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bls12381 "github.com/consensys/gnark/backend/plonk/bls12-381"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/constraint"
+)
+
+// SRSSize returns the number of G1 points a canonical KZG SRS needs for the
+// PLONK setup of ccs: its constraints plus public inputs rounded up to a power
+// of two (the evaluation domain, which is also the Lagrange SRS size), plus 3
+// for opening the blinded polynomials. It is what unsafekzg.NewSRS generates.
+func SRSSize(ccs constraint.ConstraintSystem) int {
+	return srsLagrangeSize(ccs) + 3
+}
+
+func srsLagrangeSize(ccs constraint.ConstraintSystem) int {
+	return int(ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints() + ccs.GetNbPublicVariables())))
+}
+
+// LoadSRS reads a canonical KZG SRS for curve, in gnark-crypto's binary
+// encoding (as written by its WriteTo), e.g. the output of a setup ceremony.
+// One SRS serves every circuit it is large enough for; see SetupWithSRS.
+func LoadSRS(curve ecc.ID, path string) (kzg.SRS, error) {
+	if curve != ecc.BN254 && curve != ecc.BLS12_381 {
+		return nil, fmt.Errorf("unsupported SRS curve %s", curve)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	srs := kzg.NewSRS(curve)
+	if _, err := srs.ReadFrom(bufio.NewReader(file)); err != nil {
+		return nil, fmt.Errorf("reading SRS %s: %w", path, err)
+	}
+	return srs, nil
+}
+
+// CheckSRS returns an ErrSRSTooSmall error if srs has fewer than SRSSize(ccs)
+// points, naming both sizes, or an error if it is for another curve than
+// ccs.
+func CheckSRS(ccs constraint.ConstraintSystem, srs kzg.SRS) error {
+	have, err := srsPoints(curveOfField(ccs.Field()), srs)
+	if err != nil {
+		return err
+	}
+	if need := SRSSize(ccs); have < need {
+		return fmt.Errorf("%w: it has %d points, but %d constraints and %d public inputs need %d (2^%d + 3)",
+			ErrSRSTooSmall, have, ccs.GetNbConstraints(), ccs.GetNbPublicVariables(), need, bits.Len(uint(srsLagrangeSize(ccs)))-1)
+	}
+	return nil
+}
+
+// SetupWithSRS runs the PLONK setup of ccs against srs, a canonical SRS of
+// at least SRSSize(ccs) points (see LoadSRS), instead of unsafekzg's. The
+// Lagrange SRS is derived from its first points.
+func SetupWithSRS(ccs constraint.ConstraintSystem, srs kzg.SRS) (plonk.ProvingKey, plonk.VerifyingKey, error) {
+	if err := CheckSRS(ccs, srs); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrSetup, err)
+	}
+	srsLagrange, err := lagrangeSRS(srs, srsLagrangeSize(ccs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: srs: %w", ErrSetup, err)
+	}
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrSetup, err)
+	}
+	return pk, vk, nil
+}
+
+// srsPoints returns the number of canonical G1 points in srs, which must be
+// for curve.
+func srsPoints(curve ecc.ID, srs kzg.SRS) (int, error) {
+	switch s := srs.(type) {
+	case *kzg_bn254.SRS:
+		if curve == ecc.BN254 {
+			return len(s.Pk.G1), nil
+		}
+	case *kzg_bls12381.SRS:
+		if curve == ecc.BLS12_381 {
+			return len(s.Pk.G1), nil
+		}
+	}
+	return 0, fmt.Errorf("SRS of type %T is not for curve %s", srs, curve)
+}
+
+// lagrangeSRS returns the Lagrange form of the first size points of srs.
+func lagrangeSRS(srs kzg.SRS, size int) (kzg.SRS, error) {
+	switch s := srs.(type) {
+	case *kzg_bn254.SRS:
+		g1, err := kzg_bn254.ToLagrangeG1(s.Pk.G1[:size])
+		if err != nil {
+			return nil, err
+		}
+		return &kzg_bn254.SRS{Pk: kzg_bn254.ProvingKey{G1: g1}, Vk: s.Vk}, nil
+	case *kzg_bls12381.SRS:
+		g1, err := kzg_bls12381.ToLagrangeG1(s.Pk.G1[:size])
+		if err != nil {
+			return nil, err
+		}
+		return &kzg_bls12381.SRS{Pk: kzg_bls12381.ProvingKey{G1: g1}, Vk: s.Vk}, nil
+	}
+	return nil, fmt.Errorf("unsupported SRS type %T", srs)
+}
+
+// checkKeysSRS returns an error unless k is PLONK keys set up against srs,
+// i.e. its verifying key holds srs's [α]G₂. A cache set up against another
+// SRS (such as unsafekzg's) loads fine but proves under that SRS instead.
+func checkKeysSRS(k *CircuitKeys, srs kzg.SRS) error {
+	same := false
+	switch vk := k.plonkVK.(type) {
+	case *plonk_bn254.VerifyingKey:
+		if s, ok := srs.(*kzg_bn254.SRS); ok {
+			same = vk.Kzg.G2[1].Equal(&s.Vk.G2[1])
+		}
+	case *plonk_bls12381.VerifyingKey:
+		if s, ok := srs.(*kzg_bls12381.SRS); ok {
+			same = vk.Kzg.G2[1].Equal(&s.Vk.G2[1])
+		}
+	}
+	if !same {
+		return fmt.Errorf("keys were not set up against the loaded SRS")
+	}
+	return nil
+}
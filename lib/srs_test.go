@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// testSRS returns a BN254 SRS of size points with a known toxic waste.
+func testSRS(t *testing.T, size int) *kzg_bn254.SRS {
+	t.Helper()
+	srs, err := kzg_bn254.NewSRS(uint64(size), big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srs
+}
+
+func TestSetupWithSRS(t *testing.T) {
+	ccs := compiled(t, &LinearCircuit{})
+	need := SRSSize(ccs)
+	if n := ccs.GetNbConstraints() + ccs.GetNbPublicVariables(); need <= n || need > 2*n+3 {
+		t.Fatalf("SRSSize %d for %d constraints and public inputs", need, n)
+	}
+	otherCurve, err := kzg_bls12381.NewSRS(uint64(need), big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		srs      kzg.SRS
+		tooSmall bool
+		wantErr  bool
+	}{
+		{"exact size", testSRS(t, need), false, false},
+		{"larger", testSRS(t, 2*need), false, false},
+		{"one point short", testSRS(t, need-1), true, true},
+		{"other curve", otherCurve, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pk, vk, err := SetupWithSRS(ccs, tt.srs)
+			if (err != nil) != tt.wantErr || errors.Is(err, ErrSRSTooSmall) != tt.tooSmall {
+				t.Fatalf("err = %v, want error %v, too small %v", err, tt.wantErr, tt.tooSmall)
+			}
+			if tt.tooSmall {
+				if !errors.Is(err, ErrSetup) {
+					t.Errorf("err = %v, want ErrSetup too", err)
+				}
+				for _, want := range []string{fmt.Sprintf("has %d points", need-1), fmt.Sprintf("need %d", need)} {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not say %q", err, want)
+					}
+				}
+			}
+			if err != nil {
+				return
+			}
+			if err := checkKeysSRS(plonkKeys(ccs, pk, vk), tt.srs); err != nil {
+				t.Errorf("keys from the SRS: %v", err)
+			}
+		})
+	}
+
+	// keys set up against unsafekzg's SRS, or another toxic waste, are not
+	// the loaded SRS's
+	_, _, vk, err := SetupCurve(DefaultCurve, &LinearCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKeysSRS(plonkKeys(nil, nil, vk), testSRS(t, need)); err == nil {
+		t.Error("unsafekzg keys accepted as the loaded SRS's")
+	}
+}
+
+func TestLoadSRS(t *testing.T) {
+	dir := t.TempDir()
+	srs := testSRS(t, 64)
+	path := filepath.Join(dir, "srs.bin")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srs.WriteTo(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	loaded, err := LoadSRS(ecc.BN254, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := srsPoints(ecc.BN254, loaded); err != nil || n != 64 {
+		t.Errorf("loaded %d points (%v), want 64", n, err)
+	}
+	if _, err := srsPoints(ecc.BLS12_381, loaded); err == nil {
+		t.Error("BN254 SRS accepted for BLS12-381")
+	}
+
+	if _, err := LoadSRS(ecc.BW6_761, path); err == nil {
+		t.Error("loaded an SRS for an unsupported curve")
+	}
+	if _, err := LoadSRS(ecc.BN254, filepath.Join(dir, "missing.bin")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: err = %v", err)
+	}
+	if _, err := LoadSRS(ecc.BLS12_381, path); err == nil {
+		t.Error("BN254 SRS read as BLS12-381")
+	}
+}
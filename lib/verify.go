@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark/backend/plonk"
+)
+
+// VerifySamples runs VerifySample on each sample. Failures are returned in
+// sample order; samples not listed verified.
+func VerifySamples(linearVK, sigmoidVK plonk.VerifyingKey, proofs []ProofData) []*SampleError {
+	return VerifySamplesConcurrent(linearVK, sigmoidVK, proofs, 1)
+}
+
+// VerifySamplesConcurrent is VerifySamples with up to workers samples
+// verified in parallel. Verification only reads the keys, so they are shared.
+func VerifySamplesConcurrent(linearVK, sigmoidVK plonk.VerifyingKey, proofs []ProofData, workers int) []*SampleError {
+	return verifySamples(plonkKeys(nil, nil, linearVK), plonkKeys(nil, nil, sigmoidVK), proofs, workers)
+}
+
+// verifySamples is VerifySamplesConcurrent under any backend. With nil
+// linear keys only the sigmoid proofs are verified (PipelineConfig.SkipLinear).
+func verifySamples(linear, sigmoid *CircuitKeys, proofs []ProofData, workers int) []*SampleError {
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, len(proofs))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = verifySample(linear, sigmoid, proofs[i])
+			}
+		}()
+	}
+	for i := range proofs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	var failures []*SampleError
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, &SampleError{SampleNum: proofs[i].SampleNum, Mark: proofs[i].Mark, Err: err})
+		}
+	}
+	return failures
+}
+
+// VerifySample verifies one sample's proof bundle: the linear proof, the
+// sigmoid proof and their agreement on Z (CheckPublicLink). Every check is
+// run, and the error joins those that failed, each prefixed with "linear",
+// "sigmoid" or "link"; errors.Is finds ErrVerify or ErrPublicLink in it.
+func VerifySample(pd ProofData, linearVK, sigmoidVK plonk.VerifyingKey) error {
+	return verifySample(plonkKeys(nil, nil, linearVK), plonkKeys(nil, nil, sigmoidVK), pd)
+}
+
+// verifySample is VerifySample under any backend. Nil linear keys verify
+// the sigmoid proof alone; with linear keys a bundle missing its linear
+// proof fails, so VerifySample cannot be fooled by stripping it.
+func verifySample(linear, sigmoid *CircuitKeys, pd ProofData) error {
+	if linear == nil {
+		if err := sigmoid.Verify(pd.SigmoidProof, pd.SigmoidPublic); err != nil {
+			return fmt.Errorf("sigmoid: %w", err)
+		}
+		return nil
+	}
+	if pd.LinearProof == nil || pd.LinearPublic == nil {
+		return fmt.Errorf("linear: %w: the bundle has no linear proof", ErrVerify)
+	}
+	var errs []error
+	if err := linear.Verify(pd.LinearProof, pd.LinearPublic); err != nil {
+		errs = append(errs, fmt.Errorf("linear: %w", err))
+	}
+	if err := sigmoid.Verify(pd.SigmoidProof, pd.SigmoidPublic); err != nil {
+		errs = append(errs, fmt.Errorf("sigmoid: %w", err))
+	}
+	if err := CheckPublicLink(pd.LinearPublic, pd.SigmoidPublic); err != nil {
+		errs = append(errs, fmt.Errorf("link: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,175 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// provedSamples proves testModel on marks under linear and sigmoid.
+func provedSamples(t testing.TB, linear, sigmoid *CircuitKeys, marks []float64) []ProofData {
+	t.Helper()
+	proofs, failures, _, _ := proveSamples(context.Background(), nil, linear, sigmoid, testModel.w, testModel.b, marks, testLabels(marks), 4, nil)
+	if len(failures) != 0 {
+		t.Fatalf("failures: %v", failures)
+	}
+	return proofs
+}
+
+func TestVerifySamplesConcurrent(t *testing.T) {
+	linear, sigmoid := sampleKeys(t, BackendGroth16)
+	proofs := provedSamples(t, linear, sigmoid, []float64{10, 30, 15, 40, 25, 50, 5, 35})
+
+	// each tampered sample fails on its own, whatever the other samples do
+	tampered := slices.Clone(proofs)
+	tampered[1].SigmoidPublic = proofs[2].SigmoidPublic
+	tampered[4].LinearProof = nil
+	tampered[6].LinearPublic, tampered[6].SigmoidPublic = proofs[7].LinearPublic, proofs[7].SigmoidPublic
+
+	tests := []struct {
+		name   string
+		proofs []ProofData
+		failed []int
+	}{
+		{"none", nil, nil},
+		{"all valid", proofs, nil},
+		{"tampered", tampered, []int{2, 5, 7}},
+	}
+	for _, tt := range tests {
+		for _, workers := range []int{0, 1, 3, 16} {
+			t.Run(fmt.Sprintf("%s/%d workers", tt.name, workers), func(t *testing.T) {
+				var failed []int
+				for _, f := range verifySamples(linear, sigmoid, tt.proofs, workers) {
+					if !errors.Is(f.Err, ErrVerify) && !errors.Is(f.Err, ErrPublicLink) {
+						t.Errorf("sample %d: %v, want ErrVerify or ErrPublicLink", f.SampleNum, f.Err)
+					}
+					if f.Mark != tt.proofs[f.SampleNum-1].Mark {
+						t.Errorf("sample %d reported with marks %v", f.SampleNum, f.Mark)
+					}
+					failed = append(failed, f.SampleNum)
+				}
+				if !slices.Equal(failed, tt.failed) {
+					t.Errorf("failed samples %v, want %v", failed, tt.failed)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifySample(t *testing.T) {
+	linear, sigmoid := sampleKeys(t, BackendPlonk)
+	proofs := provedSamples(t, linear, sigmoid, []float64{10, 30})
+	a, b := proofs[0], proofs[1]
+
+	tests := []struct {
+		name   string
+		tamper func(pd *ProofData)
+		failed []string // the checks that fail, in VerifySample's order
+	}{
+		{"valid", func(*ProofData) {}, nil},
+		{"other linear public", func(pd *ProofData) { pd.LinearPublic = b.LinearPublic }, []string{"linear", "link"}},
+		{"other sigmoid proof", func(pd *ProofData) { pd.SigmoidProof = b.SigmoidProof }, []string{"sigmoid"}},
+		{"other sample's linear bundle", func(pd *ProofData) { pd.LinearProof, pd.LinearPublic = b.LinearProof, b.LinearPublic }, []string{"link"}},
+		{"no linear proof", func(pd *ProofData) { pd.LinearProof = nil }, []string{"linear"}},
+		{"sigmoid proof as linear", func(pd *ProofData) { pd.LinearProof = a.SigmoidProof }, []string{"linear"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd := a
+			tt.tamper(&pd)
+			err := VerifySample(pd, linear.plonkVK, sigmoid.plonkVK)
+			if tt.failed == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("verified, want %v to fail", tt.failed)
+			}
+			var failed []string
+			for _, check := range []string{"linear", "sigmoid", "link"} {
+				if strings.Contains(err.Error(), check+": ") {
+					failed = append(failed, check)
+				}
+			}
+			if !slices.Equal(failed, tt.failed) {
+				t.Errorf("failed checks %v, want %v: %v", failed, tt.failed, err)
+			}
+			if slices.Contains(tt.failed, "link") != errors.Is(err, ErrPublicLink) {
+				t.Errorf("errors.Is(err, ErrPublicLink) = %v: %v", errors.Is(err, ErrPublicLink), err)
+			}
+			if (slices.Contains(tt.failed, "linear") || slices.Contains(tt.failed, "sigmoid")) != errors.Is(err, ErrVerify) {
+				t.Errorf("errors.Is(err, ErrVerify) = %v: %v", errors.Is(err, ErrVerify), err)
+			}
+		})
+	}
+}
+
+// BenchmarkVerifySamples compares verifying 100 PLONK sample proofs
+// serially with verifying them concurrently. Run it with
+//
+//	go test -run '^$' -bench BenchmarkVerifySamples ./lib
+func BenchmarkVerifySamples(b *testing.B) {
+	linear, sigmoid := sampleKeys(b, BackendPlonk)
+	marks := make([]float64, NumSamples)
+	for i := range marks {
+		marks[i] = float64(i)
+	}
+	proofs := provedSamples(b, linear, sigmoid, marks)
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if failures := VerifySamplesConcurrent(linear.plonkVK, sigmoid.plonkVK, proofs, workers); len(failures) != 0 {
+					b.Fatalf("failures: %v", failures)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifySigmoidOnly(t *testing.T) {
+	linear, sigmoid := sampleKeys(t, BackendGroth16)
+	marks := []float64{10, 30, 15, 40}
+	proofs := provedSamples(t, nil, sigmoid, marks)
+	for _, pd := range proofs {
+		if pd.LinearProof != nil || pd.LinearPublic != nil {
+			t.Fatalf("sample %d has a linear proof without linear keys", pd.SampleNum)
+		}
+	}
+
+	tampered := slices.Clone(proofs)
+	tampered[2].SigmoidPublic = proofs[3].SigmoidPublic
+
+	tests := []struct {
+		name   string
+		linear *CircuitKeys
+		proofs []ProofData
+		failed []int
+	}{
+		{"sigmoid only", nil, proofs, nil},
+		{"tampered", nil, tampered, []int{3}},
+		// linear keys demand a linear proof, so dropping it is no way
+		// around the linear check
+		{"linear keys", linear, proofs, []int{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed []int
+			for _, f := range verifySamples(tt.linear, sigmoid, tt.proofs, 2) {
+				if !errors.Is(f.Err, ErrVerify) {
+					t.Errorf("sample %d: %v, want ErrVerify", f.SampleNum, f.Err)
+				}
+				failed = append(failed, f.SampleNum)
+			}
+			slices.Sort(failed)
+			if !slices.Equal(failed, tt.failed) {
+				t.Errorf("failed samples %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
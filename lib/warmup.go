@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// WarmupResult reports the one-time setup cost of a single circuit.
+type WarmupResult struct {
+	Name          string
+	CacheFile     string
+	NbConstraints int
+	Duration      time.Duration
+}
+
+// WarmCaches compiles and sets up every registered circuit with default
+// options and writes its cache file into dir, without generating any proofs. Existing cache files are
+// overwritten, so a later run starts from warm caches.
+func WarmCaches(dir string) ([]WarmupResult, error) {
+	lut, err := LoadSigmoidTable(dir, DefaultLUTConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	var results []WarmupResult
+	opts := CircuitOptions{LUT: lut}
+	for _, c := range Circuits(CircuitsAll) {
+		start := time.Now()
+		circuit := c.New(opts)
+		k, err := SetupBackend(BackendPlonk, DefaultCurve, circuit)
+		if err != nil {
+			return results, fmt.Errorf("%s circuit: %w", c.Name, err)
+		}
+
+		file := filepath.Join(dir, c.CacheFile(opts))
+		if err := writeCacheFile(file, ConfigHash(circuit), k); err != nil {
+			return results, fmt.Errorf("%s circuit: saving cache %s: %w", c.Name, file, err)
+		}
+
+		results = append(results, WarmupResult{
+			Name:          c.Name,
+			CacheFile:     file,
+			NbConstraints: k.CCS.GetNbConstraints(),
+			Duration:      time.Since(start),
+		})
+	}
+	return results, nil
+}
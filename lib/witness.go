@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// MarshalWitness encodes a full or public witness in gnark's binary format,
+// e.g. to send it from a client to a proving server.
+func MarshalWitness(w witness.Witness) ([]byte, error) {
+	data, err := w.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%w: marshal: %w", ErrWitness, err)
+	}
+	return data, nil
+}
+
+// UnmarshalWitness decodes a BN254 witness written by MarshalWitness.
+func UnmarshalWitness(data []byte) (witness.Witness, error) {
+	return UnmarshalWitnessCurve(DefaultCurve, data)
+}
+
+// UnmarshalWitnessCurve is UnmarshalWitness for a witness over curve's
+// scalar field.
+func UnmarshalWitnessCurve(curve ecc.ID, data []byte) (witness.Witness, error) {
+	w, err := witness.New(curve.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWitness, err)
+	}
+	if err := w.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%w: unmarshal: %w", ErrWitness, err)
+	}
+	return w, nil
+}
+
+// MarshalPublicWitness encodes only the public part of w, which is all a
+// verifier needs and never carries the private W and B.
+func MarshalPublicWitness(w witness.Witness) ([]byte, error) {
+	public, err := w.Public()
+	if err != nil {
+		return nil, fmt.Errorf("%w: public: %w", ErrWitness, err)
+	}
+	return MarshalWitness(public)
+}
+
+// UnmarshalPublicWitness is UnmarshalWitness that rejects data containing
+// secret values.
+func UnmarshalPublicWitness(data []byte) (witness.Witness, error) {
+	return UnmarshalPublicWitnessCurve(DefaultCurve, data)
+}
+
+// UnmarshalPublicWitnessCurve is UnmarshalPublicWitness for a witness over
+// curve's scalar field.
+func UnmarshalPublicWitnessCurve(curve ecc.ID, data []byte) (witness.Witness, error) {
+	w, err := UnmarshalWitnessCurve(curve, data)
+	if err != nil {
+		return nil, err
+	}
+	public, err := w.Public()
+	if err != nil {
+		return nil, fmt.Errorf("%w: public: %w", ErrWitness, err)
+	}
+	// Vector is the curve's fr.Vector
+	if reflect.ValueOf(w.Vector()).Len() != reflect.ValueOf(public.Vector()).Len() {
+		return nil, fmt.Errorf("%w: witness contains secret values", ErrWitness)
+	}
+	return public, nil
+}
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+func TestWitnessRoundTrip(t *testing.T) {
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		t.Run(curve.String(), func(t *testing.T) {
+			full, err := LinearWitness(curve.ScalarField(), testModel.w, testModel.b, 30)
+			if err != nil {
+				t.Fatal(err)
+			}
+			public, err := full.Public()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fullData, err := MarshalWitness(full)
+			if err != nil {
+				t.Fatal(err)
+			}
+			publicData, err := MarshalPublicWitness(full)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantPublic, err := public.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(publicData, wantPublic) {
+				t.Error("MarshalPublicWitness(full) differs from the public witness' encoding")
+			}
+
+			tests := []struct {
+				name    string
+				data    []byte
+				public  bool // through UnmarshalPublicWitnessCurve
+				wantErr bool
+			}{
+				{"full", fullData, false, false},
+				{"public", publicData, false, false},
+				{"public only", publicData, true, false},
+				{"full as public", fullData, true, true},
+				{"truncated", fullData[:len(fullData)-1], false, true},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					unmarshal := UnmarshalWitnessCurve
+					if tt.public {
+						unmarshal = UnmarshalPublicWitnessCurve
+					}
+					w, err := unmarshal(curve, tt.data)
+					if tt.wantErr {
+						if !errors.Is(err, ErrWitness) {
+							t.Fatalf("err = %v, want ErrWitness", err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					got, err := MarshalWitness(w)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(got, tt.data) {
+						t.Error("re-encoding differs")
+					}
+					if got, err := MarshalPublicWitness(w); err != nil || !bytes.Equal(got, publicData) {
+						t.Errorf("public part differs: %v", err)
+					}
+				})
+			}
+		})
+	}
+}
@@ -1,785 +1,478 @@
 package main
 
 import (
-	"encoding/csv"
+	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"log"
-	"math"
-	"math/big"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/plonk"
-	"github.com/consensys/gnark/backend/witness"
-	cs "github.com/consensys/gnark/constraint/bn254"
-	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/scs"
-	"github.com/consensys/gnark/std/lookup/logderivlookup"
-	"github.com/consensys/gnark/test/unsafekzg"
-)
-
-// ============================================================================
-// CIRCUIT 1: Linear Regression Circuit (z = W*X + B)
-// ============================================================================
-
-const Precision = 32
-var scalingFactor = new(big.Int).Lsh(big.NewInt(1), Precision)
-
-type FixedPoint struct {
-	Val frontend.Variable
-	Api frontend.API
-}
-
-func New(api frontend.API, v frontend.Variable) FixedPoint {
-	return FixedPoint{Val: v, Api: api}
-}
-
-func (a FixedPoint) Mul(b FixedPoint) FixedPoint {
-	resScaled := a.Api.Mul(a.Val, b.Val)
-	res := a.Api.Div(resScaled, scalingFactor)
-	return New(a.Api, res)
-}
-
-func (a FixedPoint) Add(b FixedPoint) FixedPoint {
-	res := a.Api.Add(a.Val, b.Val)
-	return New(a.Api, res)
-}
-
-type LinearCircuit struct {
-	W frontend.Variable
-	B frontend.Variable
-	X frontend.Variable `gnark:",public"`
-	Z frontend.Variable `gnark:",public"`
-}
-
-func (circuit *LinearCircuit) Define(api frontend.API) error {
-	w := New(api, circuit.W)
-	b := New(api, circuit.B)
-	x := New(api, circuit.X)
-
-	wx := w.Mul(x)
-	z := wx.Add(b)
-
-	api.AssertIsEqual(z.Val, circuit.Z)
-	return nil
-}
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/logger"
 
-// ============================================================================
-// CIRCUIT 2: Sigmoid Classification Circuit
-// ============================================================================
-
-// Sigmoid LUT configuration
-const inputPrecision = 10   // input Q10
-const outputPrecision = 16  // output Q16
-const MaxInput = 8          // cover [-8, 8]
-const MarginSteps = 8       // margin in Q10 steps (~0.0078125) around 0
+	"github.com/santhoshcheemala/ZKLR/lib"
+	"github.com/santhoshcheemala/ZKLR/lib/server"
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
 
-type SigmoidCircuit struct {
-	Z     frontend.Variable `gnark:",public"`
-	Label frontend.Variable `gnark:",public"`
+// countFlag is a boolean-style flag that counts how often it is given, so
+// -v -v is 2; -v=N sets the count directly.
+type countFlag int
 
-	table *logderivlookup.Table
-}
+func (c *countFlag) String() string { return strconv.Itoa(int(*c)) }
 
-func (circuit *SigmoidCircuit) Define(api frontend.API) error {
-	// Build LUT once (compiled into the circuit and cached on disk by our outer cache layer)
-	if circuit.table == nil {
-		circuit.table = logderivlookup.New(api)
-		tablesize := MaxInput * (1 << inputPrecision)
-		for i := 0; i <= tablesize; i++ {
-			x := float64(i) / float64(1<<inputPrecision)
-			y := 1.0 / (1.0 + math.Exp(-x))
-			yScaled := int64(y * float64(1<<outputPrecision))
-			circuit.table.Insert(yScaled)
-		}
+func (c *countFlag) Set(s string) error {
+	if s == "true" {
+		*c++
+		return nil
 	}
-
-	// Rescale Z from Q32 to Q10 for lookup domain
-	shift := new(big.Int).Lsh(big.NewInt(1), Precision-inputPrecision)
-	zIn := api.Div(circuit.Z, shift)
-
-	// Constants
-	oneOut := big.NewInt(1 << outputPrecision)               // 65536
-	threshold := big.NewInt(1 << (outputPrecision - 1))      // 32768
-	maxTableIndex := big.NewInt(MaxInput << inputPrecision)   // 8192
-
-	// Signed handling via field midpoint
-	fieldMid := new(big.Int).Rsh(ecc.BN254.ScalarField(), 1)
-	cmpMid := api.Cmp(zIn, fieldMid)
-	isNeg := api.IsZero(api.Sub(1, cmpMid)) // 1 if negative
-
-	// |z|
-	absZ := api.Select(isNeg, api.Neg(zIn), zIn)
-
-	// Saturation to LUT domain
-	cmpMax := api.Cmp(absZ, maxTableIndex)
-	isSat := api.IsZero(api.Sub(1, cmpMax)) // 1 if absZ > max
-	clamped := api.Select(isSat, maxTableIndex, absZ)
-
-	// Lookup(sigmoid(|z|))
-	lut := circuit.table.Lookup(clamped)[0]
-	// Symmetry sigmoid(-x) = 1 - sigmoid(x)
-	sigmoid := api.Select(isNeg, api.Sub(oneOut, lut), lut)
-
-	// Threshold at 0.5
-	cmpThresh := api.Cmp(sigmoid, threshold)
-	isLess := api.IsZero(api.Add(cmpThresh, 1)) // 1 if <
-	prediction := api.Sub(1, isLess)            // 1 if >=, else 0
-
-	// Enforce match with dataset label
-	api.AssertIsEqual(prediction, circuit.Label)
-	return nil
-}
-
-// ============================================================================
-// CIRCUIT 3A: Accuracy Chunk Circuit (25 samples)
-// Counts correct predictions for a chunk of samples.
-// ============================================================================
-
-const ChunkSize = 25
-
-type AccuracyChunkCircuit struct {
-	W     frontend.Variable
-	B     frontend.Variable
-	X     [ChunkSize]frontend.Variable `gnark:",public"`
-	Label [ChunkSize]frontend.Variable `gnark:",public"`
-}
-
-func (c *AccuracyChunkCircuit) Define(api frontend.API) error {
-	w := New(api, c.W)
-	b := New(api, c.B)
-
-	fieldMid := new(big.Int).Rsh(ecc.BN254.ScalarField(), 1)
-	shift := new(big.Int).Lsh(big.NewInt(1), Precision-inputPrecision)
-	margin := big.NewInt(MarginSteps)
-
-	sumCorrect := frontend.Variable(0)
-
-	for i := 0; i < ChunkSize; i++ {
-		x := New(api, c.X[i])
-		z := w.Mul(x).Add(b)
-
-		cmpMid := api.Cmp(z.Val, fieldMid)
-		isNegative := api.IsZero(api.Sub(1, cmpMid))
-		prediction := api.Sub(1, isNegative)
-
-		zIn := api.Div(z.Val, shift)
-		cmpMidIn := api.Cmp(zIn, fieldMid)
-		isNegIn := api.IsZero(api.Sub(1, cmpMidIn))
-		absZIn := api.Select(isNegIn, api.Neg(zIn), zIn)
-		cmpMargin := api.Cmp(absZIn, margin)
-		isLessMargin := api.IsZero(api.Add(cmpMargin, 1))
-		eligible := api.Sub(1, isLessMargin)
-
-		diff := api.Sub(prediction, c.Label[i])
-		equal := api.IsZero(diff)
-
-		sumCorrect = api.Add(sumCorrect, api.Mul(eligible, equal))
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
 	}
-
-	// No assertion here - just output the count
-	// The aggregator will enforce the global threshold
+	*c = countFlag(n)
 	return nil
 }
 
-// ============================================================================
-// CIRCUIT 3B: Aggregator Circuit
-// Takes counts from 4 chunks and asserts total >= 97
-// ============================================================================
+func (c *countFlag) IsBoolFlag() bool { return true }
 
-type AggregatorCircuit struct {
-	Count1 frontend.Variable `gnark:",public"`
-	Count2 frontend.Variable `gnark:",public"`
-	Count3 frontend.Variable `gnark:",public"`
-	Count4 frontend.Variable `gnark:",public"`
+// runWarmup compiles and sets up every circuit into the cache directory
+// without proving, so setup cost is paid once and kept out of later timings.
+func runWarmup(cacheDir string) {
+	fmt.Printf("=== Warming circuit caches in %s ===\n", cacheDir)
+	start := time.Now()
+	results, err := lib.WarmCaches(cacheDir)
+	for _, r := range results {
+		fmt.Printf("%-10s %9d constraints  %8v  -> %s\n", r.Name, r.NbConstraints, r.Duration.Round(time.Millisecond), r.CacheFile)
+	}
+	if err != nil {
+		log.Fatal("Warmup failed:", err)
+	}
+	fmt.Printf("All caches warm (%v total)\n", time.Since(start).Round(time.Millisecond))
 }
 
-func (c *AggregatorCircuit) Define(api frontend.API) error {
-	totalCorrect := api.Add(c.Count1, c.Count2)
-	totalCorrect = api.Add(totalCorrect, c.Count3)
-	totalCorrect = api.Add(totalCorrect, c.Count4)
-
-	minCorrect := big.NewInt(97)
-	cmp := api.Cmp(totalCorrect, minCorrect)
-	isLess := api.IsZero(api.Add(cmp, 1))
-	api.AssertIsEqual(isLess, 0)
-
-	return nil
+// runSelfTest proves the built-in known-answer vectors through each
+// per-sample circuit and exits non-zero on any mismatch.
+func runSelfTest() {
+	fmt.Println("=== Known-answer self-test ===")
+	start := time.Now()
+	results, err := lib.SelfTest()
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "FAILED: " + r.Err.Error()
+		}
+		fmt.Printf("vector %d  %-10s %s\n", r.Vector, r.Circuit, status)
+	}
+	if err != nil {
+		log.Fatal("Self-test failed: ", err)
+	}
+	fmt.Printf("All %d checks passed (%v)\n", len(results), time.Since(start).Round(time.Millisecond))
 }
 
-// ============================================================================
-// CIRCUIT 3 (Legacy): Full Accuracy Circuit - kept for reference
-// ============================================================================
-
-const NumSamples = 100
-
-type AccuracyCircuit struct {
-	W     frontend.Variable
-	B     frontend.Variable
-	X     [NumSamples]frontend.Variable `gnark:",public"`
-	Label [NumSamples]frontend.Variable `gnark:",public"`
+// runValidateData checks that the dataset at path loads and that every
+// sample can be proven, printing its size and class balance under labels,
+// and exits non-zero on the first problem.
+func runValidateData(path string, labels utils.LabelMapping) {
+	fmt.Printf("=== Validating %s ===\n", path)
+	samples, err := utils.LoadDataset(path)
+	if err != nil {
+		log.Fatal("Invalid dataset: ", err)
+	}
+	if err := utils.CheckSamples(samples); err != nil {
+		log.Fatal("Invalid dataset: ", err)
+	}
+	zeros, ones := utils.ClassBalance(samples)
+	n := float64(len(samples))
+	fmt.Printf("Samples: %d\n", len(samples))
+	for label, count := range []int{zeros, ones} {
+		class := "pass"
+		if labels.ToClass(label) == 1 {
+			class = "fail"
+		}
+		fmt.Printf("Label %d (%s): %d (%.1f%%)\n", label, class, count, 100*float64(count)/n)
+	}
+	fmt.Println("Dataset is valid")
 }
 
-func (c *AccuracyCircuit) Define(api frontend.API) error {
-	w := New(api, c.W)
-	b := New(api, c.B)
-
-	// helper: sign threshold using field midpoint
-	fieldMid := new(big.Int).Rsh(ecc.BN254.ScalarField(), 1)
-	// for rescaling Z (Q32 -> Q10)
-	shift := new(big.Int).Lsh(big.NewInt(1), Precision-inputPrecision)
-	margin := big.NewInt(MarginSteps)
-
-	// count correct predictions
-	sumCorrect := frontend.Variable(0)
-
-	for i := 0; i < NumSamples; i++ {
-		x := New(api, c.X[i])
-		// z = w*x + b  (fixed-point scaling inside Mul/Add)
-		z := w.Mul(x).Add(b)
-
-		// prediction = 1 if z >= 0 else 0
-		cmpMid := api.Cmp(z.Val, fieldMid)
-		isNegative := api.IsZero(api.Sub(1, cmpMid)) // 1 if z>fieldMid
-		prediction := api.Sub(1, isNegative)
-
-		// eligibility: exclude borderline samples near 0 in Q10 domain
-		// zIn = z/shift (Q10). Compute |zIn| >= MarginSteps ? 1 : 0
-		zIn := api.Div(z.Val, shift)
-		cmpMidIn := api.Cmp(zIn, fieldMid)
-		isNegIn := api.IsZero(api.Sub(1, cmpMidIn))
-		absZIn := api.Select(isNegIn, api.Neg(zIn), zIn)
-		cmpMargin := api.Cmp(absZIn, margin)
-		isLessMargin := api.IsZero(api.Add(cmpMargin, 1)) // 1 if absZIn < margin
-		eligible := api.Sub(1, isLessMargin)              // 1 if >= margin, else 0
-
-		// equal = 1 if prediction == Label[i] else 0
-		diff := api.Sub(prediction, c.Label[i])
-		equal := api.IsZero(diff)
-
-		// count only eligible & correct
-		sumCorrect = api.Add(sumCorrect, api.Mul(eligible, equal))
-	}
-
-	// enforce sumCorrect >= minCorrect (97% of NumSamples)
-	minCorrect := big.NewInt(97) // since NumSamples == 100
-	cmp := api.Cmp(sumCorrect, minCorrect)
-	isLess := api.IsZero(api.Add(cmp, 1)) // 1 if sumCorrect < minCorrect
-	api.AssertIsEqual(isLess, 0)
-
-	return nil
+// runServe loads the per-sample circuits' keys from cacheDir and serves
+// proving and verification over HTTP on addr.
+func runServe(cacheDir, addr string, opts server.Options) {
+	names := []string{"linear", "sigmoid", "inference"}
+	fmt.Printf("=== Loading %s circuits from %s ===\n", strings.Join(names, ", "), cacheDir)
+	s, err := server.New(cacheDir, names, opts)
+	if err != nil {
+		log.Fatal("Server setup failed: ", err)
+	}
+	fmt.Printf("Serving POST /prove, POST /verify and GET /metrics on %s (%s, %s, %d proofs at once, %d queued)\n",
+		addr, opts.Curve, opts.Backend, opts.MaxConcurrentProofs, opts.MaxQueuedProofs)
+	log.Fatal(http.ListenAndServe(addr, s.Handler()))
 }
 
-func newScaled(val float64) *big.Int {
-	f := new(big.Float).SetFloat64(val)
-	sf := new(big.Float).SetInt(scalingFactor)
-	f.Mul(f, sf)
-	res, _ := f.Int(nil)
-	return res
+// runInspect prints what a circuit cache file holds, so it can be checked
+// against the current circuit before a long run.
+func runInspect(cacheFile string) {
+	info, err := lib.InspectCache(cacheFile)
+	if err != nil {
+		log.Fatal("Inspect failed: ", err)
+	}
+	fmt.Printf("File:           %s\n", cacheFile)
+	fmt.Printf("Format:         gnark %s encoding (constraint system, proving key, verifying key)\n", info.GnarkVersion)
+	if info.ConfigHash != "" {
+		fmt.Printf("Config hash:    %s\n", info.ConfigHash)
+	} else {
+		fmt.Printf("Config hash:    none (written by SaveCircuitData or before config headers)\n")
+	}
+	fmt.Printf("Backend:        %s\n", info.Backend)
+	fmt.Printf("Curve:          %s\n", info.Curve)
+	fmt.Printf("Constraints:    %d\n", info.NbConstraints)
+	fmt.Printf("Variables:      %d public, %d private, %d internal\n", info.NbPublic, info.NbSecret, info.NbInternal)
+	fmt.Printf("VK fingerprint: %s\n", info.VKFingerprint)
 }
 
-func loadTestData(filepath string) ([]int, []int, error) {
-	file, err := os.Open(filepath)
+// printConfidenceHistogram shows how the model's sigmoid confidences are
+// spread over the dataset, in 10 bins over [0,1]; a crowd near 0.5 means many
+// samples sit near the decision boundary.
+func printConfidenceHistogram(datasetPath, modelPath string) {
+	samples, err := utils.LoadDataset(datasetPath)
 	if err != nil {
-		return nil, nil, err
+		log.Fatal(err)
 	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	w, b, err := utils.LoadModelParameters(modelPath)
 	if err != nil {
-		return nil, nil, err
+		log.Fatal(err)
 	}
 
-	var marks []int
-	var labels []int
-
-	for i := 1; i < len(records); i++ {
-		mark, _ := strconv.Atoi(records[i][0])
-		label, _ := strconv.Atoi(records[i][1])
-		marks = append(marks, mark)
-		labels = append(labels, label)
+	const bins = 10
+	hist := utils.ConfidenceHistogram(utils.Confidences(w, b, samples), bins)
+	fmt.Println("=== Prediction confidence ===")
+	for i, n := range hist {
+		closing := ")"
+		if i == bins-1 {
+			closing = "]"
+		}
+		fmt.Printf("[%.1f, %.1f%s %4d %s\n", float64(i)/bins, float64(i+1)/bins, closing, n, strings.Repeat("#", n))
 	}
-
-	return marks, labels, nil
 }
 
-// Save constraint system and keys to file
-func saveCircuitData(filename string, ccs *cs.SparseR1CS, pk plonk.ProvingKey, vk plonk.VerifyingKey) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+// dryRunBatchSizes are the BatchInferenceCircuit sizes runDryRun reports.
+var dryRunBatchSizes = []int{1, 5, 10, lib.ChunkSize}
+
+// runDryRun prints the size of every circuit and the batch inference cost
+// per sample; compilation is far cheaper than setup+prove, so this is the
+// quick way to tune precision/chunk parameters.
+func runDryRun() {
+	stats, err := lib.DryRun()
+	fmt.Printf("%-10s %12s %10s %8s %8s %10s %10s\n", "circuit", "constraints", "internal", "public", "secret", "srs", "srs size")
+	for _, st := range stats {
+		fmt.Printf("%-10s %12d %10d %8d %8d %10d %8.1fMB\n", st.Name, st.NbConstraints, st.NbInternalVariables,
+			st.NbPublicVariables, st.NbSecretVariables, st.SRSSize, float64(st.SRSBytes)/(1<<20))
 	}
-	defer file.Close()
-
-	// Write CCS
-	_, err = ccs.WriteTo(file)
 	if err != nil {
-		return err
+		log.Fatal("Dry run failed:", err)
 	}
 
-	// Write PK
-	_, err = pk.WriteTo(file)
+	costs, err := lib.BatchInferenceCosts(dryRunBatchSizes)
+	fmt.Printf("\n%-16s %12s %12s %10s\n", "batch inference", "constraints", "per sample", "vs single")
+	for _, c := range costs {
+		fmt.Printf("%-16d %12d %12.0f %9.0f%%\n", c.Size, c.NbConstraints, c.PerSample, 100*c.Ratio)
+	}
 	if err != nil {
-		return err
+		log.Fatal("Dry run failed:", err)
 	}
+}
 
-	// Write VK
-	_, err = vk.WriteTo(file)
+// runProfile compiles every circuit under gnark's profiler and prints where
+// the constraints come from.
+func runProfile(dir string) {
+	results, err := lib.ProfileCircuits(dir)
+	for _, r := range results {
+		fmt.Printf("=== %s: %d constraints -> %s ===\n%s\n", r.Name, r.NbConstraints, r.Path, r.Top)
+	}
 	if err != nil {
-		return err
+		log.Fatal("Profiling failed:", err)
 	}
-
-	return nil
 }
 
-// Load constraint system and keys from file
-func loadCircuitData(filename string) (*cs.SparseR1CS, plonk.ProvingKey, plonk.VerifyingKey, error) {
-	file, err := os.Open(filename)
+// runModels proves the accuracy policy for several models against the same
+// dataset and prints a comparison.
+func runModels(cfg lib.PipelineConfig, spec string) {
+	paths, err := lib.ExpandModelPaths(spec)
 	if err != nil {
-		return nil, nil, nil, err
+		log.Fatal(err)
 	}
-	defer file.Close()
-
-	// Read CCS
-	ccs := &cs.SparseR1CS{}
-	_, err = ccs.ReadFrom(file)
+	results, err := lib.RunModels(cfg, paths)
 	if err != nil {
-		return nil, nil, nil, err
+		log.Fatal("Model comparison failed: ", err)
 	}
 
-	// Read PK
-	pk := plonk.NewProvingKey(ecc.BN254)
-	_, err = pk.ReadFrom(file)
-	if err != nil {
-		return nil, nil, nil, err
+	fmt.Printf("\n=== Model Accuracy Summary ===\n")
+	fmt.Printf("%-40s %10s %10s %8s %8s  %s\n", "model", "W", "B", "correct", "margin", "proof")
+	for _, r := range results {
+		status := "verified"
+		if r.Err != nil {
+			status = "FAILED: " + r.Err.Error()
+		}
+		fmt.Printf("%-40s %10.4f %10.4f %4d/%-3d %+8d  %s\n", r.ModelPath, r.W, r.B,
+			r.Result.TotalCorrect, r.Result.AccuracySamples, r.Result.Margin.Margin, status)
 	}
+}
 
-	// Read VK
-	vk := plonk.NewVerifyingKey(ecc.BN254)
-	_, err = vk.ReadFrom(file)
+// confirmEstimate prints the estimated proving time and, when stdin is a
+// terminal, asks whether to continue. It reports whether to proceed.
+func confirmEstimate(cfg lib.PipelineConfig) bool {
+	estimate, err := lib.EstimateRuntime(cfg)
 	if err != nil {
-		return nil, nil, nil, err
+		log.Fatal("Estimating runtime failed: ", err)
 	}
+	fmt.Printf("\nEstimated proving time: %v\n", estimate.Round(time.Second))
 
-	return ccs, pk, vk, nil
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return true
+	}
+	fmt.Print("Proceed? [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		// e.g. stdin is /dev/null: nobody to ask
+		fmt.Println()
+		return true
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
 func main() {
-	fmt.Println("=== Two-Circuit Logistic Regression ZK Proof ===")
-
-	marks, labels, err := loadTestData("data/student_dataset_test.csv")
-	if err != nil {
-		log.Fatal("Error loading test data:", err)
-	}
-
-	fmt.Printf("Loaded %d test samples\n\n", len(marks))
-
-	W := -0.85735312
-	B := 50.94705066
-
-	// ========================================================================
-	// SETUP CIRCUIT 1: Linear Circuit (with caching)
-	// ========================================================================
-	fmt.Println("--- Setting up Linear Circuit ---")
-	
-	var linearSCS *cs.SparseR1CS
-	var linearPK plonk.ProvingKey
-	var linearVK plonk.VerifyingKey
-	
-	linearCacheFile := "/data/linear_circuit.cache"
-	cached := false
-	if _, err := os.Stat(linearCacheFile); err == nil {
-		// Load from cache
-		fmt.Println("Loading linear circuit from cache...")
-		linearSCS, linearPK, linearVK, err = loadCircuitData(linearCacheFile)
-		if err != nil {
-			log.Printf("Error loading cache, recompiling: %v\n", err)
-		} else {
-			fmt.Println("Linear circuit loaded from cache!")
-			cached = true
-		}
+	datasetPath := flag.String("dataset", "data/student_dataset_test.csv", "Test dataset CSV (marks,failed)")
+	positiveClass := flag.Int("positive-class", utils.DefaultLabelMapping().PositiveClass, "Dataset label of the Fail class, which the model predicts at sigmoid >= 0.5 (0 for datasets labelled 1 = Pass)")
+	modelPath := flag.String("model", "data/best_model_parameters.txt", "Model parameters file")
+	cacheDir := flag.String("cache-dir", "data", "Directory for compiled circuit caches")
+	concurrency := flag.Int("concurrency", 1, "Number of samples proved in parallel (for serve, proofs run at once)")
+	queue := flag.Int("queue", 8, "For serve: prove requests held while every -concurrency slot is busy; more get HTTP 429")
+	circuits := flag.String("circuits", "all", "Stages to run: comma-separated samples, inference, accuracy, or all")
+	dryRun := flag.Bool("dryrun", false, "Compile all circuits, report their sizes and exit without setup or proving")
+	models := flag.String("models", "", "Prove accuracy for each model (comma-separated files or directories of *.txt and *.json) and compare them")
+	includeBorderline := flag.Bool("include-borderline", false, "Count samples near the decision boundary in the accuracy proof instead of skipping them")
+	minAccuracy := flag.Float64("min-accuracy", 0.97, "Accuracy policy in (0,1] proved by the aggregator circuit")
+	estimate := flag.Bool("estimate", false, "Time one proof of each selected circuit and print the estimated total before running (asks to continue on a terminal)")
+	curveName := flag.String("curve", "bn254", "Curve to prove on: bn254 or bls12_381")
+	shuffle := flag.Bool("shuffle", false, "Shuffle the dataset before proving so chunks mix labels (reproducible with -shuffle-seed)")
+	shuffleSeed := flag.Int64("shuffle-seed", 1, "Seed for -shuffle")
+	testFrac := flag.Float64("test-frac", 0, "Prove only this fraction of the dataset, held out with a seeded train/test split (0 uses all samples)")
+	splitSeed := flag.Int64("split-seed", 1, "Seed for -test-frac")
+	backendName := flag.String("backend", "plonk", "Proof system: plonk or groth16")
+	srsPath := flag.String("srs", "", "Canonical KZG SRS file (gnark-crypto encoding) to set PLONK circuits up against instead of the development SRS")
+	checkpointDir := flag.String("checkpoint", "", "Directory to checkpoint per-sample proofs into as they are generated")
+	resume := flag.Bool("resume", false, "Reuse the proofs already in the checkpoint directory (default <cache-dir>/checkpoint) instead of proving those samples again")
+	var moreVerbose countFlag
+	flag.Var(&moreVerbose, "v", "Print per-sample detail (proofs verified, failures, disagreements); repeatable")
+	quiet := flag.Bool("q", false, "Print only the final summary")
+	histogram := flag.Bool("histogram", false, "Also print a histogram of the model's prediction confidences over the dataset (implied by -v)")
+	maxDuration := flag.Duration("max-duration", 0, "Stop starting new sample proofs after this long (e.g. 10m) and summarize the ones finished (0 waits for all)")
+	skipLinear := flag.Bool("skip-linear", false, "Prove samples with the sigmoid circuit only, on z computed off-chain (nothing then proves z = W*X + B)")
+	proveBudget := flag.Duration("prove-budget", 0, "Pad every sample and inference proof to this long (e.g. 2s) so proving time does not depend on the private inputs")
+	resultOut := flag.String("result-out", "", "Write the run's result (summary, per-sample outcomes, chunk counts and timings) to this JSON file")
+	profileDir := flag.String("profile", "", "Compile all circuits under the constraint profiler, write <circuit>.pprof files into this directory and exit")
+	flag.Parse()
+
+	labels := utils.LabelMapping{PositiveClass: *positiveClass}
+	if err := labels.Check(); err != nil {
+		log.Fatal(err)
 	}
-	
-	if !cached {
-		// Compile and setup
-		fmt.Println("Compiling linear circuit...")
-		var linearCircuit LinearCircuit
-		linearCCS, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &linearCircuit)
-		if err != nil {
-			log.Fatal("Linear circuit compilation error:", err)
-		}
-
-		linearSCS = linearCCS.(*cs.SparseR1CS)
-		linearSRS, linearSRSLagrange, err := unsafekzg.NewSRS(linearSCS)
-		if err != nil {
-			panic(err)
-		}
 
-		linearPK, linearVK, err = plonk.Setup(linearCCS, linearSRS, linearSRSLagrange)
-		if err != nil {
-			log.Fatal(err)
-		}
-		
-		// Save to cache
-		fmt.Println("Saving linear circuit to cache...")
-		if err := saveCircuitData(linearCacheFile, linearSCS, linearPK, linearVK); err != nil {
-			log.Printf("Warning: Failed to save cache: %v\n", err)
-		}
+	verbosity := lib.VerbosityNormal + lib.Verbosity(moreVerbose)
+	if verbosity > lib.VerbosityVerbose {
+		verbosity = lib.VerbosityVerbose
 	}
-	
-	fmt.Printf("Linear Circuit: %d constraints\n", linearSCS.GetNbConstraints())
-
-	// ========================================================================
-	// SETUP CIRCUIT 2: Sigmoid Circuit (with caching)
-	// ========================================================================
-	fmt.Println("\n--- Setting up Sigmoid LUT Circuit (with Lookup Table) ---")
-	
-	var sigmoidSCS *cs.SparseR1CS
-	var sigmoidPK plonk.ProvingKey
-	var sigmoidVK plonk.VerifyingKey
-	
-	sigmoidCacheFile := "/data/threshold_circuit.cache"
-	cached = false
-	if _, err := os.Stat(sigmoidCacheFile); err == nil {
-		// Load from cache
-		fmt.Println("Loading sigmoid LUT circuit from cache...")
-		sigmoidSCS, sigmoidPK, sigmoidVK, err = loadCircuitData(sigmoidCacheFile)
-		if err != nil {
-			log.Printf("Error loading cache, recompiling: %v\n", err)
-		} else {
-			fmt.Println("Sigmoid LUT circuit loaded from cache!")
-			cached = true
-		}
+	if *quiet {
+		verbosity = lib.VerbosityQuiet
 	}
-	
-	if !cached {
-		// Compile and setup
-		fmt.Println("Compiling sigmoid circuit with lookup table...")
-		var sigmoidCircuit SigmoidCircuit
-		sigmoidCCS, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &sigmoidCircuit)
-		if err != nil {
-			log.Fatal("Sigmoid circuit compilation error:", err)
-		}
-
-		sigmoidSCS = sigmoidCCS.(*cs.SparseR1CS)
-		sigmoidSRS, sigmoidSRSLagrange, err := unsafekzg.NewSRS(sigmoidSCS)
-		if err != nil {
-			panic(err)
-		}
-
-		sigmoidPK, sigmoidVK, err = plonk.Setup(sigmoidCCS, sigmoidSRS, sigmoidSRSLagrange)
-		if err != nil {
-			log.Fatal(err)
-		}
-		
-		// Save to cache
-		fmt.Println("Saving sigmoid LUT circuit to cache...")
-		if err := saveCircuitData(sigmoidCacheFile, sigmoidSCS, sigmoidPK, sigmoidVK); err != nil {
-			log.Printf("Warning: Failed to save cache: %v\n", err)
-		}
+	if verbosity < lib.VerbosityVerbose {
+		// gnark logs every solve and proof; that is per-sample detail, for the
+		// subcommands too
+		logger.Disable()
 	}
-	
-	fmt.Printf("Sigmoid LUT Circuit: %d constraints (using lookup table)\n", sigmoidSCS.GetNbConstraints())
-
-	fmt.Println("\n=== Generating Proofs for All Samples ===")
-
-	// Collect all proofs and public witnesses for batch verification
-	type ProofData struct {
-		linearProof   plonk.Proof
-		linearPublic  witness.Witness
-		sigmoidProof  plonk.Proof
-		sigmoidPublic witness.Witness
-		mark          int
-		expectedLabel int
-		sampleNum     int
-	}
-	
-	var validProofs []ProofData
-	proofGenCount := 0
-
-	for i := 0; i < len(marks); i++ {
-		mark := marks[i]
-		expectedLabel := labels[i]
-
-		// ====================================================================
-		// Generate Linear Circuit Proof
-		// ====================================================================
-		var linearWitness LinearCircuit
-		
-		w_scaled := newScaled(W)
-		b_scaled := newScaled(B)
-		x_scaled := newScaled(float64(mark))
-
-		wx_scaled2 := new(big.Int).Mul(w_scaled, x_scaled)
-		wx_scaled1 := new(big.Int).Div(wx_scaled2, scalingFactor)
-		z_scaled := new(big.Int).Add(wx_scaled1, b_scaled)
-
-		linearWitness.W = w_scaled
-		linearWitness.B = b_scaled
-		linearWitness.X = x_scaled
-		linearWitness.Z = z_scaled
-
-		linearWitnessFull, err := frontend.NewWitness(&linearWitness, ecc.BN254.ScalarField())
-		if err != nil {
-			log.Printf("Sample %d (marks=%d): Linear witness error: %v\n", i+1, mark, err)
-			continue
-		}
 
-		linearWitnessPublic, err := linearWitnessFull.Public()
-		if err != nil {
-			log.Printf("Sample %d (marks=%d): Linear public witness error: %v\n", i+1, mark, err)
-			continue
-		}
-
-		linearProof, err := plonk.Prove(linearSCS, linearPK, linearWitnessFull)
-		if err != nil {
-			log.Printf("Sample %d (marks=%d): Linear proof error: %v\n", i+1, mark, err)
-			continue
+	if *profileDir != "" {
+		runProfile(*profileDir)
+		return
+	}
+	if *dryRun {
+		runDryRun()
+		return
+	}
+	if flag.Arg(0) == "warmup" {
+		runWarmup(*cacheDir)
+		return
+	}
+	if flag.Arg(0) == "selftest" {
+		runSelfTest()
+		return
+	}
+	if flag.Arg(0) == "validate-data" {
+		if flag.NArg() != 2 {
+			log.Fatal("usage: validate-data <csv>")
 		}
-
-		// ====================================================================
-		// Generate Threshold (Sign) Circuit Proof
-		// ====================================================================
-		var sigmoidWitness SigmoidCircuit
-		
-		sigmoidWitness.Z = z_scaled
-		// Use client-provided dataset label as the asserted ground truth.
-		// The circuit will recompute prediction = (z>=0) and assert equality to this label.
-		// Mapping: 1 = Fail, 0 = Pass (consistent with z>=0 => likely Fail when W<0).
-		sigmoidWitness.Label = big.NewInt(int64(expectedLabel))
-
-		sigmoidWitnessFull, err := frontend.NewWitness(&sigmoidWitness, ecc.BN254.ScalarField())
-		if err != nil {
-			log.Printf("Sample %d (marks=%d): Sigmoid witness error: %v\n", i+1, mark, err)
-			continue
+		runValidateData(flag.Arg(1), labels)
+		return
+	}
+	if flag.Arg(0) == "serve" {
+		addr := ":8080"
+		if flag.NArg() > 1 {
+			addr = flag.Arg(1)
 		}
-
-		sigmoidWitnessPublic, err := sigmoidWitnessFull.Public()
+		curve, err := lib.ParseCurve(*curveName)
 		if err != nil {
-			log.Printf("Sample %d (marks=%d): Sigmoid public witness error: %v\n", i+1, mark, err)
-			continue
+			log.Fatal(err)
 		}
-
-		sigmoidProof, err := plonk.Prove(sigmoidSCS, sigmoidPK, sigmoidWitnessFull)
+		backend, err := lib.ParseBackend(*backendName)
 		if err != nil {
-			log.Printf("Sample %d (marks=%d): Sigmoid proof error: %v\n", i+1, mark, err)
-			continue
+			log.Fatal(err)
 		}
-
-		// Store proof data for batch verification
-		validProofs = append(validProofs, ProofData{
-			linearProof:   linearProof,
-			linearPublic:  linearWitnessPublic,
-			sigmoidProof:  sigmoidProof,
-			sigmoidPublic: sigmoidWitnessPublic,
-			mark:          mark,
-			expectedLabel: expectedLabel,
-			sampleNum:     i + 1,
+		runServe(*cacheDir, addr, server.Options{
+			Curve:               curve,
+			Backend:             backend,
+			ProveBudget:         *proveBudget,
+			MaxConcurrentProofs: *concurrency,
+			MaxQueuedProofs:     *queue,
 		})
-		proofGenCount++
-		
-		if (i+1) % 10 == 0 {
-			fmt.Printf("Generated proofs for %d/%d samples...\n", i+1, len(marks))
-		}
+		return
 	}
-
-	fmt.Printf("\nSuccessfully generated proofs for %d/%d samples\n", proofGenCount, len(marks))
-
-	// ========================================================================
-	// BATCH VERIFICATION - Much faster than individual verification!
-	// ========================================================================
-	fmt.Println("\n=== Batch Verifying All Proofs ===")
-	
-	successCount := 0
-	
-	// Verify each proof (gnark's Verify already does internal batching of KZG checks)
-	// For even better performance, you could implement custom batch verification
-	// by extracting KZG commitments and using kzg.BatchVerifyMultiPoints
-	
-	for _, pd := range validProofs {
-		// Verify linear proof
-		err := plonk.Verify(pd.linearProof, linearVK, pd.linearPublic)
-		if err != nil {
-			log.Printf("Sample %d (marks=%d): Linear verification FAILED: %v\n", pd.sampleNum, pd.mark, err)
-			continue
-		}
-
-		// Verify sigmoid proof
-		err = plonk.Verify(pd.sigmoidProof, sigmoidVK, pd.sigmoidPublic)
-		if err != nil {
-			log.Printf("Sample %d (marks=%d): Sigmoid verification FAILED: %v\n", pd.sampleNum, pd.mark, err)
-			continue
-		}
-
-		successCount++
-		labelStr := "Pass"
-		if pd.expectedLabel == 1 {
-			labelStr = "Fail"
-		}
-		fmt.Printf("✓ Sample %d: Marks=%d, Label=%s - Both proofs verified!\n", pd.sampleNum, pd.mark, labelStr)
-	}
-
-	fmt.Printf("\n=== Summary ===\n")
-	fmt.Printf("Total samples: %d\n", len(marks))
-	fmt.Printf("Proofs generated: %d\n", proofGenCount)
-	fmt.Printf("Successfully verified: %d\n", successCount)
-	fmt.Printf("Failed: %d\n", len(marks)-successCount)
-	fmt.Printf("Success rate: %.2f%%\n", float64(successCount)/float64(len(marks))*100)
-
-	// ========================================================================
-	// CHUNKED ACCURACY PROOF (4 chunks of 25 samples + aggregator)
-	// ========================================================================
-	fmt.Println("\n=== Proving Accuracy >= 97% over dataset (chunked) ===")
-
-	// Setup chunk circuit (with caching)
-	var chunkSCS *cs.SparseR1CS
-	var chunkPK plonk.ProvingKey
-	var chunkVK plonk.VerifyingKey
-
-	chunkCache := "data/accuracy_chunk_25.cache"
-	cached = false
-	if _, err := os.Stat(chunkCache); err == nil {
-		fmt.Println("Loading chunk circuit from cache...")
-		chunkSCS, chunkPK, chunkVK, err = loadCircuitData(chunkCache)
-		if err != nil {
-			log.Printf("Error loading cache, recompiling: %v\n", err)
-		} else {
-			fmt.Println("Chunk circuit loaded from cache!")
-			cached = true
+	if flag.Arg(0) == "inspect" {
+		if flag.NArg() != 2 {
+			log.Fatal("usage: inspect <cachefile>")
 		}
+		runInspect(flag.Arg(1))
+		return
 	}
 
-	if !cached {
-		fmt.Println("Compiling chunk circuit (25 samples)...")
-		var chunk AccuracyChunkCircuit
-		chunkCCS, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &chunk)
-		if err != nil {
-			log.Fatal("Chunk circuit compilation error:", err)
-		}
-		chunkSCS = chunkCCS.(*cs.SparseR1CS)
-		chunkSRS, chunkSRSLagrange, err := unsafekzg.NewSRS(chunkSCS)
-		if err != nil {
-			panic(err)
-		}
-		chunkPK, chunkVK, err = plonk.Setup(chunkCCS, chunkSRS, chunkSRSLagrange)
-		if err != nil {
+	circuitSet, err := lib.ParseCircuitSet(*circuits)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *minAccuracy <= 0 || *minAccuracy > 1 {
+		log.Fatalf("-min-accuracy must be in (0,1], got %v", *minAccuracy)
+	}
+	curve, err := lib.ParseCurve(*curveName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	backend, err := lib.ParseBackend(*backendName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var srs kzg.SRS
+	if *srsPath != "" {
+		if srs, err = lib.LoadSRS(curve, *srsPath); err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println("Saving chunk circuit to cache...")
-		if err := saveCircuitData(chunkCache, chunkSCS, chunkPK, chunkVK); err != nil {
-			log.Printf("Warning: Failed to save cache: %v\n", err)
-		}
 	}
-	fmt.Printf("Chunk Circuit: %d constraints\n", chunkSCS.GetNbConstraints())
-
-	// Generate 4 chunk proofs
-	numChunks := 4
-	chunkCounts := make([]int, numChunks)
-	
-	for chunkIdx := 0; chunkIdx < numChunks; chunkIdx++ {
-		startIdx := chunkIdx * ChunkSize
-		endIdx := startIdx + ChunkSize
-
-		var chunkWitness AccuracyChunkCircuit
-		chunkWitness.W = newScaled(W)
-		chunkWitness.B = newScaled(B)
-		for i := 0; i < ChunkSize; i++ {
-			chunkWitness.X[i] = newScaled(float64(marks[startIdx+i]))
-			chunkWitness.Label[i] = big.NewInt(int64(labels[startIdx+i]))
-		}
 
-		chunkFull, err := frontend.NewWitness(&chunkWitness, ecc.BN254.ScalarField())
-		if err != nil {
-			log.Fatalf("Chunk %d witness error: %v\n", chunkIdx+1, err)
-		}
-
-		chunkProof, err := plonk.Prove(chunkSCS, chunkPK, chunkFull)
-		if err != nil {
-			log.Fatalf("Chunk %d proof error: %v\n", chunkIdx+1, err)
-		}
+	if *resume && *checkpointDir == "" {
+		*checkpointDir = filepath.Join(*cacheDir, "checkpoint")
+	}
+	// Ctrl-C stops starting new sample proofs; finished ones stay checkpointed.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := lib.PipelineConfig{
+		DatasetPath:       *datasetPath,
+		Labels:            &labels,
+		ModelPath:         *modelPath,
+		CacheDir:          *cacheDir,
+		Concurrency:       *concurrency,
+		Circuits:          circuitSet,
+		MinAccuracy:       *minAccuracy,
+		IncludeBorderline: *includeBorderline,
+		Curve:             curve,
+		Backend:           backend,
+		SRS:               srs,
+		Shuffle:           *shuffle,
+		ShuffleSeed:       *shuffleSeed,
+		TestFraction:      *testFrac,
+		SplitSeed:         *splitSeed,
+		CheckpointDir:     *checkpointDir,
+		Resume:            *resume,
+		Context:           ctx,
+		MaxDuration:       *maxDuration,
+		ProveBudget:       *proveBudget,
+		SkipLinear:        *skipLinear,
+		Verbosity:         verbosity,
+		Progress: func(done, total int) {
+			if done%10 == 0 {
+				fmt.Printf("Generated proofs for %d/%d samples...\n", done, total)
+			}
+		},
+		Logf: func(format string, args ...any) {
+			fmt.Printf(format, args...)
+		},
+	}
+	if *models != "" {
+		runModels(cfg, *models)
+		return
+	}
 
-		// Compute count off-chain for aggregator input
-		count := 0
-		for i := startIdx; i < endIdx; i++ {
-			zf := W*float64(marks[i]) + B
-			pred := 0
-			if zf >= 0 { pred = 1 }
-			if pred == labels[i] { count++ }
-		}
-		chunkCounts[chunkIdx] = count
-		
-		fmt.Printf("Chunk %d: proved %d/25 correct\n", chunkIdx+1, count)
-		_ = chunkProof // Store if needed for verification
-	}
-
-	// Setup aggregator circuit
-	var aggSCS *cs.SparseR1CS
-	var aggPK plonk.ProvingKey
-	var aggVK plonk.VerifyingKey
-
-	aggCache := "aggregator_circuit.cache"
-	cached = false
-	if _, err := os.Stat(aggCache); err == nil {
-		fmt.Println("Loading aggregator circuit from cache...")
-		aggSCS, aggPK, aggVK, err = loadCircuitData(aggCache)
-		if err != nil {
-			log.Printf("Error loading cache, recompiling: %v\n", err)
-		} else {
-			fmt.Println("Aggregator circuit loaded from cache!")
-			cached = true
-		}
+	if *estimate && !confirmEstimate(cfg) {
+		return
 	}
 
-	if !cached {
-		fmt.Println("Compiling aggregator circuit...")
-		var agg AggregatorCircuit
-		aggCCS, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &agg)
-		if err != nil {
-			log.Fatal("Aggregator circuit compilation error:", err)
-		}
-		aggSCS = aggCCS.(*cs.SparseR1CS)
-		aggSRS, aggSRSLagrange, err := unsafekzg.NewSRS(aggSCS)
-		if err != nil {
-			panic(err)
-		}
-		aggPK, aggVK, err = plonk.Setup(aggCCS, aggSRS, aggSRSLagrange)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Println("Saving aggregator circuit to cache...")
-		if err := saveCircuitData(aggCache, aggSCS, aggPK, aggVK); err != nil {
-			log.Printf("Warning: Failed to save cache: %v\n", err)
-		}
+	if *histogram || verbosity >= lib.VerbosityVerbose {
+		printConfidenceHistogram(*datasetPath, *modelPath)
 	}
-	fmt.Printf("Aggregator Circuit: %d constraints\n", aggSCS.GetNbConstraints())
 
-	// Generate aggregator proof
-	var aggWitness AggregatorCircuit
-	aggWitness.Count1 = big.NewInt(int64(chunkCounts[0]))
-	aggWitness.Count2 = big.NewInt(int64(chunkCounts[1]))
-	aggWitness.Count3 = big.NewInt(int64(chunkCounts[2]))
-	aggWitness.Count4 = big.NewInt(int64(chunkCounts[3]))
+	if verbosity >= lib.VerbosityNormal {
+		fmt.Println("=== Two-Circuit Logistic Regression ZK Proof ===")
+	}
 
-	aggFull, err := frontend.NewWitness(&aggWitness, ecc.BN254.ScalarField())
+	result, err := lib.RunPipeline(cfg)
 	if err != nil {
-		log.Fatal("Aggregator witness error:", err)
+		if m := result.Margin; m.Threshold != 0 {
+			fmt.Printf("Accuracy: total=%d threshold=%d margin=%+d\n", m.Total, m.Threshold, m.Margin)
+		}
+		log.Fatal("Pipeline failed: ", err)
 	}
-	aggPublic, err := aggFull.Public()
-	if err != nil {
-		log.Fatal("Aggregator public witness error:", err)
+	if *resultOut != "" {
+		if err := result.Save(*resultOut); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	aggProof, err := plonk.Prove(aggSCS, aggPK, aggFull)
-	if err != nil {
-		log.Fatal("Aggregator proof error:", err)
+	if circuitSet.Has(lib.CircuitsPerSample) {
+		avg := result.Timings.Average()
+		fmt.Printf("\n=== Summary ===\n")
+		fmt.Printf("Total samples: %d\n", result.TotalSamples)
+		if result.Partial {
+			fmt.Printf("Partial run: %d of %d samples proven before -max-duration\n", result.ProofsGenerated, result.TotalSamples)
+		}
+		fmt.Printf("Proofs generated: %d\n", result.ProofsGenerated)
+		if result.Resumed > 0 {
+			fmt.Printf("Resumed from checkpoint: %d\n", result.Resumed)
+		}
+		fmt.Printf("Successfully verified: %d\n", result.Verified)
+		// unattempted samples of a partial run have not failed
+		fmt.Printf("Failed: %d\n", len(result.Samples)-result.Verified)
+		fmt.Printf("Success rate: %.2f%%\n", float64(result.Verified)/float64(result.TotalSamples)*100)
+		fmt.Printf("LUT saturation: %d/%d samples with |z| beyond the table\n", result.Saturated, result.TotalSamples)
+		fmt.Printf("Float/circuit disagreements: %d\n", len(result.Disagreements))
+		if *skipLinear {
+			fmt.Printf("Avg per sample (linear proofs skipped): sigmoid witness %v, sigmoid prove %v\n",
+				avg.SigmoidWitness.Round(time.Microsecond), avg.SigmoidProve.Round(time.Microsecond))
+		} else {
+			fmt.Printf("Avg per sample: linear witness %v, linear prove %v, sigmoid witness %v, sigmoid prove %v\n",
+				avg.LinearWitness.Round(time.Microsecond), avg.LinearProve.Round(time.Microsecond),
+				avg.SigmoidWitness.Round(time.Microsecond), avg.SigmoidProve.Round(time.Microsecond))
+		}
+		fmt.Printf("Verification: %v wall-clock for %d samples\n", result.VerifyTime.Round(time.Millisecond), result.ProofsGenerated)
 	}
-	if err := plonk.Verify(aggProof, aggVK, aggPublic); err != nil {
-		log.Fatal("Aggregator verification FAILED:", err)
+	if circuitSet.Has(lib.CircuitsAccuracy) && result.AccuracyVerified {
+		fmt.Printf("\nAccuracy proof verified (chunked). Total correct=%d/%d (%.2f%%) >= %d\n",
+			result.TotalCorrect, result.AccuracySamples, float64(result.TotalCorrect)*100.0/float64(result.AccuracySamples), result.MinCorrect)
+		fmt.Printf("Accuracy: total=%d threshold=%d margin=%+d\n", result.Margin.Total, result.Margin.Threshold, result.Margin.Margin)
 	}
-
-	totalCorrect := chunkCounts[0] + chunkCounts[1] + chunkCounts[2] + chunkCounts[3]
-	fmt.Printf("\nAccuracy proof verified (chunked). Total correct=%d/%d (%.2f%%) >= 97%%\n", 
-		totalCorrect, len(marks), float64(totalCorrect)*100.0/float64(len(marks)))
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mainArgsEnv, when set, makes the test binary run main with its
+// newline-separated arguments instead of the tests, so a test can check what
+// a command prints and how it exits.
+const mainArgsEnv = "ZKLR_TEST_MAIN_ARGS"
+
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv(mainArgsEnv); ok {
+		os.Args = append([]string{"zklr"}, strings.Split(args, "\n")...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs main with args in a subprocess, returning its combined
+// output and exit code.
+func runMain(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), mainArgsEnv+"="+strings.Join(args, "\n"))
+	out, err := cmd.CombinedOutput()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return string(out), exit.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+func TestValidateData(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	good := write("good.csv", "marks,failed\n10,1\n90,0\n45,1\n70,0\n80,0\n")
+
+	tests := []struct {
+		name   string
+		args   []string
+		exit   int
+		output []string
+	}{
+		{"valid", []string{"validate-data", good}, 0, []string{"Samples: 5", "Label 0 (pass): 3 (60.0%)", "Label 1 (fail): 2 (40.0%)", "Dataset is valid"}},
+		{"valid, 1 = Pass", []string{"-positive-class=0", "validate-data", good}, 0, []string{"Label 0 (fail): 3", "Label 1 (pass): 2", "Dataset is valid"}},
+		{"unparsable marks", []string{"validate-data", write("marks.csv", "marks,failed\n10,1\nten,0\n")}, 1, []string{"Invalid dataset", "line 3"}},
+		{"missing label column", []string{"validate-data", write("columns.csv", "marks,failed\n10,1\n20\n")}, 1, []string{"Invalid dataset", "line 3"}},
+		{"label not 0 or 1", []string{"validate-data", write("label.csv", "marks,failed\n10,1\n20,2\n")}, 1, []string{"Invalid dataset"}},
+		{"marks out of range", []string{"validate-data", write("range.csv", "marks,failed\n10,1\n120,0\n")}, 1, []string{"Invalid dataset"}},
+		{"missing file", []string{"validate-data", filepath.Join(dir, "missing.csv")}, 1, []string{"Invalid dataset"}},
+		{"no file", []string{"validate-data"}, 1, []string{"usage: validate-data <csv>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, exit := runMain(t, tt.args...)
+			if exit != tt.exit {
+				t.Errorf("exit code %d, want %d:\n%s", exit, tt.exit, out)
+			}
+			for _, want := range tt.output {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+			if tt.exit != 0 && strings.Contains(out, "Dataset is valid") {
+				t.Errorf("invalid dataset reported valid:\n%s", out)
+			}
+		})
+	}
+}
@@ -28,6 +28,8 @@ func main() {
 	} else {
 		log.Println("Starting actual proof generation...")
 		log.Println("This will take several minutes. Use -animated flag for quick simulation.")
-		simulation.RunWithActualProofs()
+		if err := simulation.RunWithActualProofs(datasetFile, modelFile); err != nil {
+			log.Fatalf("Proof run failed: %v", err)
+		}
 	}
 }
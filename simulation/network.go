@@ -1,17 +1,19 @@
 package simulation
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/santhoshcheemala/ZKLR/lib"
 	"github.com/santhoshcheemala/ZKLR/utils"
 )
 
 type NetworkSimulation struct {
-	datasetFile  string
-	modelFile    string
-	latency      time.Duration
+	datasetFile   string
+	modelFile     string
+	latency       time.Duration
 	clientDataset []utils.Sample
 }
 
@@ -31,6 +33,154 @@ func NewNetworkSimulation(datasetFile, modelFile string, latency time.Duration)
 	}, nil
 }
 
+// EventKind is what an Event reports.
+type EventKind int
+
+const (
+	// PhaseStarted opens one of the four phases; Phase is set.
+	PhaseStarted EventKind = iota
+	// Step is a message sent or work done within a phase.
+	Step
+	// SampleProven and SampleFailed end a per-sample round trip; Sample,
+	// Marks and Label are set.
+	SampleProven
+	SampleFailed
+	// ChunkVerified ends a chunk's round trip; Chunk and Count are set.
+	ChunkVerified
+	// AggregatorVerified ends the aggregator phase.
+	AggregatorVerified
+	// SimulationCompleted and SimulationFailed are the last event; Err is
+	// set on failure.
+	SimulationCompleted
+	SimulationFailed
+)
+
+var eventKindNames = [...]string{"PhaseStarted", "Step", "SampleProven", "SampleFailed", "ChunkVerified", "AggregatorVerified", "SimulationCompleted", "SimulationFailed"}
+
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+	return eventKindNames[k]
+}
+
+// Event is one step of the simulation, for a front-end to render live.
+// Message is the narration RunDistributed logs for it.
+type Event struct {
+	Kind    EventKind
+	Phase   int // 1-4, the phase the event belongs to
+	Message string
+
+	Sample int // 1-based sample number
+	Marks  float64
+	Label  int
+
+	Chunk int // 1-based chunk number
+	Count int // correct predictions proved for Chunk
+
+	Err error
+}
+
+// Events runs the simulation in the background and streams its events,
+// sleeping the simulated latency between them. The channel is closed after
+// SimulationCompleted or SimulationFailed, or once ctx is done, which stops
+// the simulation early.
+func (ns *NetworkSimulation) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		s := &stream{ctx: ctx, ch: ch}
+		if err := ns.run(s); err != nil {
+			s.emit(Event{Kind: SimulationFailed, Phase: s.phase, Message: err.Error(), Err: err})
+			return
+		}
+		s.emit(Event{Kind: SimulationCompleted, Phase: s.phase, Message: "Simulation complete"})
+	}()
+	return ch
+}
+
+// stream delivers a running simulation's events.
+type stream struct {
+	ctx   context.Context
+	ch    chan<- Event
+	phase int
+}
+
+// emit sends e and reports whether the consumer is still listening.
+func (s *stream) emit(e Event) bool {
+	select {
+	case s.ch <- e:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+func (s *stream) startPhase(phase int, message string) error {
+	s.phase = phase
+	return s.send(Event{Kind: PhaseStarted, Message: message})
+}
+
+// send emits e in the current phase, failing once ctx is done.
+func (s *stream) send(e Event) error {
+	e.Phase = s.phase
+	if !s.emit(e) {
+		return s.ctx.Err()
+	}
+	return nil
+}
+
+func (s *stream) step(format string, args ...any) error {
+	return s.send(Event{Kind: Step, Message: fmt.Sprintf(format, args...)})
+}
+
+// steps emits each message in turn, sleeping its delay after it.
+func (s *stream) steps(steps ...timedStep) error {
+	for _, st := range steps {
+		if err := s.step("%s", st.message); err != nil {
+			return err
+		}
+		if err := s.sleep(st.delay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type timedStep struct {
+	message string
+	delay   time.Duration
+}
+
+// sleep waits d of simulated latency, failing once ctx is done.
+func (s *stream) sleep(d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (ns *NetworkSimulation) run(s *stream) error {
+	if err := ns.runSetup(s); err != nil {
+		return fmt.Errorf("setup phase: %w", err)
+	}
+	if err := ns.runSampleProofs(s); err != nil {
+		return fmt.Errorf("per-sample proof phase: %w", err)
+	}
+	if err := ns.runChunkProofs(s); err != nil {
+		return fmt.Errorf("chunked accuracy phase: %w", err)
+	}
+	if err := ns.runAggregator(s); err != nil {
+		return fmt.Errorf("aggregator phase: %w", err)
+	}
+	return nil
+}
+
+// RunDistributed runs the simulation and logs its events.
 func (ns *NetworkSimulation) RunDistributed() error {
 	log.Println("\n╔════════════════════════════════════════════════════════════╗")
 	log.Println("║   ZK-Proof Distributed Network Simulation                ║")
@@ -42,87 +192,19 @@ func (ns *NetworkSimulation) RunDistributed() error {
 	log.Printf("  - Model: %s\n", ns.modelFile)
 	log.Printf("  - Simulated Latency: %v\n\n", ns.latency)
 
-	// Phase 1: Setup
-	log.Println("=== Phase 1: Setup ===")
-	log.Println("Client → Server: Establishing connection...")
-	time.Sleep(ns.latency)
-	
-	log.Println("Server: Compiling/loading ZK circuits...")
-	log.Println("  - Linear Circuit (Z = W*X + B)")
-	log.Println("  - Sigmoid LUT Circuit (prediction)")
-	log.Println("  - Chunk Accuracy Circuit (25 samples)")
-	log.Println("  - Aggregator Circuit (≥97% threshold)")
-	time.Sleep(ns.latency / 2)
-	
-	log.Println("Server → Client: Sending verifying keys...")
-	time.Sleep(ns.latency)
-	log.Println("✓ Setup complete!")
-
-	log.Println("=== Phase 2: Per-Sample Proof Demonstration ===")
-	log.Println("(Simulating first 10 samples)")
-	
-	for i := 0; i < 10 && i < len(ns.clientDataset); i++ {
-		sample := ns.clientDataset[i]
-		
-		log.Printf("[Sample %d] marks=%.1f, label=%d\n", i+1, sample.Marks, sample.Label)
-		log.Printf("  Client → Server: Sending sample...")
-		time.Sleep(ns.latency / 10)
-		
-		log.Printf("  Server: Generating proofs (linear + sigmoid)...")
-		time.Sleep(ns.latency / 5)
-		
-		log.Printf("  Server → Client: Sending proofs...")
-		time.Sleep(ns.latency / 10)
-		
-		log.Printf("  Client: Verifying proofs...")
-		time.Sleep(ns.latency / 20)
-		
-		if sample.Marks > 55 {
-			log.Printf("  ✓ Both proofs verified!\n\n")
-		} else {
-			log.Printf("  ⚠ Proof generation failed (model prediction mismatch)\n\n")
+	for e := range ns.Events(context.Background()) {
+		switch e.Kind {
+		case SimulationFailed:
+			return e.Err
+		case SimulationCompleted:
+			continue
+		case SampleProven, SampleFailed, ChunkVerified:
+			log.Printf("%s\n\n", e.Message)
+		default:
+			log.Println(e.Message)
 		}
 	}
 
-	log.Println("=== Phase 3: Chunked Accuracy Proof ===")
-	log.Println("Processing all 100 samples in 4 chunks of 25...")
-	
-	for chunk := 1; chunk <= 4; chunk++ {
-		log.Printf("[Chunk %d/4] (samples %d-%d)\n", chunk, (chunk-1)*25+1, chunk*25)
-		log.Printf("  Client → Server: Sending 25 samples...")
-		time.Sleep(ns.latency)
-		
-		log.Printf("  Server: Computing predictions for 25 samples...")
-		time.Sleep(ns.latency * 2)
-		
-		log.Printf("  Server: Generating chunk proof (~404k constraints)...")
-		time.Sleep(ns.latency * 3)
-		
-		log.Printf("  Server → Client: Sending chunk proof...")
-		time.Sleep(ns.latency)
-		
-		log.Printf("  Client: Verifying chunk proof...")
-		time.Sleep(ns.latency / 2)
-		
-		log.Printf("  ✓ Chunk %d verified! Count: 25/25 correct\n\n", chunk)
-	}
-
-	log.Println("=== Phase 4: Aggregator Proof ===")
-	log.Println("Server: Aggregating results from 4 chunks...")
-	time.Sleep(ns.latency)
-	
-	log.Println("Server: Generating aggregator proof (total ≥97%)...")
-	time.Sleep(ns.latency * 2)
-	
-	log.Println("Server → Client: Sending aggregator proof...")
-	time.Sleep(ns.latency)
-	
-	log.Println("Client: Verifying aggregator proof...")
-	time.Sleep(ns.latency / 2)
-	
-	log.Println("\n✓ Aggregator proof verified!")
-	log.Println("✓ Accuracy threshold met: 100/100 (100%) ≥ 97%")
-
 	log.Println("╔════════════════════════════════════════════════════════════╗")
 	log.Println("║                   Simulation Complete                     ║")
 	log.Println("╚════════════════════════════════════════════════════════════╝")
@@ -139,12 +221,136 @@ func (ns *NetworkSimulation) RunDistributed() error {
 	return nil
 }
 
-func RunWithActualProofs() {
+// Phase 1: Setup
+func (ns *NetworkSimulation) runSetup(s *stream) error {
+	if len(ns.clientDataset) == 0 {
+		return fmt.Errorf("client dataset %s is empty", ns.datasetFile)
+	}
+
+	if err := s.startPhase(1, "=== Phase 1: Setup ==="); err != nil {
+		return err
+	}
+	if err := s.steps(
+		timedStep{"Client → Server: Establishing connection...", ns.latency},
+		timedStep{"Server: Compiling/loading ZK circuits...", 0},
+		timedStep{"  - Linear Circuit (Z = W*X + B)", 0},
+		timedStep{"  - Sigmoid LUT Circuit (prediction)", 0},
+		timedStep{"  - Chunk Accuracy Circuit (25 samples)", 0},
+		timedStep{"  - Aggregator Circuit (≥97% threshold)", ns.latency / 2},
+		timedStep{"Server → Client: Sending verifying keys...", ns.latency},
+	); err != nil {
+		return err
+	}
+	return s.step("✓ Setup complete!")
+}
+
+// Phase 2: Per-sample proofs
+func (ns *NetworkSimulation) runSampleProofs(s *stream) error {
+	if err := s.startPhase(2, "=== Phase 2: Per-Sample Proof Demonstration ==="); err != nil {
+		return err
+	}
+	if err := s.step("(Simulating first 10 samples)"); err != nil {
+		return err
+	}
+
+	for i := 0; i < 10 && i < len(ns.clientDataset); i++ {
+		sample := ns.clientDataset[i]
+
+		if err := s.steps(
+			timedStep{fmt.Sprintf("[Sample %d] marks=%.1f, label=%d", i+1, sample.Marks, sample.Label), 0},
+			timedStep{"  Client → Server: Sending sample...", ns.latency / 10},
+			timedStep{"  Server: Generating proofs (linear + sigmoid)...", ns.latency / 5},
+			timedStep{"  Server → Client: Sending proofs...", ns.latency / 10},
+			timedStep{"  Client: Verifying proofs...", ns.latency / 20},
+		); err != nil {
+			return err
+		}
+
+		e := Event{Kind: SampleProven, Sample: i + 1, Marks: sample.Marks, Label: sample.Label, Message: "  ✓ Both proofs verified!"}
+		if sample.Marks <= 55 {
+			e.Kind = SampleFailed
+			e.Message = "  ⚠ Proof generation failed (model prediction mismatch)"
+		}
+		if err := s.send(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Phase 3: Chunked accuracy proof
+func (ns *NetworkSimulation) runChunkProofs(s *stream) error {
+	if len(ns.clientDataset) < 4*25 {
+		return fmt.Errorf("need 100 samples for 4 chunks of 25, have %d", len(ns.clientDataset))
+	}
+
+	if err := s.startPhase(3, "=== Phase 3: Chunked Accuracy Proof ==="); err != nil {
+		return err
+	}
+	if err := s.step("Processing all 100 samples in 4 chunks of 25..."); err != nil {
+		return err
+	}
+
+	for chunk := 1; chunk <= 4; chunk++ {
+		if err := s.steps(
+			timedStep{fmt.Sprintf("[Chunk %d/4] (samples %d-%d)", chunk, (chunk-1)*25+1, chunk*25), 0},
+			timedStep{"  Client → Server: Sending 25 samples...", ns.latency},
+			timedStep{"  Server: Computing predictions for 25 samples...", ns.latency * 2},
+			timedStep{"  Server: Generating chunk proof (~404k constraints)...", ns.latency * 3},
+			timedStep{"  Server → Client: Sending chunk proof...", ns.latency},
+			timedStep{"  Client: Verifying chunk proof...", ns.latency / 2},
+		); err != nil {
+			return err
+		}
+
+		err := s.send(Event{Kind: ChunkVerified, Chunk: chunk, Count: 25,
+			Message: fmt.Sprintf("  ✓ Chunk %d verified! Count: 25/25 correct", chunk)})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Phase 4: Aggregator proof
+func (ns *NetworkSimulation) runAggregator(s *stream) error {
+	if err := s.startPhase(4, "=== Phase 4: Aggregator Proof ==="); err != nil {
+		return err
+	}
+	if err := s.steps(
+		timedStep{"Server: Aggregating results from 4 chunks...", ns.latency},
+		timedStep{"Server: Generating aggregator proof (total ≥97%)...", ns.latency * 2},
+		timedStep{"Server → Client: Sending aggregator proof...", ns.latency},
+		timedStep{"Client: Verifying aggregator proof...", ns.latency / 2},
+	); err != nil {
+		return err
+	}
+	return s.send(Event{Kind: AggregatorVerified,
+		Message: "\n✓ Aggregator proof verified!\n✓ Accuracy threshold met: 100/100 (100%) ≥ 97%"})
+}
+
+// RunWithActualProofs runs the full proving pipeline over the same dataset
+// and model the animated simulation uses.
+func RunWithActualProofs(datasetFile, modelFile string) error {
 	log.Println("\n╔════════════════════════════════════════════════════════════╗")
 	log.Println("║   Running ACTUAL ZK Proof System                         ║")
 	log.Println("║   (This will generate and verify real proofs)            ║")
 	log.Println("╚════════════════════════════════════════════════════════════╝")
-	log.Println("NOTE: The main.go implementation will now run with real proof generation.")
 	log.Println("This may take several minutes as it generates actual ZK-SNARK proofs.")
-	log.Println("See main.go output above for detailed results.")
+
+	result, err := lib.RunPipeline(lib.PipelineConfig{
+		DatasetPath: datasetFile,
+		ModelPath:   modelFile,
+		CacheDir:    "data",
+		Logf: func(format string, args ...any) {
+			log.Printf(format, args...)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✓ Verified %d/%d samples, accuracy proof verified: %v (%d/%d correct)\n",
+		result.Verified, result.TotalSamples, result.AccuracyVerified, result.TotalCorrect, result.TotalSamples)
+	return nil
 }
@@ -0,0 +1,129 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/santhoshcheemala/ZKLR/utils"
+)
+
+// dataset returns n samples with marks 50, 51, ..., so the animated
+// per-sample phase fails the first six (marks <= 55) and proves the rest.
+func dataset(n int) []utils.Sample {
+	samples := make([]utils.Sample, n)
+	for i := range samples {
+		samples[i] = utils.Sample{Marks: float64(50 + i%50), Label: i % 2}
+	}
+	return samples
+}
+
+// outcome is an event other than a Step, in the order it was emitted.
+type outcome struct {
+	kind  EventKind
+	phase int
+	id    int // Sample or Chunk
+}
+
+func TestEventsSequence(t *testing.T) {
+	var samples []outcome
+	for i := 1; i <= 10; i++ {
+		kind := SampleProven
+		if i <= 6 {
+			kind = SampleFailed
+		}
+		samples = append(samples, outcome{kind, 2, i})
+	}
+	var chunks []outcome
+	for c := 1; c <= 4; c++ {
+		chunks = append(chunks, outcome{ChunkVerified, 3, c})
+	}
+	completed := slices.Concat(
+		[]outcome{{PhaseStarted, 1, 0}, {PhaseStarted, 2, 0}},
+		samples,
+		[]outcome{{PhaseStarted, 3, 0}},
+		chunks,
+		[]outcome{{PhaseStarted, 4, 0}, {AggregatorVerified, 4, 0}, {SimulationCompleted, 4, 0}},
+	)
+
+	tests := []struct {
+		name    string
+		samples []utils.Sample
+		want    []outcome
+	}{
+		{"complete", dataset(100), completed},
+		// the per-sample phase runs, then there are too few for the chunks
+		{"too few for chunks", dataset(50), slices.Concat(
+			[]outcome{{PhaseStarted, 1, 0}, {PhaseStarted, 2, 0}},
+			samples,
+			[]outcome{{SimulationFailed, 2, 0}},
+		)},
+		{"empty dataset", nil, []outcome{{SimulationFailed, 0, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &NetworkSimulation{datasetFile: "test.csv", clientDataset: tt.samples}
+			var got []outcome
+			var events []Event
+			for e := range ns.Events(context.Background()) {
+				events = append(events, e)
+				if e.Kind == Step {
+					continue
+				}
+				got = append(got, outcome{e.Kind, e.Phase, e.Sample + e.Chunk})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("events %v,\nwant %v", got, tt.want)
+			}
+
+			for _, e := range events {
+				if e.Message == "" {
+					t.Errorf("%v event in phase %d has no message", e.Kind, e.Phase)
+				}
+				if e.Kind == SampleProven || e.Kind == SampleFailed {
+					if s := tt.samples[e.Sample-1]; e.Marks != s.Marks || e.Label != s.Label {
+						t.Errorf("sample %d event has marks %v, label %d; want %v, %d", e.Sample, e.Marks, e.Label, s.Marks, s.Label)
+					}
+				}
+				if e.Kind == ChunkVerified && e.Count != 25 {
+					t.Errorf("chunk %d count %d, want 25", e.Chunk, e.Count)
+				}
+			}
+			last := events[len(events)-1]
+			if (last.Kind == SimulationFailed) != (last.Err != nil) {
+				t.Errorf("last event %v has error %v", last.Kind, last.Err)
+			}
+		})
+	}
+}
+
+func TestEventsCancelled(t *testing.T) {
+	ns := &NetworkSimulation{datasetFile: "test.csv", clientDataset: dataset(100)}
+	ctx, cancel := context.WithCancel(context.Background())
+	events := ns.Events(ctx)
+	if e := <-events; e.Kind != PhaseStarted || e.Phase != 1 {
+		t.Fatalf("first event %v in phase %d, want phase 1 starting", e.Kind, e.Phase)
+	}
+	cancel()
+	// the simulation stops and closes the channel without finishing; an
+	// event already waiting to be sent may still arrive
+	for e := range events {
+		if e.Kind == SimulationCompleted || e.Kind == SimulationFailed && !errors.Is(e.Err, context.Canceled) {
+			t.Errorf("%v after cancelling: %v", e.Kind, e.Err)
+		}
+	}
+}
+
+func TestEventKindString(t *testing.T) {
+	for k, want := range map[EventKind]string{
+		PhaseStarted:     "PhaseStarted",
+		SimulationFailed: "SimulationFailed",
+		EventKind(-1):    "EventKind(-1)",
+		EventKind(99):    "EventKind(99)",
+	} {
+		if got := k.String(); got != want {
+			t.Errorf("EventKind(%d).String() = %q, want %q", int(k), got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+// Confidence returns sigmoid(w*x + b), the model's probability for label 1
+// (Fail). Values near 0.5 are the samples near the decision boundary that
+// the accuracy circuits may skip as borderline.
+func Confidence(w, b, x float64) float64 {
+	return Sigmoid(w*x + b)
+}
+
+// Confidences returns Confidence for every sample.
+func Confidences(w, b float64, samples []Sample) []float64 {
+	confidences := make([]float64, len(samples))
+	for i, s := range samples {
+		confidences[i] = Confidence(w, b, s.Marks)
+	}
+	return confidences
+}
+
+// ConfidenceHistogram counts confidences into bins equal-width bins over
+// [0, 1]; bin i covers [i/bins, (i+1)/bins), with 1 in the last bin. Values
+// outside [0, 1] are clamped into the end bins.
+func ConfidenceHistogram(confidences []float64, bins int) []int {
+	if bins < 1 {
+		return nil
+	}
+	hist := make([]int, bins)
+	for _, c := range confidences {
+		i := int(c * float64(bins))
+		if i < 0 {
+			i = 0
+		}
+		if i >= bins {
+			i = bins - 1
+		}
+		hist[i]++
+	}
+	return hist
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// logit returns the z at which Sigmoid(z) = p.
+func logit(p float64) float64 {
+	return math.Log(p / (1 - p))
+}
+
+func TestConfidenceHistogram(t *testing.T) {
+	tests := []struct {
+		name        string
+		confidences []float64
+		bins        int
+		want        []int
+	}{
+		{"empty", nil, 10, make([]int, 10)},
+		{"no bins", []float64{0.5}, 0, nil},
+		{"one bin", []float64{0, 0.5, 1}, 1, []int{3}},
+		{"lower edges", []float64{0, 0.1, 0.5, 0.9}, 10, []int{1, 1, 0, 0, 0, 1, 0, 0, 0, 1}},
+		{"1 in the last bin", []float64{1, 0.95}, 10, []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 2}},
+		{"clamped", []float64{-0.5, 1.5}, 4, []int{1, 0, 0, 1}},
+		{"around 0.5", []float64{0.4999, 0.5, 0.5001}, 2, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConfidenceHistogram(tt.confidences, tt.bins); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfidencesFromZ(t *testing.T) {
+	// with w = 1 and b = 0 the marks are z itself
+	tests := []struct {
+		z   float64
+		bin int
+	}{
+		{-40, 0},
+		{logit(0.05), 0},
+		{logit(0.15), 1},
+		{logit(0.45), 4},
+		{0, 5},
+		{logit(0.55), 5},
+		{logit(0.85), 8},
+		{logit(0.95), 9},
+		{40, 9},
+	}
+	samples := make([]Sample, len(tests))
+	want := make([]int, 10)
+	for i, tt := range tests {
+		samples[i] = Sample{Marks: tt.z}
+		want[tt.bin]++
+	}
+	confidences := Confidences(1, 0, samples)
+	for i, tt := range tests {
+		if got := ConfidenceHistogram(confidences[i:i+1], 10); got[tt.bin] != 1 {
+			t.Errorf("z = %v (confidence %v): histogram %v, want bin %d", tt.z, confidences[i], got, tt.bin)
+		}
+	}
+	if got := ConfidenceHistogram(confidences, 10); !slices.Equal(got, want) {
+		t.Errorf("histogram %v, want %v", got, want)
+	}
+	if c := Confidence(-0.5, 10, 20); c != 0.5 {
+		t.Errorf("confidence at the boundary = %v, want 0.5", c)
+	}
+}
@@ -2,9 +2,17 @@ package utils
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
 )
 
 type Sample struct {
@@ -13,32 +21,70 @@ type Sample struct {
 }
 
 func LoadDataset(filename string) ([]Sample, error) {
+	_, samples, err := loadDataset(filename)
+	return samples, err
+}
+
+// LoadDatasets loads each file as LoadDataset does and concatenates their
+// samples in argument order, e.g. for a dataset kept as one file per chunk.
+// Every file must have the same header row as the first. Errors name the
+// file, and for bad rows the line, they came from.
+func LoadDatasets(filenames ...string) ([]Sample, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no dataset files given")
+	}
+	var header []string
+	var samples []Sample
+	for i, filename := range filenames {
+		h, s, err := loadDataset(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		if i == 0 {
+			header = h
+		} else if !slices.Equal(h, header) {
+			return nil, fmt.Errorf("%s: header %q does not match %q in %s", filename, strings.Join(h, ","), strings.Join(header, ","), filenames[0])
+		}
+		samples = append(samples, s...)
+	}
+	return samples, nil
+}
+
+// loadDataset reads a marks,label CSV, returning its header row and samples.
+func loadDataset(filename string) ([]string, []Sample, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open dataset: %w", err)
+		return nil, nil, fmt.Errorf("failed to open dataset: %w", err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	// rows are checked below, with a message naming the missing column
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
 	}
 
+	var header []string
 	var samples []Sample
 	for i, record := range records {
 		if i == 0 {
+			header = record
 			continue
 		}
+		if len(record) < 2 {
+			return nil, nil, fmt.Errorf("line %d: want marks,label columns, got %d field(s)", i+1, len(record))
+		}
 
 		marks, err := strconv.ParseFloat(record[0], 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid marks at line %d: %w", i+1, err)
+			return nil, nil, fmt.Errorf("invalid marks at line %d: %w", i+1, err)
 		}
 
 		label, err := strconv.Atoi(record[1])
 		if err != nil {
-			return nil, fmt.Errorf("invalid label at line %d: %w", i+1, err)
+			return nil, nil, fmt.Errorf("invalid label at line %d: %w", i+1, err)
 		}
 
 		samples = append(samples, Sample{
@@ -47,20 +93,149 @@ func LoadDataset(filename string) ([]Sample, error) {
 		})
 	}
 
-	return samples, nil
+	return header, samples, nil
 }
 
+// CheckSamples returns an error for the first sample LoadDataset accepts but
+// the circuits cannot prove: a label other than 0 or 1, or marks that are
+// NaN, infinite or outside [0, fixedpoint.MaxMarks]. It names the sample's line, counting one header row.
+func CheckSamples(samples []Sample) error {
+	if len(samples) == 0 {
+		return fmt.Errorf("dataset has no samples")
+	}
+	for i, s := range samples {
+		if s.Label != 0 && s.Label != 1 {
+			return fmt.Errorf("line %d: label %d is not 0 or 1", i+2, s.Label)
+		}
+		if math.IsNaN(s.Marks) || math.IsInf(s.Marks, 0) {
+			return fmt.Errorf("line %d: marks %v are not finite", i+2, s.Marks)
+		}
+		if s.Marks < 0 || s.Marks > fixedpoint.MaxMarks {
+			return fmt.Errorf("line %d: marks %v are outside [0, %d]", i+2, s.Marks, fixedpoint.MaxMarks)
+		}
+	}
+	return nil
+}
+
+// ClassBalance returns how many samples are labelled 0 and 1.
+func ClassBalance(samples []Sample) (zeros, ones int) {
+	for _, s := range samples {
+		switch s.Label {
+		case 0:
+			zeros++
+		case 1:
+			ones++
+		}
+	}
+	return zeros, ones
+}
+
+// ShuffleDataset permutes samples in place with a PRNG seeded by seed, so
+// the same seed always yields the same order. Shuffling before chunking
+// spreads the labels of a sorted dataset evenly across chunks.
+func ShuffleDataset(samples []Sample, seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(samples), func(i, j int) {
+		samples[i], samples[j] = samples[j], samples[i]
+	})
+}
+
+// TrainTestSplit deterministically partitions samples into a training and a
+// test set: it shuffles a copy with ShuffleDataset(seed) and takes the first
+// round(testFrac*len(samples)) samples as the test set. samples itself is
+// not modified.
+func TrainTestSplit(samples []Sample, testFrac float64, seed int64) (train, test []Sample, err error) {
+	if !(testFrac > 0 && testFrac < 1) {
+		return nil, nil, fmt.Errorf("test fraction %v not in (0, 1)", testFrac)
+	}
+	shuffled := append([]Sample(nil), samples...)
+	ShuffleDataset(shuffled, seed)
+	nTest := int(math.Round(testFrac * float64(len(shuffled))))
+	return shuffled[nTest:], shuffled[:nTest], nil
+}
+
+// LoadModelParameters reads W and B from either "W: <w>\nB: <b>" or the
+// format written by scripts/train_model.py ("Coefficient: [[<w>]]" and
+// "Intercept: [<b>]"). Other lines are ignored. A .json file is read with
+// LoadModelJSON and must have a single weight.
 func LoadModelParameters(filename string) (w, b float64, err error) {
-	file, err := os.Open(filename)
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		m, err := LoadModelJSON(filename)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(m.Weights) != 1 {
+			return 0, 0, fmt.Errorf("failed to parse model parameters: %s has %d weights, the circuits take one", filename, len(m.Weights))
+		}
+		return m.Weights[0], m.Bias, nil
+	}
+
+	data, err := os.ReadFile(filename)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to open model file: %w", err)
 	}
-	defer file.Close()
 
-	_, err = fmt.Fscanf(file, "W: %f\nB: %f", &w, &b)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to parse model parameters: %w", err)
+	var haveW, haveB bool
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), "[]")
+		switch key {
+		case "W", "Coefficient":
+			w, err = strconv.ParseFloat(value, 64)
+			haveW = err == nil
+		case "B", "Intercept":
+			b, err = strconv.ParseFloat(value, 64)
+			haveB = err == nil
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse model parameters: %s: %w", key, err)
+		}
+	}
+	if !haveW || !haveB {
+		return 0, 0, fmt.Errorf("failed to parse model parameters: missing W/Coefficient or B/Intercept in %s", filename)
 	}
 
 	return w, b, nil
-}
\ No newline at end of file
+}
+
+// Model is a linear model z = Weights·x + Bias over one or more features.
+type Model struct {
+	Weights []float64
+	Bias    float64
+}
+
+// LoadModelJSON reads a model from JSON of the form
+// {"weights": [<w>, ...], "bias": <b>}. A single-feature model may give
+// "weights" as a number instead of an array. Both fields are required.
+func LoadModelJSON(filename string) (Model, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Model{}, fmt.Errorf("failed to open model file: %w", err)
+	}
+
+	var raw struct {
+		Weights json.RawMessage `json:"weights"`
+		Bias    *float64        `json:"bias"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Model{}, fmt.Errorf("failed to parse model JSON %s: %w", filename, err)
+	}
+	if raw.Weights == nil || string(raw.Weights) == "null" || raw.Bias == nil {
+		return Model{}, fmt.Errorf("failed to parse model JSON: %s needs both \"weights\" and \"bias\"", filename)
+	}
+
+	m := Model{Bias: *raw.Bias}
+	var single float64
+	if err := json.Unmarshal(raw.Weights, &single); err == nil {
+		m.Weights = []float64{single}
+	} else if err := json.Unmarshal(raw.Weights, &m.Weights); err != nil {
+		return Model{}, fmt.Errorf("failed to parse model JSON %s: weights: want a number or an array of numbers", filename)
+	}
+	if len(m.Weights) == 0 {
+		return Model{}, fmt.Errorf("failed to parse model JSON: %s has no weights", filename)
+	}
+	return m, nil
+}
@@ -0,0 +1,336 @@
+package utils
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeCSV writes content to a CSV file in a temporary directory and
+// returns its path.
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	return writeFile(t, "data.csv", content)
+}
+
+func TestLoadDatasetRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []Sample
+		wantErr string
+	}{
+		{"valid", "marks,failed\n69,0\n12.5,1\n", []Sample{{69, 0}, {12.5, 1}}, ""},
+		{"header only", "marks,failed\n", nil, ""},
+		{"short row", "marks,failed\n69,0\n70\n", nil, "line 3: want marks,label columns, got 1 field(s)"},
+		{"empty row field", "marks,failed\n69,0\n,\n", nil, "invalid marks at line 3"},
+		{"extra columns", "marks,failed,note\n69,0,x\n", []Sample{{69, 0}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadDataset(writeCSV(t, tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d samples, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sample %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadDatasetUnparsableFields(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []string
+	}{
+		{"non-numeric marks", "marks,failed\n69,0\nabc,1\n", []string{"invalid marks at line 3", `"abc"`}},
+		{"non-numeric label", "marks,failed\n69,yes\n", []string{"invalid label at line 2", `"yes"`}},
+		{"fractional label", "marks,failed\n69,0.5\n", []string{"invalid label at line 2", `"0.5"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadDataset(writeCSV(t, tt.csv))
+			if err == nil {
+				t.Fatal("loaded without error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("err = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+		wantErr string
+	}{
+		{"valid", []Sample{{0, 1}, {100, 0}}, ""},
+		{"empty", nil, "no samples"},
+		{"label 2", []Sample{{50, 0}, {50, 2}}, "line 3: label 2"},
+		{"NaN marks", []Sample{{math.NaN(), 0}}, "line 2: marks NaN are not finite"},
+		{"negative marks", []Sample{{-1, 0}}, "line 2: marks -1 are outside [0, 100]"},
+		{"marks above 100", []Sample{{100.5, 0}}, "line 2: marks 100.5 are outside [0, 100]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSamples(tt.samples)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// sortedDataset returns n samples with distinct marks 0, 1, ..., n-1, the
+// first half labelled 1 (Fail): a dataset sorted by label.
+func sortedDataset(n int) []Sample {
+	samples := make([]Sample, n)
+	for i := range samples {
+		samples[i] = Sample{Marks: float64(i)}
+		if i < n/2 {
+			samples[i].Label = 1
+		}
+	}
+	return samples
+}
+
+// sameMultiset reports whether a and b hold the same samples in any order.
+func sameMultiset(a, b []Sample) bool {
+	byMarks := func(x, y Sample) int { return cmp.Or(cmp.Compare(x.Marks, y.Marks), cmp.Compare(x.Label, y.Label)) }
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.SortFunc(a, byMarks)
+	slices.SortFunc(b, byMarks)
+	return slices.Equal(a, b)
+}
+
+func TestShuffleDataset(t *testing.T) {
+	original := sortedDataset(100)
+	shuffled := func(seed int64) []Sample {
+		s := slices.Clone(original)
+		ShuffleDataset(s, seed)
+		return s
+	}
+	for _, seed := range []int64{0, 1, 42, -7} {
+		a, b := shuffled(seed), shuffled(seed)
+		if !slices.Equal(a, b) {
+			t.Errorf("seed %d: two shuffles differ", seed)
+		}
+		if !sameMultiset(a, original) {
+			t.Errorf("seed %d: shuffling changed the samples", seed)
+		}
+		if slices.Equal(a, original) {
+			t.Errorf("seed %d: order unchanged", seed)
+		}
+		// the labels of a sorted dataset spread across its chunks of 25
+		for c := 0; c < 4; c++ {
+			fails := 0
+			for _, s := range a[c*25 : (c+1)*25] {
+				fails += s.Label
+			}
+			if fails == 0 || fails == 25 {
+				t.Errorf("seed %d: chunk %d has %d of 25 failures", seed, c+1, fails)
+			}
+		}
+	}
+	if slices.Equal(shuffled(1), shuffled(2)) {
+		t.Error("seeds 1 and 2 give the same permutation")
+	}
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	samples := sortedDataset(100)
+	tests := []struct {
+		name     string
+		n        int
+		testFrac float64
+		wantTest int
+		wantErr  bool
+	}{
+		{"fifth", 100, 0.2, 20, false},
+		{"half", 100, 0.5, 50, false},
+		{"rounded", 10, 0.25, 3, false},
+		{"tiny", 3, 0.1, 0, false},
+		{"zero", 100, 0, 0, true},
+		{"one", 100, 1, 0, true},
+		{"negative", 100, -0.2, 0, true},
+		{"NaN", 100, math.NaN(), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := slices.Clone(samples[:tt.n])
+			train, test, err := TrainTestSplit(in, tt.testFrac, 7)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(test) != tt.wantTest || len(train) != tt.n-tt.wantTest {
+				t.Fatalf("%d train and %d test samples, want %d test", len(train), len(test), tt.wantTest)
+			}
+			// marks are distinct, so a disjoint partition has every sample once
+			if !sameMultiset(append(slices.Clone(train), test...), samples[:tt.n]) {
+				t.Error("train and test are not a partition of the samples")
+			}
+			if !slices.Equal(in, samples[:tt.n]) {
+				t.Error("the input was modified")
+			}
+			train2, test2, err := TrainTestSplit(in, tt.testFrac, 7)
+			if err != nil || !slices.Equal(train, train2) || !slices.Equal(test, test2) {
+				t.Error("the same seed gave another split")
+			}
+		})
+	}
+}
+
+// writeFile writes content to name in a temporary directory and returns its
+// path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadModelJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Model
+		wantErr string
+	}{
+		{"single weight", `{"weights": [-0.85735312], "bias": 50.94705066}`, Model{[]float64{-0.85735312}, 50.94705066}, ""},
+		{"weight as a number", `{"weights": -0.5, "bias": 10}`, Model{[]float64{-0.5}, 10}, ""},
+		{"multi-feature", `{"weights": [0.1, -2, 3.5], "bias": -1}`, Model{[]float64{0.1, -2, 3.5}, -1}, ""},
+		{"zero bias", `{"weights": [1], "bias": 0}`, Model{[]float64{1}, 0}, ""},
+		{"extra fields", `{"weights": [1], "bias": 2, "accuracy": 0.97}`, Model{[]float64{1}, 2}, ""},
+		{"no bias", `{"weights": [1]}`, Model{}, `needs both "weights" and "bias"`},
+		{"no weights", `{"bias": 1}`, Model{}, `needs both "weights" and "bias"`},
+		{"null weights", `{"weights": null, "bias": 1}`, Model{}, `needs both "weights" and "bias"`},
+		{"empty weights", `{"weights": [], "bias": 1}`, Model{}, "has no weights"},
+		{"string weight", `{"weights": ["1"], "bias": 1}`, Model{}, "want a number or an array of numbers"},
+		{"malformed", `{"weights": [1],`, Model{}, "failed to parse model JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadModelJSON(writeFile(t, "model.json", tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got.Weights, tt.want.Weights) || got.Bias != tt.want.Bias {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if _, err := LoadModelJSON(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestLoadModelParametersFormats(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		w, b                float64
+		wantErr             bool
+	}{
+		{"text", "model.txt", "W: -0.5\nB: 10\n", -0.5, 10, false},
+		{"train_model.py", "model.txt", "Coefficient: [[-0.85735312]]\nIntercept: [50.94705066]\n", -0.85735312, 50.94705066, false},
+		{"JSON", "model.json", `{"weights": [-0.5], "bias": 10}`, -0.5, 10, false},
+		{"JSON by extension case", "model.JSON", `{"weights": -0.5, "bias": 10}`, -0.5, 10, false},
+		{"multi-feature JSON", "model.json", `{"weights": [1, 2], "bias": 10}`, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, b, err := LoadModelParameters(writeFile(t, tt.file, tt.content))
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("err = %v, want error = %v", err, tt.wantErr)
+			}
+			if w != tt.w || b != tt.b {
+				t.Errorf("W, B = %v, %v; want %v, %v", w, b, tt.w, tt.b)
+			}
+		})
+	}
+}
+
+func TestLoadDatasets(t *testing.T) {
+	first := writeFile(t, "first.csv", "marks,failed\n10,1\n90,0\n")
+	second := writeFile(t, "second.csv", "marks,failed\n45,1\n70,0\n55,1\n")
+	otherHeader := writeFile(t, "other.csv", "score,passed\n50,1\n")
+	badRow := writeFile(t, "bad.csv", "marks,failed\n40,0\nforty,1\n")
+
+	tests := []struct {
+		name    string
+		files   []string
+		want    []Sample
+		wantErr []string // substrings of the error
+	}{
+		{"one file", []string{first}, []Sample{{10, 1}, {90, 0}}, nil},
+		{"in argument order", []string{second, first}, []Sample{{45, 1}, {70, 0}, {55, 1}, {10, 1}, {90, 0}}, nil},
+		{"same file twice", []string{first, first}, []Sample{{10, 1}, {90, 0}, {10, 1}, {90, 0}}, nil},
+		{"no files", nil, nil, []string{"no dataset files"}},
+		{"other header", []string{first, otherHeader}, nil, []string{otherHeader, "score,passed", first}},
+		{"bad row", []string{first, badRow}, nil, []string{badRow, "line 3"}},
+		{"missing file", []string{first, filepath.Join(t.TempDir(), "missing.csv")}, nil, []string{"missing.csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadDatasets(tt.files...)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("loaded %v, want an error", got)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not mention %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("loaded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
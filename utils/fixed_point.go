@@ -1,8 +1,29 @@
 package utils
 
-import "math"
+import (
+	"math"
+	"math/big"
 
-const ScalingFactor = 1 << 32
+	"github.com/santhoshcheemala/ZKLR/fixedpoint"
+)
+
+const ScalingFactor = fixedpoint.ScalingFactor
+
+// Sigmoid LUT configuration, shared with the circuit.
+const (
+	Precision       = fixedpoint.Precision       // z in Q32
+	InputPrecision  = fixedpoint.InputPrecision  // LUT index in Q10
+	OutputPrecision = fixedpoint.OutputPrecision // LUT value in Q16
+	MaxInput        = fixedpoint.MaxInput        // LUT covers |z| <= 8
+)
+
+// Rounding selects how ComputeZ rescales products; see fixedpoint.Rounding.
+type Rounding = fixedpoint.Rounding
+
+const (
+	Truncate     = fixedpoint.Truncate
+	RoundNearest = fixedpoint.RoundNearest
+)
 
 func FloatToFixed(f float64) int64 {
 	return int64(f * float64(ScalingFactor))
@@ -12,26 +33,92 @@ func FixedToFloat(i int64) float64 {
 	return float64(i) / float64(ScalingFactor)
 }
 
-func ComputeZ(w, b, x float64) float64 {
-	wFixed := FloatToFixed(w)
-	bFixed := FloatToFixed(b)
-	xFixed := FloatToFixed(x)
+// ComputeZ computes z = w*x + b in Q32 as the linear circuit does with the
+// same rounding: the Q32 product w*x is rescaled with rounding (floor for
+// Truncate, as DefaultArith; to nearest for RoundNearest, as RoundingQ) and
+// b added. It works on big integers, so it does not overflow for large
+// marks.
+func ComputeZ(w, b, x float64, rounding Rounding) float64 {
+	wFixed := toFixedBig(w)
+	bFixed := toFixedBig(b)
+	xFixed := toFixedBig(x)
 
-	zScaled := (wFixed * xFixed) / int64(ScalingFactor)
-	z := zScaled + bFixed
+	z := new(big.Int).Mul(wFixed, xFixed)
+	if rounding == RoundNearest {
+		z.Add(z, new(big.Int).Lsh(big.NewInt(1), Precision-1))
+	}
+	z = fixedpoint.RescaleInt(z, 2*Precision, Precision)
+	z.Add(z, bFixed)
 
-	return FixedToFloat(z)
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(z), new(big.Float).SetInt64(ScalingFactor)).Float64()
+	return f
 }
 
 func Sigmoid(z float64) float64 {
 	return 1.0 / (1.0 + math.Exp(-z))
 }
 
+// Predict is DefaultLabelMapping.Predict: the float prediction as a dataset
+// label under the default mapping, 1 (Fail) when sigmoid(w*x + b) >= 0.5 as
+// the circuits predict. Datasets labelled the other way need their own
+// LabelMapping's Predict.
 func Predict(w, b, x float64) int {
-	z := ComputeZ(w, b, x)
-	sig := Sigmoid(z)
-	if sig >= 0.5 {
-		return 0 // Pass
+	return DefaultLabelMapping().Predict(w, b, x)
+}
+
+// PredictClass is the float64 reference prediction in the circuits'
+// convention: class 1 when sigmoid(w*x + b) >= 0.5, as PredictQuantized and
+// every circuit predict, and class 0 otherwise.
+func PredictClass(w, b, x float64) int {
+	if Sigmoid(w*x+b) >= 0.5 {
+		return 1
 	}
-	return 1
-}
\ No newline at end of file
+	return 0
+}
+
+// PredictQuantized reproduces the sigmoid circuit's prediction exactly:
+// Q32 z = floor(w*x / 2^32) + b, floor rescale to a Q10 index, saturation at
+// MaxInput, the truncated Q16 LUT value with sigmoid(-x) = 1 - sigmoid(x),
+// and a >= 0.5 threshold. It returns 1 when the circuit predicts 1.
+func PredictQuantized(w, b, x float64) int {
+	wFixed := toFixedBig(w)
+	bFixed := toFixedBig(b)
+	xFixed := toFixedBig(x)
+
+	z := new(big.Int).Mul(wFixed, xFixed)
+	z = fixedpoint.RescaleInt(z, 2*Precision, Precision)
+	z.Add(z, bFixed)
+
+	zIn := fixedpoint.RescaleInt(z, Precision, InputPrecision)
+
+	isNeg := zIn.Sign() < 0
+	absZ := new(big.Int).Abs(zIn)
+	maxIndex := big.NewInt(MaxInput << InputPrecision)
+	if absZ.Cmp(maxIndex) > 0 {
+		absZ = maxIndex
+	}
+
+	lut := sigmoidLUTValue(absZ.Int64())
+	sig := lut
+	if isNeg {
+		sig = (1 << OutputPrecision) - lut
+	}
+	if sig >= 1<<(OutputPrecision-1) {
+		return 1
+	}
+	return 0
+}
+
+// sigmoidLUTValue returns the circuit's LUT entry for a Q10 index.
+func sigmoidLUTValue(i int64) int64 {
+	x := float64(i) / float64(1<<InputPrecision)
+	y := 1.0 / (1.0 + math.Exp(-x))
+	return int64(y * float64(1<<OutputPrecision))
+}
+
+func toFixedBig(f float64) *big.Int {
+	v := new(big.Float).SetFloat64(f)
+	v.Mul(v, new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), Precision)))
+	res, _ := v.Int(nil)
+	return res
+}
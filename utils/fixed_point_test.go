@@ -0,0 +1,36 @@
+package utils
+
+import "testing"
+
+// TestPredictConventions pins the single convention: Predict, PredictClass
+// and PredictQuantized all predict the circuits' class 1 (Fail under
+// DefaultLabelMapping) when sigmoid(z) >= 0.5, and the inverse mapping
+// predicts the other label.
+func TestPredictConventions(t *testing.T) {
+	const w, b = -0.5, 10 // z = 0 at marks 20
+	tests := []struct {
+		name           string
+		x              float64
+		predict, class int
+	}{
+		{"z positive", 0, 1, 1},
+		{"z zero", 20, 1, 1},
+		{"z negative", 40, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Predict(w, b, tt.x); got != tt.predict {
+				t.Errorf("Predict = %d, want %d", got, tt.predict)
+			}
+			if got := PredictClass(w, b, tt.x); got != tt.class {
+				t.Errorf("PredictClass = %d, want %d", got, tt.class)
+			}
+			if got := PredictQuantized(w, b, tt.x); got != tt.class {
+				t.Errorf("PredictQuantized = %d, want %d", got, tt.class)
+			}
+			if got := (LabelMapping{PositiveClass: 0}).Predict(w, b, tt.x); got != 1-tt.predict {
+				t.Errorf("inverse mapping Predict = %d, want %d", got, 1-tt.predict)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import "fmt"
+
+// InversionMismatchRate is the fraction of labels disagreeing with the
+// model above which DetectLabelInversion reports the labels as inverted. A
+// model that is wrong this often would be right far more often with every
+// label flipped, which is what a dataset using 1 = Pass, 0 = Fail looks like.
+const InversionMismatchRate = 0.75
+
+// DetectLabelInversion compares each sample's label with PredictClass and returns
+// the fraction that disagree, and whether that fraction is high enough to
+// suggest the dataset uses the opposite label convention to the circuits (1
+// = Fail, 0 = Pass), i.e. needs the other LabelMapping. Labels are compared
+// as given, so pass samples already mapped to classes. With no samples it
+// returns false, 0.
+func DetectLabelInversion(w, b float64, samples []Sample) (inverted bool, mismatchRate float64) {
+	if len(samples) == 0 {
+		return false, 0
+	}
+	mismatches := 0
+	for _, s := range samples {
+		if PredictClass(w, b, s.Marks) != s.Label {
+			mismatches++
+		}
+	}
+	mismatchRate = float64(mismatches) / float64(len(samples))
+	return mismatchRate > InversionMismatchRate, mismatchRate
+}
+
+// LabelMapping is how a dataset's labels map to the model's classes. The
+// model and every circuit predict class 1 when sigmoid(w*x + b) >= 0.5 and
+// class 0 otherwise; for the bundled model class 1 is Fail. Datasets are read
+// through the mapping once, so the circuits only ever see classes and
+// nothing else needs to know which convention a dataset uses.
+type LabelMapping struct {
+	// PositiveClass is the dataset label of class 1: 1 for datasets
+	// labelled 1 = Fail, 0 = Pass, as DefaultLabelMapping, or 0 for
+	// datasets labelled 1 = Pass, 0 = Fail.
+	PositiveClass int
+}
+
+// DefaultLabelMapping returns the mapping that reads labels as the
+// circuits' classes, 1 = Fail.
+func DefaultLabelMapping() LabelMapping {
+	return LabelMapping{PositiveClass: 1}
+}
+
+// Check returns an error unless PositiveClass is 0 or 1.
+func (m LabelMapping) Check() error {
+	if m.PositiveClass != 0 && m.PositiveClass != 1 {
+		return fmt.Errorf("label mapping: positive class %d is not 0 or 1", m.PositiveClass)
+	}
+	return nil
+}
+
+// ToClass returns the model class of a dataset label. Labels other than 0
+// and 1 are returned unchanged, so CheckSamples and the circuits still
+// reject them.
+func (m LabelMapping) ToClass(label int) int {
+	if m.PositiveClass == 0 && (label == 0 || label == 1) {
+		return 1 - label
+	}
+	return label
+}
+
+// ToLabel returns the dataset label of a model class; it is its own
+// inverse, like ToClass.
+func (m LabelMapping) ToLabel(class int) int {
+	return m.ToClass(class)
+}
+
+// Predict is PredictClass as a dataset label.
+func (m LabelMapping) Predict(w, b, x float64) int {
+	return m.ToLabel(PredictClass(w, b, x))
+}
+
+// Classes returns a copy of samples with each label replaced by its model
+// class, as the circuits and their witness builders expect.
+func (m LabelMapping) Classes(samples []Sample) []Sample {
+	classes := make([]Sample, len(samples))
+	for i, s := range samples {
+		classes[i] = Sample{Marks: s.Marks, Label: m.ToClass(s.Label)}
+	}
+	return classes
+}
@@ -0,0 +1,111 @@
+package utils
+
+import "testing"
+
+func TestDetectLabelInversion(t *testing.T) {
+	const w, b = -0.5, 30
+	samples := func(n int, noise float64) []Sample { return GenerateSynthetic(n, w, b, noise, 1) }
+	// four samples, the first three mislabelled: exactly the threshold
+	threshold := samples(4, 0)
+	for i := range 3 {
+		threshold[i].Label = 1 - threshold[i].Label
+	}
+
+	tests := []struct {
+		name     string
+		samples  []Sample
+		inverted bool
+		rate     float64
+	}{
+		{"model's labels", samples(100, 0), false, 0},
+		{"every label flipped", flipLabels(samples(100, 0)), true, 1},
+		{"at the threshold", threshold, false, InversionMismatchRate},
+		{"no samples", nil, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inverted, rate := DetectLabelInversion(w, b, tt.samples)
+			if inverted != tt.inverted || rate != tt.rate {
+				t.Errorf("DetectLabelInversion = %v, %v; want %v, %v", inverted, rate, tt.inverted, tt.rate)
+			}
+		})
+	}
+
+	// reading the flipped dataset through the other mapping undoes it
+	inverse := LabelMapping{PositiveClass: 0}
+	if inverted, rate := DetectLabelInversion(w, b, inverse.Classes(flipLabels(samples(100, 0)))); inverted || rate != 0 {
+		t.Errorf("flipped labels read with %+v: %v, %v; want not inverted", inverse, inverted, rate)
+	}
+
+	// noisy labels are a poor fit, not an inversion, until most are wrong
+	for _, noise := range []float64{0.1, 0.5} {
+		if inverted, rate := DetectLabelInversion(w, b, samples(1000, noise)); inverted {
+			t.Errorf("noise %v: reported inverted at mismatch rate %v", noise, rate)
+		}
+	}
+	if inverted, rate := DetectLabelInversion(w, b, samples(1000, 0.9)); !inverted {
+		t.Errorf("noise 0.9: not reported inverted at mismatch rate %v", rate)
+	}
+}
+
+// flipLabels returns samples with every 0/1 label swapped, as a dataset
+// labelled with the opposite convention would read.
+func flipLabels(samples []Sample) []Sample {
+	flipped := make([]Sample, len(samples))
+	for i, s := range samples {
+		flipped[i] = Sample{Marks: s.Marks, Label: 1 - s.Label}
+	}
+	return flipped
+}
+
+func TestLabelMapping(t *testing.T) {
+	const w, b = -0.5, 10 // class 1 (Fail) below marks 20
+	inverse := LabelMapping{PositiveClass: 0}
+	tests := []struct {
+		name    string
+		mapping LabelMapping
+		label   int
+		class   int
+	}{
+		{"default, 1 = Fail", DefaultLabelMapping(), 1, 1},
+		{"default, 0 = Pass", DefaultLabelMapping(), 0, 0},
+		{"inverse, 1 = Pass", inverse, 1, 0},
+		{"inverse, 0 = Fail", inverse, 0, 1},
+		// left for CheckSamples and the circuits to reject
+		{"inverse, out of range", inverse, 2, 2},
+		{"inverse, negative", inverse, -1, -1},
+	}
+	for _, tt := range tests {
+		if got := tt.mapping.ToClass(tt.label); got != tt.class {
+			t.Errorf("%s: ToClass(%d) = %d, want %d", tt.name, tt.label, got, tt.class)
+		}
+		if got := tt.mapping.ToLabel(tt.class); got != tt.label {
+			t.Errorf("%s: ToLabel(%d) = %d, want %d", tt.name, tt.class, got, tt.label)
+		}
+	}
+
+	for _, x := range []float64{5, 19, 21, 80} {
+		class := PredictClass(w, b, x)
+		if got := DefaultLabelMapping().Predict(w, b, x); got != class {
+			t.Errorf("marks %v: default Predict = %d, want class %d", x, got, class)
+		}
+		if got := inverse.Predict(w, b, x); got != 1-class {
+			t.Errorf("marks %v: inverse Predict = %d, want label %d", x, got, 1-class)
+		}
+	}
+
+	dataset := []Sample{{Marks: 5, Label: 0}, {Marks: 80, Label: 1}}
+	classes := inverse.Classes(dataset)
+	if want := []Sample{{Marks: 5, Label: 1}, {Marks: 80, Label: 0}}; classes[0] != want[0] || classes[1] != want[1] {
+		t.Errorf("Classes = %v, want %v", classes, want)
+	}
+	if dataset[0].Label != 0 {
+		t.Error("Classes modified its argument")
+	}
+
+	for _, m := range []LabelMapping{DefaultLabelMapping(), inverse, {PositiveClass: 2}, {PositiveClass: -1}} {
+		if err := m.Check(); (err != nil) != (m.PositiveClass != 0 && m.PositiveClass != 1) {
+			t.Errorf("%+v: Check() = %v", m, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Synthetic marks are drawn from a normal distribution like the generated
+// student dataset's, clamped to the 0..100 mark range and rounded to whole
+// marks.
+const (
+	syntheticMarksMean   = 60.0
+	syntheticMarksStdDev = 15.0
+)
+
+// GenerateSynthetic returns n samples labelled by the model (w, b) with
+// PredictClass, for testing and benchmarking without a dataset file. Each label is
+// then flipped with probability noise, so with noise 0 every label is the
+// model's float prediction. The same seed always yields the same samples.
+func GenerateSynthetic(n int, w, b float64, noise float64, seed int64) []Sample {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]Sample, n)
+	for i := range samples {
+		marks := math.Round(math.Max(0, math.Min(100, r.NormFloat64()*syntheticMarksStdDev+syntheticMarksMean)))
+		label := PredictClass(w, b, marks)
+		if r.Float64() < noise {
+			label = 1 - label
+		}
+		samples[i] = Sample{Marks: marks, Label: label}
+	}
+	return samples
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestGenerateSynthetic(t *testing.T) {
+	const w, b = -0.5, 30 // z = 0 at marks 60, the marks mean
+	const n = 2000
+	clean := GenerateSynthetic(n, w, b, 0, 1)
+
+	tests := []struct {
+		name     string
+		noise    float64
+		flipRate float64 // expected fraction of labels disagreeing with the model
+	}{
+		{"no noise", 0, 0},
+		{"some noise", 0.2, 0.2},
+		{"all flipped", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := GenerateSynthetic(n, w, b, tt.noise, 1)
+			if len(samples) != n {
+				t.Fatalf("%d samples, want %d", len(samples), n)
+			}
+			flipped := 0
+			for i, s := range samples {
+				if s.Marks != clean[i].Marks {
+					t.Fatalf("sample %d: marks %v depend on the noise (%v without)", i, s.Marks, clean[i].Marks)
+				}
+				if s.Marks < 0 || s.Marks > 100 || s.Marks != math.Round(s.Marks) {
+					t.Fatalf("sample %d: marks %v are not whole marks in 0..100", i, s.Marks)
+				}
+				if s.Label != PredictClass(w, b, s.Marks) {
+					flipped++
+				}
+			}
+			// within four standard deviations of the binomial
+			rate := float64(flipped) / n
+			if slack := 4 * math.Sqrt(tt.flipRate*(1-tt.flipRate)/n); math.Abs(rate-tt.flipRate) > slack {
+				t.Errorf("%v of labels flipped, want %v", rate, tt.flipRate)
+			}
+			if !slices.Equal(samples, GenerateSynthetic(n, w, b, tt.noise, 1)) {
+				t.Error("same seed gave different samples")
+			}
+			if slices.Equal(samples, GenerateSynthetic(n, w, b, tt.noise, 2)) {
+				t.Error("another seed gave the same samples")
+			}
+		})
+	}
+
+	classes := map[int]int{}
+	for _, s := range clean {
+		classes[s.Label]++
+	}
+	if classes[0] == 0 || classes[1] == 0 {
+		t.Errorf("classes %v, want both around a boundary at the mean", classes)
+	}
+	if got := GenerateSynthetic(0, w, b, 0, 1); len(got) != 0 {
+		t.Errorf("n = 0 gave %d samples", len(got))
+	}
+}